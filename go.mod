@@ -9,8 +9,12 @@ require (
 )
 
 require (
+	github.com/andybalholm/brotli v1.2.5
 	github.com/fogfish/opts v0.0.2
 	github.com/google/go-cmp v0.6.0
+	golang.org/x/text v0.13.0
+	golang.org/x/time v0.5.0
+	google.golang.org/protobuf v1.33.0
 )
 
 require (
@@ -0,0 +1,183 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+//
+// The file implements adaptive concurrency controller using additive
+// increase / multiplicative decrease (AIMD) algorithm.
+//
+
+// AIMD is adaptive concurrency controller. It backs off on 429, 5xx,
+// transport failures and latency spikes, it ramps up when upstream is
+// healthy. The limit grows by one per window of successful requests and
+// halves on congestion.
+//
+//	aimd := http.NewAIMD(1, 64, 500*time.Millisecond)
+//	stack.IO(context.Background(),
+//		http.JoinParallelAIMD(aimd, arrows...),
+//	)
+type AIMD struct {
+	sync.Mutex
+	wake     chan struct{}
+	min, max float64
+	latency  time.Duration
+	limit    float64
+	inflight int
+	backoff  time.Time
+}
+
+// Creates new instance of AIMD controller, concurrency is bounded by
+// [min, max]. Zero latency disables detection of latency spikes.
+func NewAIMD(min, max int, latency time.Duration) *AIMD {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+
+	c := &AIMD{
+		min:     float64(min),
+		max:     float64(max),
+		latency: latency,
+		limit:   float64(min),
+		wake:    make(chan struct{}),
+	}
+	return c
+}
+
+// Limit is current concurrency limit
+func (c *AIMD) Limit() int {
+	c.Lock()
+	defer c.Unlock()
+
+	return int(c.limit)
+}
+
+// acquire the slot, it waits for release of other requests or
+// cancellation of the context
+func (c *AIMD) acquire(ctx context.Context, clock Clock) (time.Time, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return time.Time{}, err
+		}
+
+		c.Lock()
+		if c.inflight < int(c.limit) {
+			c.inflight++
+			c.Unlock()
+			return clock.Now(), nil
+		}
+		wake := c.wake
+		c.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return time.Time{}, ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+func (c *AIMD) release(started time.Time, sample *aimdSample, clock Clock) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.inflight--
+
+	switch {
+	case c.congested(sample):
+		// Note: requests started before the last decrease observe the same
+		//       congestion, they do not decrease the limit again.
+		if started.After(c.backoff) {
+			c.limit = max(c.min, c.limit/2)
+			c.backoff = clock.Now()
+		}
+	case sample.status != 0:
+		c.limit = min(c.max, c.limit+1/c.limit)
+	}
+
+	// Note: closed channel wakes up all waiters, they compete for the slot
+	close(c.wake)
+	c.wake = make(chan struct{})
+}
+
+func (c *AIMD) congested(sample *aimdSample) bool {
+	if sample.err != nil && !errors.Is(sample.err, context.Canceled) {
+		return true
+	}
+
+	return sample.status == http.StatusTooManyRequests ||
+		sample.status >= 500 ||
+		(c.latency > 0 && sample.duration > c.latency)
+}
+
+// aimdSample is the outcome of the last request of the arrow
+type aimdSample struct {
+	status   int
+	duration time.Duration
+	err      error
+}
+
+type aimdKey struct{}
+
+func observe(ctx context.Context, in *http.Response, dur time.Duration, err error) {
+	sample, ok := ctx.Value(aimdKey{}).(*aimdSample)
+	if !ok {
+		return
+	}
+
+	sample.duration, sample.err = dur, err
+	if in != nil {
+		sample.status = in.StatusCode
+	}
+}
+
+// JoinParallelAIMD is JoinParallel with parallelism adapted by the controller.
+// The controller is shared across batches, it keeps the learned limit.
+// Arrows waiting for the slot are not started if the context is cancelled.
+func JoinParallelAIMD(ctrl *AIMD, arrows ...Arrow) Arrow {
+	return func(cat *Context) error {
+		var (
+			wg   sync.WaitGroup
+			errs = make([]error, len(arrows))
+		)
+
+		clock := cat.Clock()
+		for i, f := range arrows {
+			started, err := ctrl.acquire(cat.Context, clock)
+			if err != nil {
+				errs[i] = err
+				break
+			}
+
+			wg.Add(1)
+			go func(i int, f Arrow) {
+				defer wg.Done()
+
+				sample := &aimdSample{}
+				ctx := context.WithValue(cat.Context, aimdKey{}, sample)
+				errs[i] = cat.stack.IO(ctx, f)
+				ctrl.release(started, sample, clock)
+			}(i, f)
+		}
+
+		wg.Wait()
+
+		return errors.Join(errs...)
+	}
+}
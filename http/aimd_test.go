@@ -0,0 +1,97 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestAIMD(t *testing.T) {
+	var (
+		inflight, peak int32
+		overload       atomic.Bool
+	)
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&inflight, 1)
+			defer atomic.AddInt32(&inflight, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+
+			time.Sleep(2 * time.Millisecond)
+			if overload.Load() {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer ts.Close()
+
+	batch := func(n int) []µ.Arrow {
+		seq := make([]µ.Arrow, n)
+		for i := range seq {
+			seq[i] = µ.GET(ø.URI(ts.URL), ƒ.Code(200, 429))
+		}
+		return seq
+	}
+
+	aimd := µ.NewAIMD(1, 8, 0)
+	stack := µ.New()
+
+	t.Run("RampUp", func(t *testing.T) {
+		err := stack.IO(context.Background(), µ.JoinParallelAIMD(aimd, batch(100)...))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(aimd.Limit(), 8),
+			it.True(atomic.LoadInt32(&peak) <= 8),
+		)
+	})
+
+	t.Run("BackOff", func(t *testing.T) {
+		overload.Store(true)
+		err := stack.IO(context.Background(), µ.JoinParallelAIMD(aimd, batch(20)...))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(aimd.Limit(), 1),
+		)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		var n atomic.Int32
+		ctx, cancel := context.WithCancel(context.Background())
+		arrow := func(*µ.Context) error {
+			n.Add(1)
+			cancel()
+			return nil
+		}
+
+		err := stack.IO(ctx, µ.JoinParallelAIMD(µ.NewAIMD(1, 1, 0), arrow, arrow))
+		it.Then(t).Should(
+			it.True(errors.Is(err, context.Canceled)),
+			it.Equal(n.Load(), int32(1)),
+		)
+	})
+}
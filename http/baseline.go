@@ -0,0 +1,128 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+//
+// The file implements differential run, comparing the status of tests
+// against the baseline report of previous run.
+//
+
+// Kind of regression
+type RegressionKind string
+
+const (
+	// Test is failing, it was successful at baseline (or it is new)
+	RegressionFailing RegressionKind = "failing"
+	// Test is slower than baseline beyond the threshold
+	RegressionSlow RegressionKind = "slow"
+	// Test is successful, it was failing at baseline
+	RegressionRecovered RegressionKind = "recovered"
+)
+
+// Regression is the difference of test status against the baseline
+type Regression struct {
+	ID       string         `json:"id"`
+	Kind     RegressionKind `json:"kind"`
+	Actual   Status         `json:"actual"`
+	Baseline *Status        `json:"baseline,omitempty"`
+}
+
+func (r Regression) String() string {
+	switch {
+	case r.Kind == RegressionSlow:
+		return fmt.Sprintf("%-9s %s: %v (was %v, %+.0f%%)", r.Kind, r.ID,
+			r.Actual.Duration.Round(time.Millisecond),
+			r.Baseline.Duration.Round(time.Millisecond),
+			100*(float64(r.Actual.Duration)/float64(r.Baseline.Duration)-1),
+		)
+	case r.Baseline == nil:
+		return fmt.Sprintf("%-9s %s: %s (new)", r.Kind, r.ID, r.Actual.Status)
+	default:
+		return fmt.Sprintf("%-9s %s: %s (was %s)", r.Kind, r.ID, r.Actual.Status, r.Baseline.Status)
+	}
+}
+
+// ReadBaseline decodes the report produced by WriteOnce
+func ReadBaseline(r io.Reader) ([]Status, error) {
+	var seq []Status
+	if err := json.NewDecoder(r).Decode(&seq); err != nil {
+		return nil, err
+	}
+	return seq, nil
+}
+
+// CompareBaseline compares status of current run against the baseline. The test is newly
+// slow if its duration exceeds baseline by slowdown percents, zero slowdown
+// disables the check. Regressions are ordered by kind and then by tests.
+func CompareBaseline(baseline, current []Status, slowdown float64) []Regression {
+	index := make(map[string]*Status, len(baseline))
+	for i := range baseline {
+		index[baseline[i].ID] = &baseline[i]
+	}
+
+	var failing, slow, recovered []Regression
+	for _, actual := range current {
+		was := index[actual.ID]
+		switch {
+		case actual.Status != "success" && (was == nil || was.Status == "success"):
+			failing = append(failing, Regression{ID: actual.ID, Kind: RegressionFailing, Actual: actual, Baseline: was})
+		case actual.Status == "success" && was != nil && was.Status != "success":
+			recovered = append(recovered, Regression{ID: actual.ID, Kind: RegressionRecovered, Actual: actual, Baseline: was})
+		case actual.Status == "success" && was != nil && slowdown > 0 && was.Duration > 0 &&
+			float64(actual.Duration) > float64(was.Duration)*(1+slowdown/100):
+			slow = append(slow, Regression{ID: actual.ID, Kind: RegressionSlow, Actual: actual, Baseline: was})
+		}
+	}
+
+	return append(append(failing, slow...), recovered...)
+}
+
+// WriteRegressions outputs the concise summary of regressions, e.g.
+//
+//	regressions: 1 failing, 1 slow, 0 recovered
+//	failing   TestA: nomatch (was success)
+//	slow      TestB: 120ms (was 100ms, +20%)
+func WriteRegressions(w io.Writer, seq []Regression) error {
+	count := map[RegressionKind]int{}
+	for _, r := range seq {
+		count[r.Kind]++
+	}
+
+	buf := strings.Builder{}
+	fmt.Fprintf(&buf, "regressions: %d failing, %d slow, %d recovered\n",
+		count[RegressionFailing], count[RegressionSlow], count[RegressionRecovered])
+	for _, r := range seq {
+		buf.WriteString(r.String())
+		buf.WriteRune('\n')
+	}
+
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// WriteOnceDiff evaluates sequence of tests and outputs the summary of
+// regressions against the baseline. It returns status of the run so that
+// it can be stored as the next baseline.
+func WriteOnceDiff(w io.Writer, baseline []Status, slowdown float64, stack Stack, tests ...func() Arrow) ([]Status, error) {
+	seq := Once(stack, tests...)
+
+	if err := WriteRegressions(w, CompareBaseline(baseline, seq, slowdown)); err != nil {
+		return seq, err
+	}
+
+	return seq, nil
+}
@@ -0,0 +1,97 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestCompareBaseline(t *testing.T) {
+	baseline := []µ.Status{
+		{ID: "a", Status: "success", Duration: 100 * time.Millisecond},
+		{ID: "b", Status: "success", Duration: 100 * time.Millisecond},
+		{ID: "c", Status: "nomatch", Duration: 100 * time.Millisecond},
+		{ID: "d", Status: "success", Duration: 100 * time.Millisecond},
+	}
+	current := []µ.Status{
+		{ID: "a", Status: "failure", Duration: 100 * time.Millisecond},
+		{ID: "b", Status: "success", Duration: 150 * time.Millisecond},
+		{ID: "c", Status: "success", Duration: 100 * time.Millisecond},
+		{ID: "d", Status: "success", Duration: 110 * time.Millisecond},
+		{ID: "e", Status: "nomatch", Duration: 100 * time.Millisecond},
+	}
+
+	seq := µ.CompareBaseline(baseline, current, 20)
+	it.Then(t).Should(
+		it.Equal(len(seq), 4),
+		it.Equal(seq[0].ID, "a"),
+		it.Equal(seq[0].Kind, µ.RegressionFailing),
+		it.Equal(seq[1].ID, "e"),
+		it.Equal(seq[1].Kind, µ.RegressionFailing),
+		it.Equal(seq[2].ID, "b"),
+		it.Equal(seq[2].Kind, µ.RegressionSlow),
+		it.Equal(seq[3].ID, "c"),
+		it.Equal(seq[3].Kind, µ.RegressionRecovered),
+	)
+
+	buf := bytes.Buffer{}
+	err := µ.WriteRegressions(&buf, seq)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(buf.String(),
+			"regressions: 2 failing, 1 slow, 1 recovered\n"+
+				"failing   a: failure (was success)\n"+
+				"failing   e: nomatch (new)\n"+
+				"slow      b: 150ms (was 100ms, +50%)\n"+
+				"recovered c: success (was nomatch)\n",
+		),
+	)
+
+	it.Then(t).Should(
+		it.Equal(len(µ.CompareBaseline(baseline, current[1:2], 0)), 0),
+	)
+}
+
+func TestWriteOnceDiff(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	unittest := func() µ.Arrow {
+		return µ.GET(
+			ø.URI("/json"),
+			ƒ.Status.OK,
+		)
+	}
+
+	stack := µ.New(µ.WithHost(ts.URL))
+
+	buf := bytes.Buffer{}
+	err := µ.WriteOnce(&buf, stack, unittest)
+	it.Then(t).Should(it.Nil(err))
+
+	baseline, err := µ.ReadBaseline(&buf)
+	it.Then(t).Should(it.Nil(err))
+	baseline[0].Status = "nomatch"
+
+	buf.Reset()
+	seq, err := µ.WriteOnceDiff(&buf, baseline, 0, stack, unittest)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(len(seq), 1),
+		it.True(strings.HasPrefix(buf.String(), "regressions: 0 failing, 0 slow, 1 recovered\n")),
+	)
+}
@@ -0,0 +1,102 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+//
+// The file implements per-host circuit breaker of the protocol stack
+//
+
+// CircuitOpen is returned when requests to the host fail fast because of
+// consecutive failures. After cooldown, a single probe request is let through,
+// its success closes the circuit.
+type CircuitOpen struct {
+	Host  string
+	Until time.Time
+}
+
+func (e *CircuitOpen) Error() string {
+	return fmt.Sprintf("circuit open for %s until %s", e.Host, e.Until.Format(time.RFC3339))
+}
+
+type circuitBreaker struct {
+	sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	hosts     map[string]*circuit
+}
+
+type circuit struct {
+	failures int
+	until    time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		hosts:     map[string]*circuit{},
+	}
+}
+
+// allow checks the circuit state of host, the circuit which cooldown is
+// expired is half-open, it let a single probe through. Other requests fail
+// fast until the probe is recorded: success closes the circuit, failure
+// opens it again. The probe which never reports back (e.g. cancelled before
+// sending) is replaced by a new one after another cooldown.
+func (cb *circuitBreaker) allow(host string, now time.Time) error {
+	cb.Lock()
+	defer cb.Unlock()
+
+	c, has := cb.hosts[host]
+	if !has || c.failures < cb.threshold {
+		return nil
+	}
+
+	if now.Before(c.until) {
+		return &CircuitOpen{Host: host, Until: c.until}
+	}
+
+	c.until = now.Add(cb.cooldown)
+	return nil
+}
+
+// record outcome of the request, transport errors and 5xx responses are
+// failures.
+func (cb *circuitBreaker) record(host string, in *http.Response, err error, now time.Time) {
+	cb.Lock()
+	defer cb.Unlock()
+
+	if err == nil && in.StatusCode < 500 {
+		delete(cb.hosts, host)
+		return
+	}
+
+	c, has := cb.hosts[host]
+	if !has {
+		c = &circuit{}
+		cb.hosts[host] = c
+	}
+
+	c.failures++
+	if c.failures >= cb.threshold {
+		c.until = now.Add(cb.cooldown)
+	}
+}
+
+func withCircuitBreaker(cat *Protocol, cb *circuitBreaker) error {
+	cat.breaker = cb
+	return nil
+}
@@ -0,0 +1,105 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	ts := flaky(2)
+	defer ts.Close()
+
+	req := µ.GET(
+		ø.URI(ts.URL),
+		ƒ.Status.OK,
+	)
+
+	cat := µ.New(µ.WithCircuitBreaker(2, 50*time.Millisecond))
+
+	var open *µ.CircuitOpen
+	for i := 0; i < 2; i++ {
+		err := cat.IO(context.Background(), req)
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+			it.True(errors.As(err, &open)),
+		)
+	}
+
+	err := cat.IO(context.Background(), req)
+	it.Then(t).Should(
+		it.True(errors.As(err, &open)),
+		it.Equal(open.Host, ts.Listener.Addr().String()),
+	)
+
+	time.Sleep(60 * time.Millisecond)
+
+	err = cat.IO(context.Background(), req)
+	it.Then(t).Should(it.Nil(err))
+}
+
+func TestCircuitBreakerProbe(t *testing.T) {
+	probe := make(chan struct{})
+	release := make(chan struct{})
+	failures := 2
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if failures > 0 {
+				failures--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			probe <- struct{}{}
+			<-release
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer ts.Close()
+
+	req := µ.GET(
+		ø.URI(ts.URL),
+		ƒ.Status.OK,
+	)
+
+	cat := µ.New(µ.WithCircuitBreaker(2, 50*time.Millisecond))
+	for i := 0; i < 2; i++ {
+		cat.IO(context.Background(), req)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	probed := make(chan error, 1)
+	go func() { probed <- cat.IO(context.Background(), req) }()
+	<-probe
+
+	var open *µ.CircuitOpen
+	err := cat.IO(context.Background(), req)
+	it.Then(t).Should(
+		it.True(errors.As(err, &open)),
+	)
+
+	close(release)
+	it.Then(t).Should(
+		it.Nil(<-probed),
+	)
+
+	go func() { <-probe }()
+	err = cat.IO(context.Background(), req)
+	it.Then(t).Should(it.Nil(err))
+}
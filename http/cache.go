@@ -0,0 +1,416 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+//
+// The file implements private HTTP cache of the stack (RFC 9111), see
+// cachestore.go for storage backends.
+//
+
+// CacheStorage is the backend of HTTP cache, it stores opaque entries by
+// key. Failures of the storage are treated as cache misses.
+type CacheStorage interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+	Delete(key string)
+}
+
+// Status codes of responses cacheable by default (RFC 9110, Section 15.1)
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+type httpCache struct {
+	storage CacheStorage
+	clock   Clock
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+}
+
+func withCache(cat *Protocol, storage CacheStorage) error {
+	cat.cache = &httpCache{storage: storage, clock: SystemClock}
+	return nil
+}
+
+// cacheEntry is the stored response
+type cacheEntry struct {
+	StatusCode   int         `json:"status"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body,omitempty"`
+	Vary         http.Header `json:"vary,omitempty"`
+	RequestTime  time.Time   `json:"request_time"`
+	ResponseTime time.Time   `json:"response_time"`
+}
+
+func cacheKey(req *http.Request) string {
+	return http.MethodGet + " " + req.URL.String()
+}
+
+// do serves GET requests from the cache, other requests are forwarded to
+// the socket, unsafe methods invalidate cached target URI.
+func (c *httpCache) do(req *http.Request, socket func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		in, err := socket(req)
+		if err == nil && req.Method != http.MethodHead && req.Method != http.MethodOptions && in.StatusCode < 400 {
+			c.storage.Delete(cacheKey(req))
+		}
+		return in, err
+	}
+
+	creq := cacheControlOf(req.Header)
+	if creq.has("no-store") || isConditional(req) {
+		// Note: the request is not served from cache if the client
+		//       controls validators or ranges by itself.
+		return socket(req)
+	}
+
+	key := cacheKey(req)
+	entry, has := c.load(key)
+	if has && !entry.matchVary(req) {
+		has = false
+	}
+
+	now := c.clock.Now()
+	if has && entry.fresh(creq, now) {
+		c.hits.Add(1)
+		return entry.response(req, now), nil
+	}
+
+	if creq.has("only-if-cached") {
+		c.misses.Add(1)
+		return &http.Response{
+			Status:     "504 Gateway Timeout",
+			StatusCode: http.StatusGatewayTimeout,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+
+	c.misses.Add(1)
+
+	eg := req
+	if has {
+		eg = entry.revalidate(req)
+	}
+
+	in, err := socket(eg)
+	if err != nil {
+		return nil, err
+	}
+	responseTime := c.clock.Now()
+
+	if has && in.StatusCode == http.StatusNotModified {
+		io.Copy(io.Discard, in.Body)
+		in.Body.Close()
+
+		entry.refresh(in.Header, now, responseTime)
+		c.save(key, entry)
+		return entry.response(req, responseTime), nil
+	}
+
+	return c.store(key, req, in, now, responseTime)
+}
+
+func (c *httpCache) load(key string) (*cacheEntry, bool) {
+	val, has := c.storage.Get(key)
+	if !has {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(val, &entry); err != nil {
+		c.storage.Delete(key)
+		return nil, false
+	}
+
+	return &entry, true
+}
+
+func (c *httpCache) save(key string, entry *cacheEntry) {
+	if val, err := json.Marshal(entry); err == nil {
+		c.storage.Set(key, val)
+	}
+}
+
+func (c *httpCache) store(key string, req *http.Request, in *http.Response, requestTime, responseTime time.Time) (*http.Response, error) {
+	if !isStorable(in) {
+		c.storage.Delete(key)
+		return in, nil
+	}
+
+	body, err := io.ReadAll(in.Body)
+	in.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	in.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry := &cacheEntry{
+		StatusCode:   in.StatusCode,
+		Header:       in.Header.Clone(),
+		Body:         body,
+		Vary:         varyOf(req, in.Header),
+		RequestTime:  requestTime,
+		ResponseTime: responseTime,
+	}
+	entry.Header.Del("Transfer-Encoding")
+	entry.Header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	c.save(key, entry)
+	return in, nil
+}
+
+// isConditional request controls validators or ranges by itself
+func isConditional(req *http.Request) bool {
+	for _, h := range []string{"If-None-Match", "If-Modified-Since", "If-Match", "If-Unmodified-Since", "If-Range", "Range"} {
+		if req.Header.Get(h) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// isStorable checks the response against RFC 9111, Section 3
+func isStorable(in *http.Response) bool {
+	if !cacheableStatus[in.StatusCode] {
+		return false
+	}
+
+	cres := cacheControlOf(in.Header)
+	if cres.has("no-store") || strings.TrimSpace(in.Header.Get("Vary")) == "*" {
+		return false
+	}
+
+	// Note: the response is useless for cache without explicit freshness
+	//       or validators (heuristic freshness requires Last-Modified)
+	return cres.has("max-age") ||
+		in.Header.Get("Expires") != "" ||
+		in.Header.Get("ETag") != "" ||
+		in.Header.Get("Last-Modified") != ""
+}
+
+// varyOf selects request headers listed by Vary of response
+func varyOf(req *http.Request, header http.Header) http.Header {
+	var vary http.Header
+	for _, field := range header.Values("Vary") {
+		for _, name := range strings.Split(field, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if vary == nil {
+				vary = http.Header{}
+			}
+			vary[name] = []string{strings.Join(req.Header.Values(name), ", ")}
+		}
+	}
+	return vary
+}
+
+func (entry *cacheEntry) matchVary(req *http.Request) bool {
+	for name, val := range entry.Vary {
+		if strings.Join(req.Header.Values(name), ", ") != val[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// lifetime is freshness lifetime of stored response (RFC 9111, Section 4.2.1)
+func (entry *cacheEntry) lifetime() time.Duration {
+	cres := cacheControlOf(entry.Header)
+	if maxAge, has := cres.seconds("max-age"); has {
+		return maxAge
+	}
+
+	date := entry.date()
+	if expires := entry.Header.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+		return t.Sub(date)
+	}
+
+	// Note: heuristic freshness is 10% of time since last modification
+	//       (RFC 9111, Section 4.2.2)
+	if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+		if t, err := http.ParseTime(lastModified); err == nil && date.After(t) {
+			return date.Sub(t) / 10
+		}
+	}
+
+	return 0
+}
+
+func (entry *cacheEntry) date() time.Time {
+	if t, err := http.ParseTime(entry.Header.Get("Date")); err == nil {
+		return t
+	}
+	return entry.ResponseTime
+}
+
+// age is current age of stored response (RFC 9111, Section 4.2.3)
+func (entry *cacheEntry) age(now time.Time) time.Duration {
+	apparent := max(0, entry.ResponseTime.Sub(entry.date()))
+
+	age := time.Duration(0)
+	if sec, err := strconv.Atoi(entry.Header.Get("Age")); err == nil && sec > 0 {
+		age = time.Duration(sec) * time.Second
+	}
+	delay := entry.ResponseTime.Sub(entry.RequestTime)
+
+	return max(apparent, age+delay) + now.Sub(entry.ResponseTime)
+}
+
+// fresh checks if the stored response is allowed to be served without
+// validation (RFC 9111, Section 4.2 and 5.2.1)
+func (entry *cacheEntry) fresh(creq cacheControl, now time.Time) bool {
+	cres := cacheControlOf(entry.Header)
+	if cres.has("no-cache") || creq.has("no-cache") {
+		return false
+	}
+
+	age := entry.age(now)
+	lifetime := entry.lifetime()
+
+	if maxAge, has := creq.seconds("max-age"); has && age > maxAge {
+		return false
+	}
+
+	if minFresh, has := creq.seconds("min-fresh"); has && lifetime-age < minFresh {
+		return false
+	}
+
+	if age < lifetime {
+		return true
+	}
+
+	if !creq.has("max-stale") || cres.has("must-revalidate") {
+		return false
+	}
+
+	maxStale, has := creq.seconds("max-stale")
+	return !has || age-lifetime <= maxStale
+}
+
+// revalidate makes conditional request using validators of stored response
+func (entry *cacheEntry) revalidate(req *http.Request) *http.Request {
+	etag := entry.Header.Get("ETag")
+	lastModified := entry.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return req
+	}
+
+	eg := req.Clone(req.Context())
+	if etag != "" {
+		eg.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		eg.Header.Set("If-Modified-Since", lastModified)
+	}
+	return eg
+}
+
+// refresh stored response using headers of 304 Not Modified
+// (RFC 9111, Section 4.3.4)
+func (entry *cacheEntry) refresh(header http.Header, requestTime, responseTime time.Time) {
+	for key, val := range header {
+		switch key {
+		case "Content-Length", "Transfer-Encoding", "Connection":
+			continue
+		}
+		entry.Header[key] = val
+	}
+	entry.RequestTime = requestTime
+	entry.ResponseTime = responseTime
+}
+
+func (entry *cacheEntry) response(req *http.Request, now time.Time) *http.Response {
+	header := entry.Header.Clone()
+	header.Set("Age", strconv.Itoa(int(entry.age(now)/time.Second)))
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.StatusCode, http.StatusText(entry.StatusCode)),
+		StatusCode:    entry.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       req,
+	}
+}
+
+// cacheControl is parsed Cache-Control header, directives are lower-cased
+type cacheControl map[string]string
+
+func cacheControlOf(header http.Header) cacheControl {
+	cc := cacheControl{}
+	for _, field := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(field, ",") {
+			key, val, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if key == "" {
+				continue
+			}
+			cc[strings.ToLower(key)] = strings.Trim(val, `"`)
+		}
+	}
+
+	if len(cc) == 0 && strings.Contains(strings.ToLower(header.Get("Pragma")), "no-cache") {
+		cc["no-cache"] = ""
+	}
+
+	return cc
+}
+
+func (cc cacheControl) has(directive string) bool {
+	_, has := cc[directive]
+	return has
+}
+
+func (cc cacheControl) seconds(directive string) (time.Duration, bool) {
+	val, has := cc[directive]
+	if !has || val == "" {
+		return 0, false
+	}
+
+	sec, err := strconv.Atoi(val)
+	if err != nil || sec < 0 {
+		return 0, true
+	}
+	return time.Duration(sec) * time.Second, true
+}
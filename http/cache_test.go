@@ -0,0 +1,245 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestCache(t *testing.T) {
+	var calls, revalidated atomic.Int64
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			switch r.URL.Path {
+			case "/fresh":
+				w.Header().Set("Cache-Control", "max-age=60")
+			case "/etag":
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					revalidated.Add(1)
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Header().Set("Cache-Control", "no-cache")
+				w.Header().Set("ETag", `"v1"`)
+			case "/vary":
+				w.Header().Set("Cache-Control", "max-age=60")
+				w.Header().Set("Vary", "Accept")
+			case "/nostore":
+				w.Header().Set("Cache-Control", "no-store")
+			}
+			w.Write([]byte(r.URL.Path))
+		}),
+	)
+	defer ts.Close()
+
+	get := func(path string, arrows ...µ.Arrow) µ.Arrow {
+		seq := append([]µ.Arrow{ø.URI("%s%s", ø.Authority(ts.URL), ø.Path(path))}, arrows...)
+		return µ.GET(append(seq, ƒ.Status.OK)...)
+	}
+
+	read := func(path string, buf *bytes.Buffer) µ.Arrow {
+		return µ.GET(
+			ø.URI("%s%s", ø.Authority(ts.URL), ø.Path(path)),
+			ƒ.Status.OK,
+			ƒ.Bytes(buf),
+		)
+	}
+
+	t.Run("Fresh", func(t *testing.T) {
+		calls.Store(0)
+		clock := µ.NewManualClock(time.Now())
+		stack := µ.New(µ.WithCache(µ.NewMemoryCache(0)), µ.WithClock(clock), µ.WithStats())
+
+		var a, b bytes.Buffer
+		err := stack.IO(context.Background(),
+			read("/fresh", &a),
+			read("/fresh", &b),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(calls.Load(), 1),
+			it.Equal(a.String(), "/fresh"),
+			it.Equal(b.String(), "/fresh"),
+			it.Equal(stack.(*µ.Protocol).Stats().CacheHits, 1),
+			it.Equal(stack.(*µ.Protocol).Stats().CacheMisses, 1),
+			it.Equal(stack.(*µ.Protocol).Stats().Requests, 1),
+		)
+
+		clock.Advance(61 * time.Second)
+		err = stack.IO(context.Background(), get("/fresh"))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(calls.Load(), 2),
+		)
+	})
+
+	t.Run("Age", func(t *testing.T) {
+		clock := µ.NewManualClock(time.Now())
+		stack := µ.New(µ.WithCache(µ.NewMemoryCache(0)), µ.WithClock(clock))
+
+		var age int
+		err1 := stack.IO(context.Background(), get("/fresh"))
+		clock.Advance(30 * time.Second)
+		err2 := stack.IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/fresh", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Header("Age", &age),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err1),
+			it.Nil(err2),
+			it.True(age >= 30 && age <= 31),
+		)
+	})
+
+	t.Run("Revalidate", func(t *testing.T) {
+		calls.Store(0)
+		stack := µ.New(µ.WithCache(µ.NewMemoryCache(0)))
+
+		var a, b bytes.Buffer
+		err := stack.IO(context.Background(),
+			read("/etag", &a),
+			read("/etag", &b),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(calls.Load(), 2),
+			it.Equal(revalidated.Load(), 1),
+			it.Equal(a.String(), "/etag"),
+			it.Equal(b.String(), "/etag"),
+		)
+	})
+
+	t.Run("Vary", func(t *testing.T) {
+		calls.Store(0)
+		stack := µ.New(µ.WithCache(µ.NewMemoryCache(0)))
+
+		err := stack.IO(context.Background(),
+			get("/vary", ø.Accept.JSON),
+			get("/vary", ø.Accept.JSON),
+			get("/vary", ø.Accept.XML),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(calls.Load(), 2),
+		)
+	})
+
+	t.Run("NoStore", func(t *testing.T) {
+		calls.Store(0)
+		stack := µ.New(µ.WithCache(µ.NewMemoryCache(0)))
+
+		err := stack.IO(context.Background(),
+			get("/nostore"),
+			get("/nostore"),
+			get("/fresh"),
+			get("/fresh", ø.Header("Cache-Control", "no-store")),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(calls.Load(), 4),
+		)
+	})
+
+	t.Run("Invalidate", func(t *testing.T) {
+		calls.Store(0)
+		stack := µ.New(µ.WithCache(µ.NewMemoryCache(0)))
+
+		err := stack.IO(context.Background(),
+			get("/fresh"),
+			µ.POST(
+				ø.URI("%s/fresh", ø.Authority(ts.URL)),
+				ø.ContentType.Text,
+				ø.Send("abc"),
+				ƒ.Status.OK,
+			),
+			get("/fresh"),
+			get("/fresh"),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(calls.Load(), 3),
+		)
+	})
+
+	t.Run("OnlyIfCached", func(t *testing.T) {
+		stack := µ.New(µ.WithCache(µ.NewMemoryCache(0)))
+
+		err := stack.IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/fresh", ø.Authority(ts.URL)),
+				ø.Header("Cache-Control", "only-if-cached"),
+				ƒ.Status.GatewayTimeout,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+		)
+	})
+
+	t.Run("Disk", func(t *testing.T) {
+		calls.Store(0)
+		dir := t.TempDir()
+
+		for i := 0; i < 2; i++ {
+			disk, err := µ.NewDiskCache(dir)
+			it.Then(t).Should(it.Nil(err))
+
+			var buf bytes.Buffer
+			err = µ.New(µ.WithCache(disk)).IO(context.Background(),
+				read("/fresh", &buf),
+			)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(buf.String(), "/fresh"),
+			)
+		}
+
+		it.Then(t).Should(
+			it.Equal(calls.Load(), 1),
+		)
+	})
+}
+
+func TestMemoryCache(t *testing.T) {
+	cache := µ.NewMemoryCache(2)
+	cache.Set("a", []byte("a"))
+	cache.Set("b", []byte("b"))
+	cache.Get("a")
+	cache.Set("c", []byte("c"))
+
+	_, hasA := cache.Get("a")
+	_, hasB := cache.Get("b")
+	_, hasC := cache.Get("c")
+	it.Then(t).Should(
+		it.Equal(cache.Len(), 2),
+		it.True(hasA),
+		it.True(!hasB),
+		it.True(hasC),
+	)
+
+	cache.Delete("a")
+	it.Then(t).Should(
+		it.Equal(cache.Len(), 1),
+	)
+}
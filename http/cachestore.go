@@ -0,0 +1,158 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+//
+// The file implements storage backends of HTTP cache
+//
+
+// MemoryCache is in-memory storage of HTTP cache, the least recently used
+// entries are evicted once capacity is exceeded.
+type MemoryCache struct {
+	sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key   string
+	value []byte
+}
+
+// Creates new in-memory storage for given number of entries,
+// zero capacity is unbounded.
+func NewMemoryCache(capacity int) *MemoryCache {
+	return &MemoryCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+// Get entry from the storage
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, has := c.entries[key]
+	if !has {
+		return nil, false
+	}
+
+	c.order.MoveToFront(e)
+	return e.Value.(*memoryEntry).value, true
+}
+
+// Set entry to the storage
+func (c *MemoryCache) Set(key string, value []byte) {
+	c.Lock()
+	defer c.Unlock()
+
+	if e, has := c.entries[key]; has {
+		e.Value.(*memoryEntry).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryEntry{key: key, value: value})
+
+	if c.capacity > 0 && c.order.Len() > c.capacity {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.entries, e.Value.(*memoryEntry).key)
+	}
+}
+
+// Delete entry from the storage
+func (c *MemoryCache) Delete(key string) {
+	c.Lock()
+	defer c.Unlock()
+
+	if e, has := c.entries[key]; has {
+		c.order.Remove(e)
+		delete(c.entries, key)
+	}
+}
+
+// Len returns number of entries at the storage
+func (c *MemoryCache) Len() int {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.order.Len()
+}
+
+// DiskCache is file system storage of HTTP cache, each entry is a file
+// at the directory named after hash of the key. The storage survives
+// restarts of the process, it is not bounded.
+type DiskCache struct {
+	dir string
+}
+
+// Creates new file system storage at the directory, the directory is
+// created if it does not exist.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &DiskCache{dir: dir}, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(hash[:]))
+}
+
+// Get entry from the storage
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	val, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return val, true
+}
+
+// Set entry to the storage
+func (c *DiskCache) Set(key string, value []byte) {
+	// Note: the entry is written to temporary file and renamed so that
+	//       concurrent readers never observe partially written entry.
+	f, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+
+	_, err = f.Write(value)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return
+	}
+
+	if err := os.Rename(f.Name(), c.path(key)); err != nil {
+		os.Remove(f.Name())
+	}
+}
+
+// Delete entry from the storage
+func (c *DiskCache) Delete(key string) {
+	os.Remove(c.path(key))
+}
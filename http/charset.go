@@ -0,0 +1,70 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"io"
+	"mime"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+//
+// The file implements transcoding of payloads with non UTF-8 charset
+//
+
+// CharsetReader transcodes the stream into UTF-8 from the charset declared
+// by Content-Type (e.g. text/plain; charset=Shift_JIS). The stream is
+// returned as-is if charset is not declared, it is UTF-8 or unknown.
+func CharsetReader(content string, stream io.Reader) io.Reader {
+	if r, ok := charsetReader(content, stream); ok {
+		return r
+	}
+	return stream
+}
+
+func charsetReader(content string, stream io.Reader) (io.Reader, bool) {
+	_, params, err := mime.ParseMediaType(content)
+	if err != nil {
+		return nil, false
+	}
+
+	return transcode(params["charset"], stream)
+}
+
+func transcode(charset string, stream io.Reader) (io.Reader, bool) {
+	charset = strings.ToLower(strings.TrimSpace(charset))
+	if charset == "" || charset == "utf-8" || charset == "utf8" || charset == "us-ascii" {
+		return nil, false
+	}
+
+	enc, err := htmlindex.Get(charset)
+	if err != nil || enc == encoding.Nop {
+		return nil, false
+	}
+
+	return enc.NewDecoder().Reader(stream), true
+}
+
+// xmlCharsetReader is used by xml.Decoder for charset declared by document,
+// the stream transcoded by Content-Type is passed as-is.
+func xmlCharsetReader(transcoded bool) func(string, io.Reader) (io.Reader, error) {
+	return func(charset string, stream io.Reader) (io.Reader, error) {
+		if transcoded {
+			return stream, nil
+		}
+
+		if r, ok := transcode(charset, stream); ok {
+			return r, nil
+		}
+		return stream, nil
+	}
+}
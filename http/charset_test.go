@@ -0,0 +1,137 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestCharset(t *testing.T) {
+	sjis, _ := japanese.ShiftJIS.NewEncoder().Bytes([]byte(`{"text":"こんにちは"}`))
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/latin1":
+				w.Header().Set("Content-Type", "text/plain; charset=ISO-8859-1")
+				w.Write([]byte{'c', 'a', 'f', 0xe9})
+			case "/sjis":
+				w.Header().Set("Content-Type", "application/json; charset=Shift_JIS")
+				w.Write(sjis)
+			case "/xml":
+				w.Header().Set("Content-Type", "application/xml")
+				w.Write([]byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><text>caf\xe9</text>"))
+			case "/unknown":
+				w.Header().Set("Content-Type", "text/plain; charset=x-unknown")
+				w.Write([]byte("cafe"))
+			}
+		}),
+	)
+	defer ts.Close()
+
+	t.Run("Bytes", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/latin1", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Bytes(buf),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(buf.String(), "caf\xe9"),
+		)
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/latin1", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Text(buf),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(buf.String(), "café"),
+		)
+	})
+
+	t.Run("Body", func(t *testing.T) {
+		var val struct {
+			Text string `json:"text"`
+		}
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/sjis", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Body(&val),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(val.Text, "こんにちは"),
+		)
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/sjis", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Match(`{"text":"こんにちは"}`),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+		)
+	})
+
+	t.Run("XML", func(t *testing.T) {
+		var val string
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/xml", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Body(&val),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(val, "café"),
+		)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/unknown", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Text(buf),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(buf.String(), "cafe"),
+		)
+	})
+}
@@ -0,0 +1,130 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+//
+// The file implements injectable clock used for timing decisions of the
+// stack (retries, circuit breaker, rate limiting and caching) and for
+// measurements of requests.
+//
+
+// Clock is the source of time for the stack
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the wall clock, it is default for the stack
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// ManualClock is the clock advanced by tests, timers fire only when
+// the clock is advanced beyond their deadline.
+//
+//	clock := http.NewManualClock(time.Now())
+//	stack := http.New(http.WithClock(clock))
+//	go stack.IO(context.Background(), http.Retry(3, time.Second, arrow))
+//	...
+//	clock.Advance(time.Second)
+type ManualClock struct {
+	lock   sync.Mutex
+	now    time.Time
+	timers []manualTimer
+}
+
+type manualTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// Creates new instance of manual clock at the time
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{now: t}
+}
+
+// Now returns current time of the clock
+func (c *ManualClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+// After waits for the clock to be advanced by the duration
+func (c *ManualClock) After(d time.Duration) <-chan time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.timers = append(c.timers, manualTimer{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance the clock by the duration, firing expired timers
+func (c *ManualClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now = c.now.Add(d)
+
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].at.Before(c.timers[j].at) })
+
+	n := 0
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			break
+		}
+		t.ch <- c.now
+		n++
+	}
+	c.timers = c.timers[n:]
+}
+
+// Waiters is number of pending timers, use it to synchronize tests with
+// the stack before advancing the clock.
+func (c *ManualClock) Waiters() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return len(c.timers)
+}
+
+func withClock(cat *Protocol, clock Clock) error {
+	cat.clock = clock
+	return nil
+}
+
+// Clock of the context, the wall clock is used by context without stack.
+// Arrows use it instead of time.Now for timing decisions.
+func (ctx *Context) Clock() Clock {
+	return clockOf(ctx.stack)
+}
+
+// clock of the stack, the wall clock is used by default
+func clockOf(cat *Protocol) Clock {
+	if cat == nil || cat.clock == nil {
+		return SystemClock
+	}
+	return cat.clock
+}
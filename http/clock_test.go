@@ -0,0 +1,170 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+// waits for the stack to sleep on the clock
+func waitFor(t *testing.T, clock *µ.ManualClock, n int) {
+	t.Helper()
+
+	for i := 0; i < 1000 && clock.Waiters() < n; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if clock.Waiters() < n {
+		t.Fatalf("expected %d waiters, got %d", n, clock.Waiters())
+	}
+}
+
+func TestManualClock(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Advance", func(t *testing.T) {
+		clock := µ.NewManualClock(t0)
+		a := clock.After(time.Second)
+		b := clock.After(2 * time.Second)
+
+		clock.Advance(time.Second)
+		select {
+		case <-b:
+			t.Fatal("timer fired before deadline")
+		default:
+		}
+
+		it.Then(t).Should(
+			it.Equal(<-a, t0.Add(time.Second)),
+			it.Equal(clock.Now(), t0.Add(time.Second)),
+			it.Equal(clock.Waiters(), 1),
+		)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		ts := flaky(2)
+		defer ts.Close()
+
+		clock := µ.NewManualClock(t0)
+		ctx := µ.New(µ.WithClock(clock)).WithContext(context.Background())
+
+		done := make(chan error)
+		go func() {
+			done <- ctx.IO(
+				µ.Retry(3, time.Hour,
+					µ.GET(
+						ø.URI(ts.URL),
+						ƒ.Status.OK,
+					),
+				),
+			)
+		}()
+
+		waitFor(t, clock, 1)
+		clock.Advance(time.Hour)
+		waitFor(t, clock, 1)
+		clock.Advance(2 * time.Hour)
+
+		it.Then(t).Should(
+			it.Nil(<-done),
+			it.Equal(len(ctx.Attempts), 2),
+			it.Equal(ctx.Attempts[0].Time, t0),
+			it.Equal(ctx.Attempts[1].Time, t0.Add(time.Hour)),
+		)
+	})
+
+	t.Run("CircuitBreaker", func(t *testing.T) {
+		ts := flaky(2)
+		defer ts.Close()
+
+		clock := µ.NewManualClock(t0)
+		stack := µ.New(µ.WithClock(clock), µ.WithCircuitBreaker(2, time.Hour))
+		req := µ.GET(ø.URI(ts.URL), ƒ.Status.OK)
+
+		stack.IO(context.Background(), req)
+		stack.IO(context.Background(), req)
+
+		var open *µ.CircuitOpen
+		err := stack.IO(context.Background(), req)
+		it.Then(t).Should(
+			it.True(errors.As(err, &open)),
+			it.Equal(open.Until, t0.Add(time.Hour)),
+		)
+
+		clock.Advance(time.Hour)
+		err = stack.IO(context.Background(), req)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("RateLimit", func(t *testing.T) {
+		ts := flaky(0)
+		defer ts.Close()
+
+		clock := µ.NewManualClock(t0)
+		stack := µ.New(µ.WithClock(clock), µ.WithRateLimit(1, 1))
+		req := µ.GET(ø.URI(ts.URL), ƒ.Status.OK)
+
+		err := stack.IO(context.Background(), req)
+		it.Then(t).Should(it.Nil(err))
+
+		done := make(chan error)
+		go func() { done <- stack.IO(context.Background(), req) }()
+
+		waitFor(t, clock, 1)
+		clock.Advance(time.Second)
+		it.Then(t).Should(it.Nil(<-done))
+	})
+
+	t.Run("HAR", func(t *testing.T) {
+		ts := flaky(0)
+		defer ts.Close()
+
+		har := µ.NewHAR(0)
+		clock := µ.NewManualClock(t0)
+		err := µ.New(µ.WithHAR(har), µ.WithClock(clock)).IO(context.Background(),
+			µ.GET(ø.URI(ts.URL), ƒ.Status.OK),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(har.Entries()[0].StartedDateTime, t0),
+			it.Equal(har.Entries()[0].Time, 0.0),
+		)
+	})
+
+	t.Run("Header", func(t *testing.T) {
+		ts := httptest.NewServer(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Timeout", t0.Add(time.Minute).Format(time.RFC1123))
+			}),
+		)
+		defer ts.Close()
+
+		var d time.Duration
+		err := µ.New(µ.WithClock(µ.NewManualClock(t0))).IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.HeaderOf[time.Duration]("X-Timeout").To(&d),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(d, time.Minute),
+		)
+	})
+}
@@ -0,0 +1,57 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"io"
+	"mime"
+	"strings"
+	"sync"
+)
+
+//
+// The file implements registry of pluggable codecs, encoders and decoders
+// of payload for content types not supported by the library out of the box.
+//
+
+// Codec encodes and decodes payload of the content type
+type Codec interface {
+	Encode(w io.Writer, data any) error
+	Decode(r io.Reader, data any) error
+}
+
+var codecs = struct {
+	sync.RWMutex
+	media map[string]Codec
+}{media: map[string]Codec{}}
+
+// RegisterCodec makes the codec available for the media type (e.g.
+// application/msgpack), both ø.Send and ƒ.Body consult the registry before
+// built-in codecs. Registering the same media type twice replaces the codec.
+// The extension x/codec supplies MessagePack and CBOR codecs.
+func RegisterCodec(mediaType string, codec Codec) {
+	codecs.Lock()
+	defer codecs.Unlock()
+
+	codecs.media[strings.ToLower(mediaType)] = codec
+}
+
+// LookupCodec of the content type, parameters of content type are ignored.
+func LookupCodec(content string) (Codec, bool) {
+	media, _, err := mime.ParseMediaType(content)
+	if err != nil {
+		media = strings.ToLower(strings.TrimSpace(content))
+	}
+
+	codecs.RLock()
+	defer codecs.RUnlock()
+
+	codec, has := codecs.media[media]
+	return codec, has
+}
@@ -0,0 +1,74 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+// echo server replies with request body and content type
+func echo() *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+			io.Copy(w, r.Body)
+		}),
+	)
+}
+
+type upper struct{}
+
+func (upper) Encode(w io.Writer, data any) error {
+	_, err := io.WriteString(w, strings.ToUpper(*data.(*string)))
+	return err
+}
+
+func (upper) Decode(r io.Reader, data any) error {
+	b, err := io.ReadAll(r)
+	*data.(*string) = strings.ToLower(string(b))
+	return err
+}
+
+func TestCodec(t *testing.T) {
+	ts := echo()
+	defer ts.Close()
+
+	t.Run("Register", func(t *testing.T) {
+		µ.RegisterCodec("application/x-upper", upper{})
+
+		var body string
+		text := "example.com"
+		err := µ.New().IO(context.Background(),
+			µ.POST(
+				ø.URI(ts.URL),
+				ø.ContentType.Set("application/x-upper; charset=utf-8"),
+				ø.Send(&text),
+				ƒ.Status.OK,
+				ƒ.Body(&body),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(body, "example.com"),
+		)
+
+		_, has := µ.LookupCodec("application/x-upper")
+		it.Then(t).Should(it.True(has))
+	})
+}
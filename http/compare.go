@@ -0,0 +1,115 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+//
+// The file implements comparison of two live endpoints
+//
+
+// Snapshot of HTTP response compared by Compare. Volatile headers
+// (Date, Content-Length, Connection, Keep-Alive, Transfer-Encoding) are
+// excluded. JSON payload is decoded, other payloads are kept as string.
+type Snapshot struct {
+	StatusCode int
+	Header     http.Header
+	Body       any
+}
+
+// Compare performs both requests and diffs their responses. It is used for
+// blue/green and migration validation where the expected value lives on
+// the other environment. The cmp options customizes the comparison of Snapshot.
+//
+//	http.Compare(
+//		http.GET(ø.URI("https://blue.example.com/api"), ƒ.Status.OK),
+//		http.GET(ø.URI("https://green.example.com/api"), ƒ.Status.OK),
+//		cmpopts.IgnoreMapEntries(func(k string, v []string) bool { return k == "Server" }),
+//	)
+func Compare(a, b Arrow, opts ...cmp.Option) Arrow {
+	return func(cat *Context) error {
+		expect, err := snapshot(cat, a)
+		if err != nil {
+			return err
+		}
+
+		actual, err := snapshot(cat, b)
+		if err != nil {
+			return err
+		}
+
+		if diff := cmp.Diff(expect, actual, opts...); diff != "" {
+			return &gurl.NoMatch{
+				ID:       "http.Compare",
+				Diff:     diff,
+				Protocol: "Response",
+				Expect:   expect,
+				Actual:   actual,
+			}
+		}
+
+		return nil
+	}
+}
+
+var volatileHeaders = []string{
+	"Date", "Content-Length", "Connection", "Keep-Alive", "Transfer-Encoding",
+}
+
+func snapshot(cat *Context, f Arrow) (Snapshot, error) {
+	ctx := cat.stack.WithContext(cat.Context)
+	if err := f(ctx); err != nil {
+		ctx.discardBody()
+		return Snapshot{}, err
+	}
+
+	if ctx.Response == nil {
+		return Snapshot{}, &gurl.NoMatch{
+			ID:       "http.Compare",
+			Diff:     "- Response: *",
+			Protocol: "Response",
+		}
+	}
+
+	in := ctx.Response
+	defer ctx.discardBody()
+
+	buf, err := io.ReadAll(in.Body)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	header := in.Header.Clone()
+	for _, h := range volatileHeaders {
+		header.Del(h)
+	}
+
+	var body any = string(buf)
+	if strings.Contains(in.Header.Get("Content-Type"), "json") && len(buf) > 0 {
+		var val any
+		if err := json.Unmarshal(buf, &val); err != nil {
+			return Snapshot{}, err
+		}
+		body = val
+	}
+
+	return Snapshot{
+		StatusCode: in.StatusCode,
+		Header:     header,
+		Body:       body,
+	}, nil
+}
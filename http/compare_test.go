@@ -0,0 +1,82 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestCompare(t *testing.T) {
+	blue := mock()
+	defer blue.Close()
+
+	green := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Content-Type", "application/json")
+			w.Header().Add("X-Version", "green")
+			switch r.URL.Path {
+			case "/json":
+				w.Write([]byte(`{"site":"example.com"}`))
+			default:
+				w.Write([]byte(`{"site":"example.net"}`))
+			}
+		}),
+	)
+	defer green.Close()
+
+	ignoreVersion := cmpopts.IgnoreMapEntries(func(k string, v []string) bool { return k == "X-Version" })
+
+	t.Run("Equal", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.Compare(
+				µ.GET(ø.URI(blue.URL+"/json"), ƒ.Status.OK),
+				µ.GET(ø.URI(green.URL+"/json"), ƒ.Status.OK),
+				ignoreVersion,
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("Header", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.Compare(
+				µ.GET(ø.URI(blue.URL+"/json"), ƒ.Status.OK),
+				µ.GET(ø.URI(green.URL+"/json"), ƒ.Status.OK),
+			),
+		)
+		it.Then(t).Should(it.True(errors.As(err, new(*gurl.NoMatch))))
+	})
+
+	t.Run("Body", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.Compare(
+				µ.GET(ø.URI(blue.URL+"/json"), ƒ.Status.OK),
+				µ.GET(ø.URI(green.URL+"/other"), ƒ.Status.OK),
+				ignoreVersion,
+			),
+		)
+
+		var nomatch *gurl.NoMatch
+		it.Then(t).Should(
+			it.True(errors.As(err, &nomatch)),
+			it.String(nomatch.Diff).Contain("example.net"),
+		)
+	})
+}
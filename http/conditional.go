@@ -0,0 +1,118 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+//
+// The file implements conditional requests (ETag / Last-Modified workflow)
+//
+
+// ConditionalCache stores validators (ETag, Last-Modified) and payload of
+// responses per URL. The cache is safe for concurrent use and is shared
+// across requests and stacks. It is built on the private cache of the stack
+// (see http.WithCache) but it always revalidates stored responses.
+type ConditionalCache struct {
+	sync.Mutex
+	storage *MemoryCache
+}
+
+// Creates new instance of conditional cache
+func NewConditionalCache() *ConditionalCache {
+	return &ConditionalCache{
+		storage: NewMemoryCache(0),
+	}
+}
+
+// Flush all cached entries
+func (c *ConditionalCache) Flush() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.storage = NewMemoryCache(0)
+}
+
+// Len returns number of cached entries
+func (c *ConditionalCache) Len() int {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.storage.Len()
+}
+
+// Conditional makes the current GET request conditional. The response
+// with ETag or Last-Modified headers is stored per URL, subsequent requests
+// send If-None-Match and If-Modified-Since headers. The response
+// 304 Not Modified is replaced with the cached one (200 OK), so that
+// ƒ.Status.OK and ƒ.Body read the cached value.
+//
+// The conditional request bypasses the cache of the stack (http.WithCache),
+// the stack does not serve requests with validators controlled by client.
+//
+//	cache := http.NewConditionalCache()
+//
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		http.Conditional(cache),
+//		ƒ.Status.OK,
+//		ƒ.Body(&data),
+//	)
+func Conditional(cache *ConditionalCache) Arrow {
+	return func(ctx *Context) error {
+		ctx.conditional = cache
+		return nil
+	}
+}
+
+// do revalidates stored response of GET request, other requests are
+// forwarded to the socket as-is.
+func (c *ConditionalCache) do(req *http.Request, clock Clock, socket func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if req.Method != http.MethodGet || isConditional(req) {
+		return socket(req)
+	}
+
+	c.Lock()
+	cache := &httpCache{storage: c.storage, clock: clock}
+	c.Unlock()
+
+	key := cacheKey(req)
+	entry, has := cache.load(key)
+	if has && !entry.matchVary(req) {
+		has = false
+	}
+
+	eg := req
+	if has {
+		eg = entry.revalidate(req)
+	}
+
+	requestTime := clock.Now()
+	in, err := socket(eg)
+	if err != nil {
+		return nil, err
+	}
+	responseTime := clock.Now()
+
+	if has && in.StatusCode == http.StatusNotModified {
+		io.Copy(io.Discard, in.Body)
+		in.Body.Close()
+
+		// Note: 304 Not Modified carries the refreshed metadata of
+		//       the stored response (RFC 9111, Section 4.3.4).
+		entry.refresh(in.Header, requestTime, responseTime)
+		cache.save(key, entry)
+		return entry.response(req, responseTime), nil
+	}
+
+	return cache.store(key, req, in, requestTime, responseTime)
+}
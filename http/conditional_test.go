@@ -0,0 +1,161 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestConditional(t *testing.T) {
+	var inm, ims []string
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			inm = append(inm, r.Header.Get("If-None-Match"))
+			ims = append(ims, r.Header.Get("If-Modified-Since"))
+
+			switch r.URL.Path {
+			case "/etag":
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					w.Header().Set("X-Value", "refreshed")
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Header().Set("ETag", `"v1"`)
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"site": "example.com"}`))
+			case "/lastmod":
+				if r.Header.Get("If-Modified-Since") != "" {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+				w.Write([]byte("hello"))
+			default:
+				w.Write([]byte("hello"))
+			}
+		}),
+	)
+	defer ts.Close()
+
+	type Site struct {
+		Site string `json:"site"`
+	}
+
+	cache := µ.NewConditionalCache()
+	stack := µ.New()
+
+	t.Run("ETag", func(t *testing.T) {
+		inm = nil
+		get := func(site *Site) µ.Arrow {
+			return µ.GET(
+				ø.URI("%s/etag", ø.Authority(ts.URL)),
+				µ.Conditional(cache),
+				ƒ.Status.OK,
+				ƒ.ContentType.JSON,
+				ƒ.Body(site),
+			)
+		}
+
+		var a, b Site
+		var value string
+		err := stack.IO(context.Background(),
+			get(&a),
+			get(&b),
+			µ.GET(
+				ø.URI("%s/etag", ø.Authority(ts.URL)),
+				µ.Conditional(cache),
+				ƒ.Status.OK,
+				ƒ.Header("ETag", `"v1"`),
+				ƒ.Header("X-Value", &value),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(a.Site, "example.com"),
+			it.Equal(b.Site, "example.com"),
+			it.Equal(value, "refreshed"),
+			it.Seq(inm).Equal("", `"v1"`, `"v1"`),
+		)
+	})
+
+	t.Run("LastModified", func(t *testing.T) {
+		ims = nil
+		get := func(text *bytes.Buffer) µ.Arrow {
+			return µ.GET(
+				ø.URI("%s/lastmod", ø.Authority(ts.URL)),
+				µ.Conditional(cache),
+				ƒ.Status.OK,
+				ƒ.Bytes(text),
+			)
+		}
+
+		var a, b bytes.Buffer
+		err := stack.IO(context.Background(), get(&a), get(&b))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(a.String(), "hello"),
+			it.Equal(b.String(), "hello"),
+			it.Seq(ims).Equal("", "Mon, 02 Jan 2006 15:04:05 GMT"),
+		)
+	})
+
+	t.Run("Unconditional", func(t *testing.T) {
+		inm = nil
+		err := stack.IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/etag", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(inm).Equal(""),
+		)
+	})
+
+	t.Run("WithCache", func(t *testing.T) {
+		inm = nil
+		shared := µ.NewConditionalCache()
+		get := func(site *Site) µ.Arrow {
+			return µ.GET(
+				ø.URI("%s/etag", ø.Authority(ts.URL)),
+				µ.Conditional(shared),
+				ƒ.Status.OK,
+				ƒ.Body(site),
+			)
+		}
+
+		var a, b Site
+		err := µ.New(µ.WithCache(µ.NewMemoryCache(16))).IO(context.Background(),
+			get(&a),
+			get(&b),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(a.Site, "example.com"),
+			it.Equal(b.Site, "example.com"),
+			it.Seq(inm).Equal("", `"v1"`),
+		)
+	})
+
+	t.Run("Flush", func(t *testing.T) {
+		it.Then(t).Should(it.Equal(cache.Len(), 2))
+		cache.Flush()
+		it.Then(t).Should(it.Equal(cache.Len(), 0))
+	})
+}
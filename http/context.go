@@ -11,10 +11,12 @@ package http
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/http/httputil"
+	"strings"
 )
 
 //
@@ -25,21 +27,32 @@ import (
 type Context struct {
 	context.Context
 
-	Host     string
-	Method   string
-	Request  *http.Request
-	Response *http.Response
-	Payload  []byte
-	stack    *Protocol
+	Host        string
+	Method      string
+	Request     *http.Request
+	Response    *http.Response
+	Payload     []byte
+	Proxy       *ProxyConnect
+	Attempts    []Attempt
+	Redirects   []Redirect
+	Tags        map[string]string
+	tagged      map[string]string
+	stack       *Protocol
+	scope       *scope
+	probe       *probe
+	conditional *ConditionalCache
+	shadow      *shadow
 }
 
 // IO executes protocol operations
 func (ctx *Context) IO(arrows ...Arrow) error {
 	for _, f := range arrows {
 		if err := f(ctx); err != nil {
+			ctx.release()
 			return err
 		}
 	}
+	ctx.release()
 
 	if ctx.Response != nil {
 		// Note: due to Golang HTTP pool implementation we need to consume and
@@ -64,19 +77,99 @@ func (ctx *Context) IO(arrows ...Arrow) error {
 
 // Unsafe evaluates current context of HTTP I/O
 func (ctx *Context) Unsafe() error {
+	if ctx.probe != nil {
+		return nil
+	}
+
 	eg := ctx.Request
 
 	if ctx.Context != nil {
 		eg = eg.WithContext(ctx.Context)
 	}
 
+	eg = withTags(ctx, eg)
+
+	if eg.Header == nil {
+		eg.Header = http.Header{}
+	}
+
+	for key, val := range ctx.stack.headers {
+		if _, has := eg.Header[key]; !has {
+			eg.Header[key] = append([]string(nil), val...)
+		}
+	}
+
+	if ctx.stack.proxy {
+		ctx.Proxy = nil
+		eg = withProxyTrace(ctx, eg)
+	}
+
+	if ctx.stack.redirects {
+		ctx.Redirects = nil
+		eg = withRedirectTrace(ctx, eg)
+	}
+
+	if ctx.stack.breaker != nil {
+		if err := ctx.stack.breaker.allow(eg.URL.Host, ctx.Clock().Now()); err != nil {
+			return err
+		}
+	}
+
+	if ctx.stack.limiter != nil {
+		if err := ctx.stack.limiter.wait(eg.Context(), eg.URL.Host, ctx.Clock()); err != nil {
+			return err
+		}
+	}
+
+	var mirror func(*http.Response) error
+	if ctx.shadow != nil {
+		f, err := ctx.shadow.mirror(ctx.stack, eg)
+		if err != nil {
+			return err
+		}
+		mirror = f
+	}
+
 	ctx.logSend(ctx.stack.LogLevel, eg)
 
-	in, err := ctx.stack.Do(eg)
+	t := ctx.Clock().Now()
+	var in *http.Response
+	var err error
+	if ctx.conditional != nil {
+		in, err = ctx.conditional.do(eg, ctx.Clock(), ctx.stack.do)
+	} else {
+		in, err = ctx.stack.do(eg)
+	}
+	in, err = ctx.stack.limitHeaders(eg, in, err)
+	in, err = ctx.stack.proxyStatus(eg, in, err)
+	observe(eg.Context(), in, ctx.Clock().Now().Sub(t), err)
+	if ctx.stack.breaker != nil {
+		ctx.stack.breaker.record(eg.URL.Host, in, err, ctx.Clock().Now())
+	}
 	if err != nil {
 		return err
 	}
 
+	if ctx.stack.Compression {
+		if err := decompress(in); err != nil {
+			in.Body.Close()
+			return err
+		}
+	}
+
+	for _, f := range ctx.stack.rewrite {
+		if err := f(in); err != nil {
+			in.Body.Close()
+			return err
+		}
+	}
+
+	if mirror != nil {
+		if err := mirror(in); err != nil {
+			return err
+		}
+	}
+
 	if ctx.stack.Memento {
 		ctx.Payload, err = io.ReadAll(in.Body)
 		if err != nil {
@@ -84,6 +177,10 @@ func (ctx *Context) Unsafe() error {
 		}
 
 		in.Body = io.NopCloser(bytes.NewBuffer(ctx.Payload))
+
+		if ctx.stack.schemas != nil {
+			ctx.stack.schemas.observe(eg, in, ctx.Payload)
+		}
 	}
 
 	ctx.Response = in
@@ -116,7 +213,7 @@ func (ctx *Context) discardBody() error {
 func (ctx *Context) logSend(level int, eg *http.Request) {
 	if level >= 1 {
 		if msg, err := httputil.DumpRequest(eg, level == 3); err == nil {
-			log.Printf(">>>>\n%s\n", msg)
+			ctx.stack.logf(">>>>%s\n%s\n", ctx.logTags(), ctx.formatDump(msg, eg.Header))
 		}
 	}
 }
@@ -124,7 +221,66 @@ func (ctx *Context) logSend(level int, eg *http.Request) {
 func (ctx *Context) logRecv(level int, in *http.Response) {
 	if level >= 2 {
 		if msg, err := httputil.DumpResponse(in, level == 3); err == nil {
-			log.Printf("<<<<\n%s\n", msg)
+			ctx.stack.logf("<<<<%s\n%s\n", ctx.logTags(), ctx.formatDump(msg, in.Header))
 		}
 	}
 }
+
+func (ctx *Context) logTags() string {
+	if len(ctx.Tags) == 0 {
+		return ""
+	}
+	return " " + formatTags(ctx.Tags)
+}
+
+func (ctx *Context) formatDump(msg []byte, header http.Header) []byte {
+	if ctx.stack.LogPrettyJSON && strings.Contains(header.Get("Content-Type"), "json") {
+		msg = prettyDump(msg)
+	}
+
+	return truncateDump(msg, ctx.stack.LogPayloadLimit)
+}
+
+// indents JSON payload of the dump, the dump is intact if payload is not valid JSON
+func prettyDump(msg []byte) []byte {
+	at := bytes.Index(msg, []byte("\r\n\r\n"))
+	if at == -1 {
+		return msg
+	}
+
+	head, body := msg[:at+4], msg[at+4:]
+	if len(body) == 0 {
+		return msg
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(msg)*2))
+	out.Write(head)
+	if err := json.Indent(out, body, "", "  "); err != nil {
+		return msg
+	}
+
+	return out.Bytes()
+}
+
+// truncates payload of the dump to the limit of bytes, headers are intact
+func truncateDump(msg []byte, limit int) []byte {
+	if limit <= 0 {
+		return msg
+	}
+
+	at := bytes.Index(msg, []byte("\r\n\r\n"))
+	if at == -1 {
+		return msg
+	}
+
+	head, body := msg[:at+4], msg[at+4:]
+	if len(body) <= limit {
+		return msg
+	}
+
+	out := make([]byte, 0, len(head)+limit+64)
+	out = append(out, head...)
+	out = append(out, body[:limit]...)
+	out = append(out, fmt.Sprintf("...\n[truncated %d of %d bytes]", len(body)-limit, len(body))...)
+	return out
+}
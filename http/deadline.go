@@ -0,0 +1,83 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+//
+// The file implements deadlines scoped to single request
+//
+
+type scope struct {
+	parent context.Context
+	cancel []context.CancelFunc
+}
+
+// WithDeadline derives the child context with deadline for the current
+// request only. The parent context is restored once the request is
+// evaluated, the child one is released when the response is consumed.
+// Use ø.Timeout and ø.Deadline arrows instead of calling it directly.
+func (ctx *Context) WithDeadline(t time.Time) {
+	if ctx.scope == nil {
+		ctx.scope = &scope{parent: ctx.Context}
+	}
+
+	parent := ctx.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	child, cancel := context.WithDeadline(parent, t)
+	ctx.Context = child
+	ctx.scope.cancel = append(ctx.scope.cancel, cancel)
+}
+
+// release the scope of request, restoring the parent context and dropping
+// the conditional cache of the request
+func (ctx *Context) release() {
+	ctx.conditional = nil
+
+	if ctx.scope == nil {
+		return
+	}
+
+	s := ctx.scope
+	ctx.scope = nil
+	ctx.Context = s.parent
+
+	cancel := func() {
+		for _, f := range s.cancel {
+			f()
+		}
+	}
+
+	if ctx.Response == nil || ctx.Response.Body == nil {
+		cancel()
+		return
+	}
+
+	// Note: the deadline covers reading of the response body as well,
+	//       the child context is released when body is closed.
+	ctx.Response.Body = &scopedBody{ReadCloser: ctx.Response.Body, cancel: cancel}
+}
+
+type scopedBody struct {
+	io.ReadCloser
+	cancel func()
+}
+
+func (b *scopedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
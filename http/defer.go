@@ -0,0 +1,108 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+//
+// The file implements deferred (cleanup) arrows
+//
+
+type deferredKey struct{}
+
+// deferred is LIFO of cleanup arrows registered within the scope
+type deferred struct {
+	sync.Mutex
+	seq []Arrow
+}
+
+func (d *deferred) push(f Arrow) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.seq = append(d.seq, f)
+}
+
+func (d *deferred) pop() Arrow {
+	d.Lock()
+	defer d.Unlock()
+
+	if len(d.seq) == 0 {
+		return nil
+	}
+
+	f := d.seq[len(d.seq)-1]
+	d.seq = d.seq[:len(d.seq)-1]
+	return f
+}
+
+func withDeferred(ctx context.Context) (context.Context, *deferred) {
+	if d, ok := ctx.Value(deferredKey{}).(*deferred); ok {
+		return ctx, d
+	}
+
+	d := &deferred{}
+	return context.WithValue(ctx, deferredKey{}, d), d
+}
+
+// ErrDeferScope is returned if Defer is evaluated outside of Once or Stack.IO
+var ErrDeferScope = errors.New("http.Defer is evaluated outside of Once or Stack.IO")
+
+// Defer registers compensating arrows (e.g. DELETE of created resources),
+// which are evaluated in LIFO order at the end of Once or Stack.IO even if
+// earlier steps fail. Promises consumed by deferred arrows are resolved
+// at the time of cleanup.
+//
+//	var id http.Promise[string]
+//
+//	http.Join(
+//		http.POST(
+//			ø.URI("https://example.com/users"),
+//			ƒ.Status.Created,
+//			ƒ.Fulfill(&id),
+//		),
+//		http.Defer(
+//			http.DELETE(
+//				ø.URI("https://example.com/users/%s", &id),
+//				ƒ.Status.NoContent,
+//			),
+//		),
+//	)
+func Defer(arrows ...Arrow) Arrow {
+	return func(ctx *Context) error {
+		if ctx.Context == nil {
+			return ErrDeferScope
+		}
+
+		d, ok := ctx.Context.Value(deferredKey{}).(*deferred)
+		if !ok {
+			return ErrDeferScope
+		}
+
+		d.push(Join(arrows...))
+		return nil
+	}
+}
+
+// cleanup evaluates deferred arrows, each one at own context
+func (stack *Protocol) cleanup(ctx context.Context, d *deferred, f func(*Context, time.Duration, error)) {
+	ctx = context.WithoutCancel(ctx)
+
+	for arrow := d.pop(); arrow != nil; arrow = d.pop() {
+		c := stack.WithContext(ctx)
+		t := c.Clock().Now()
+		err := c.IO(arrow)
+		f(c, c.Clock().Now().Sub(t), err)
+	}
+}
@@ -0,0 +1,154 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestDefer(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	step := func(id string, seq *[]string) µ.Arrow {
+		return func(*µ.Context) error {
+			*seq = append(*seq, id)
+			return nil
+		}
+	}
+
+	t.Run("IO", func(t *testing.T) {
+		var seq []string
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				µ.Defer(step("a", &seq)),
+			),
+			µ.Defer(step("b", &seq)),
+			step("c", &seq),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal("c", "b", "a"),
+		)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		var seq []string
+		err := µ.New().IO(context.Background(),
+			µ.Defer(step("a", &seq)),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.Status.NotFound,
+			),
+			µ.Defer(step("b", &seq)),
+		)
+		it.Then(t).Should(
+			it.Seq(seq).Equal("a"),
+		).ShouldNot(
+			it.Nil(err),
+		)
+	})
+
+	t.Run("CleanupFailure", func(t *testing.T) {
+		failure := errors.New("cleanup")
+		err := µ.New().IO(context.Background(),
+			µ.Defer(func(*µ.Context) error { return failure }),
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, failure)),
+		)
+	})
+
+	t.Run("Once", func(t *testing.T) {
+		var seq []string
+		stack := µ.New(µ.WithHost(ts.URL))
+		status, deferred, err := µ.OnceDeferred(stack,
+			µ.Suite{Name: "a", Spec: func() µ.Arrow {
+				return µ.Join(
+					µ.Defer(step("a", &seq)),
+					step("1", &seq),
+				)
+			}},
+			µ.Suite{Name: "b", Spec: func() µ.Arrow {
+				return µ.Join(
+					µ.Defer(
+						µ.GET(
+							ø.URI("/json"),
+							ƒ.Status.NotFound,
+						),
+					),
+					µ.GET(
+						ø.URI("/json"),
+						ƒ.Status.NotFound,
+					),
+					step("2", &seq),
+				)
+			}},
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal("1", "a"),
+			it.Equal(len(status), 2),
+			it.Equal(status[0].Status, "success"),
+			it.Equal(status[1].Status, "nomatch"),
+			it.Equal(len(deferred), 2),
+			it.Equal(deferred[0].ID, "defer"),
+			it.Equal(deferred[0].Status, "nomatch"),
+			it.Equal(deferred[1].ID, "defer"),
+			it.Equal(deferred[1].Status, "success"),
+		)
+	})
+
+	t.Run("OnceStatus", func(t *testing.T) {
+		var seq []string
+		status := µ.Once(µ.New(),
+			func() µ.Arrow { return µ.Defer(step("a", &seq)) },
+		)
+		it.Then(t).Should(
+			it.Seq(seq).Equal("a"),
+			it.Equal(len(status), 1),
+			it.Equal(status[0].Status, "success"),
+		)
+	})
+
+	t.Run("OnceCleanupFailure", func(t *testing.T) {
+		status := µ.Once(µ.New(),
+			func() µ.Arrow {
+				return µ.Defer(func(*µ.Context) error { return errors.New("cleanup") })
+			},
+		)
+		it.Then(t).Should(
+			it.Equal(len(status), 1),
+			it.Equal(status[0].Status, "failure"),
+			it.Equal(status[0].Reason, "cleanup"),
+		)
+	})
+
+	t.Run("UnsupportedStack", func(t *testing.T) {
+		_, _, err := µ.OnceDeferred(struct{ µ.Stack }{µ.New()})
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+
+	t.Run("NoScope", func(t *testing.T) {
+		ctx := µ.New().WithContext(context.Background())
+		err := ctx.IO(µ.Defer())
+		it.Then(t).Should(
+			it.True(errors.Is(err, µ.ErrDeferScope)),
+		)
+	})
+}
@@ -0,0 +1,124 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+//
+// The file implements introspection of composed arrows
+//
+
+// Step is the metadata of arrow, it is recorded by introspection
+type Step struct {
+	Kind   string   `json:"kind"`
+	Method string   `json:"method,omitempty"`
+	URI    string   `json:"uri,omitempty"`
+	Status []string `json:"status,omitempty"`
+}
+
+// Describe inspects the composed arrow without I/O, it returns the steps
+// of the composition (e.g. method, URI template, expected status codes).
+// Arrows marked by Annotated describe themselves using Context.Annotate,
+// other arrows are not evaluated, they are described by their name.
+//
+//	for _, step := range http.Describe(arrow) {
+//		fmt.Println(step.Kind, step.Method, step.URI, step.Status)
+//	}
+func Describe(arrow Arrow) []Step {
+	ctx := &Context{
+		Context: nil,
+		Method:  http.MethodGet,
+		stack:   &Protocol{clock: SystemClock},
+		probe:   &probe{},
+	}
+	ctx.probe.eval(ctx, arrow)
+
+	return ctx.probe.steps
+}
+
+// Annotated marks the arrow as annotating itself, so that Describe
+// evaluates it. The arrow shall call Context.Annotate before any I/O.
+//
+//	func URI(uri string) http.Arrow {
+//		return http.Annotated(func(ctx *http.Context) error { ... })
+//	}
+func Annotated(f Arrow) Arrow {
+	return annotation{arrow: f}.eval
+}
+
+// annotation is the arrow built by Annotated, probe recognises it by code
+// of the method value.
+type annotation struct {
+	arrow Arrow
+}
+
+func (a annotation) eval(ctx *Context) error { return a.arrow(ctx) }
+
+var annotationCode = reflect.ValueOf(annotation{}.eval).Pointer()
+
+// Annotate records metadata of arrow if the context is used for
+// introspection, the arrow shall return immediately if it returns true.
+//
+//	func(ctx *http.Context) error {
+//		if ctx.Annotate(http.Step{Kind: "ø.URI", URI: uri}) {
+//			return nil
+//		}
+//		...
+//	}
+func (ctx *Context) Annotate(step Step) bool {
+	if ctx.probe == nil {
+		return false
+	}
+
+	ctx.probe.steps = append(ctx.probe.steps, step)
+	return true
+}
+
+// probe is the state of introspection
+type probe struct {
+	steps []Step
+}
+
+// eval the arrow in the introspection mode, the arrow is evaluated only
+// if it annotates itself, otherwise it is recorded as an opaque step.
+func (p *probe) eval(ctx *Context, f Arrow) {
+	if reflect.ValueOf(f).Pointer() == annotationCode {
+		f(ctx)
+		return
+	}
+
+	p.steps = append(p.steps, Step{Kind: stepKind(f)})
+}
+
+// stepKind is the name of function that constructs the arrow
+func stepKind(f Arrow) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if fn == nil {
+		return "arrow"
+	}
+
+	name := fn.Name()
+	if at := strings.LastIndexByte(name, '/'); at != -1 {
+		name = name[at+1:]
+	}
+	for {
+		at := strings.LastIndex(name, ".func")
+		if at == -1 {
+			break
+		}
+		name = name[:at]
+	}
+	name = strings.ReplaceAll(name, "[...]", "")
+	return strings.TrimSuffix(name, "-fm")
+}
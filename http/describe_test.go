@@ -0,0 +1,106 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"reflect"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestDescribe(t *testing.T) {
+	type Site struct {
+		Site string `json:"site"`
+	}
+
+	var site Site
+	var id µ.Promise[string]
+
+	steps := µ.Describe(
+		µ.Join(
+			µ.POST(
+				ø.URI("https://example.com/sites"),
+				ø.ContentType.JSON,
+				ø.Send(Site{Site: "example.com"}),
+				ƒ.Status.Created,
+				ƒ.Body(&site),
+				ƒ.ContentType.JSON,
+			),
+			µ.GET(
+				ø.URI("https://example.com/sites/%s", &id),
+				ƒ.Code(µ.StatusOK, µ.StatusNotFound),
+			),
+		),
+	)
+
+	it.Then(t).Should(
+		it.Equal(len(steps), 10),
+		it.Equal(steps[0].Kind, "http.POST"),
+		it.Equal(steps[0].Method, "POST"),
+		it.Equal(steps[1].Kind, "send.URI"),
+		it.Equal(steps[1].URI, "https://example.com/sites"),
+		it.Equal(steps[2].Kind, "send.HeaderEnumContent.JSON"),
+		it.Equal(steps[3].Kind, "send.Send"),
+		it.Equal(steps[4].Kind, "recv.Status"),
+		it.Seq(steps[4].Status).Equal("201"),
+		it.Equal(steps[5].Kind, "recv.Body"),
+		it.Equal(steps[7].Kind, "http.GET"),
+		it.Equal(steps[8].URI, "https://example.com/sites/%s"),
+		it.Seq(steps[9].Status).Equal("200", "404"),
+	)
+}
+
+func TestDescribeOpaque(t *testing.T) {
+	evaluated := false
+	opaque := func(ctx *µ.Context) error {
+		evaluated = true
+		return nil
+	}
+
+	custom := µ.Annotated(func(ctx *µ.Context) error {
+		if ctx.Annotate(µ.Step{Kind: "custom"}) {
+			return nil
+		}
+		evaluated = true
+		return nil
+	})
+
+	steps := µ.Describe(
+		µ.GET(
+			ø.URI("https://example.com"),
+			opaque,
+			custom,
+			ƒ.Status.To(new(int)),
+		),
+	)
+
+	it.Then(t).Should(
+		it.Equal(len(steps), 5),
+		it.Equal(steps[3].Kind, "custom"),
+		it.Equal(steps[4].Kind, "recv.StatusCode.To"),
+		it.Equal(evaluated, false),
+	)
+}
+
+func TestDescribeStatus(t *testing.T) {
+	status := reflect.ValueOf(ƒ.Status)
+	for i := 0; i < status.NumField(); i++ {
+		arrow := status.Field(i).Interface().(µ.Arrow)
+		steps := µ.Describe(arrow)
+
+		it.Then(t).Should(
+			it.Equal(len(steps), 1),
+			it.Equal(steps[0].Kind, "recv.Status"),
+		)
+	}
+}
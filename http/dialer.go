@@ -0,0 +1,64 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"context"
+	"net"
+)
+
+//
+// The file implements custom dialers of the protocol stack
+//
+
+// Dialer establishes network connections for the protocol stack,
+// net.Dialer is the default implementation.
+type Dialer interface {
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+func withDialer(cat *Protocol, dialer Dialer) error {
+	cat.unixSocket = ""
+	return withDialContext(cat, dialer.DialContext)
+}
+
+func withUnixSocket(cat *Protocol, path string) error {
+	// Note: the dialer ignores address, the host of URI is not resolved
+	cat.unixSocket = path
+	dialer := &net.Dialer{}
+	return withDialContext(cat,
+		func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", path)
+		},
+	)
+}
+
+func withDialContext(cat *Protocol, dial dialContext) error {
+	cli, err := clientOf(cat)
+	if err != nil {
+		return err
+	}
+
+	// Note: the dialer is composed with DNS cache and statistics regardless
+	//       of the options order.
+	if cat.dns != nil && cat.unixSocket == "" {
+		dial = cat.dns.dialContext(dial)
+	}
+	if cat.stats != nil {
+		dial = cat.stats.dialContext(dial)
+	}
+
+	t, err := transportOf(cli)
+	if err != nil {
+		return err
+	}
+
+	t.DialContext = dial
+	return nil
+}
@@ -0,0 +1,99 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestUnixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "gurl.sock")
+	ln, err := net.Listen("unix", sock)
+	it.Then(t).Should(it.Nil(err))
+
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"path": "` + r.URL.Path + `"}`))
+		}),
+	}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	var path struct {
+		Path string `json:"path"`
+	}
+
+	err = µ.New(µ.WithUnixSocket(sock)).IO(context.Background(),
+		µ.GET(
+			ø.URI("http://unix/containers/json"),
+			ƒ.Status.OK,
+			ƒ.Body(&path),
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(path.Path, "/containers/json"),
+	)
+
+	for _, options := range [][]µ.Option{
+		{µ.WithUnixSocket(sock), µ.WithDNSCache(time.Minute), µ.WithStats()},
+		{µ.WithDNSCache(time.Minute), µ.WithUnixSocket(sock), µ.WithStats()},
+	} {
+		cat := µ.New(options...).(*µ.Protocol)
+		err = cat.IO(context.Background(),
+			µ.GET(
+				ø.URI("http://docker/containers/json"),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cat.Stats().DNSCacheMisses, 0),
+			it.Equal(cat.Stats().IdleConns, 1),
+		)
+	}
+}
+
+type dialer struct {
+	net.Dialer
+	count int
+}
+
+func (d *dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	d.count++
+	return d.Dialer.DialContext(ctx, network, addr)
+}
+
+func TestDialer(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	d := &dialer{}
+	err := µ.New(µ.WithDialer(d)).IO(context.Background(),
+		µ.GET(
+			ø.URI("%s/ok", ø.Authority(ts.URL)),
+			ƒ.Status.OK,
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(d.count, 1),
+	)
+}
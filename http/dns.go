@@ -0,0 +1,153 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//
+// The file implements in-process DNS cache for the protocol stack
+//
+
+// DNSCache caches results of DNS lookups for configured time-to-live.
+// The "not found" answers are cached as well (negative caching).
+type DNSCache struct {
+	sync.Mutex
+	ttl      time.Duration
+	clock    Clock
+	entries  map[string]dnsEntry
+	hits     atomic.Uint64
+	misses   atomic.Uint64
+	resolver interface {
+		LookupHost(ctx context.Context, host string) ([]string, error)
+	}
+}
+
+type dnsEntry struct {
+	addrs   []string
+	err     error
+	expires time.Time
+}
+
+// Creates new instance of DNS cache
+func NewDNSCache(ttl time.Duration) *DNSCache {
+	return &DNSCache{
+		ttl:      ttl,
+		clock:    SystemClock,
+		entries:  map[string]dnsEntry{},
+		resolver: net.DefaultResolver,
+	}
+}
+
+// Flush all cached entries
+func (c *DNSCache) Flush() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.entries = map[string]dnsEntry{}
+}
+
+// LookupHost looks up the given host using cache
+func (c *DNSCache) LookupHost(ctx context.Context, host string) ([]string, error) {
+	c.Lock()
+	entry, has := c.entries[host]
+	c.Unlock()
+
+	if has && c.clock.Now().Before(entry.expires) {
+		c.hits.Add(1)
+		return entry.addrs, entry.err
+	}
+	c.misses.Add(1)
+
+	addrs, err := c.resolver.LookupHost(ctx, host)
+
+	var dnsErr *net.DNSError
+	if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+		// Note: only authoritative "not found" answers are cached,
+		//       transient failures are always re-tried.
+		return nil, err
+	}
+
+	c.Lock()
+	c.entries[host] = dnsEntry{
+		addrs:   addrs,
+		err:     err,
+		expires: c.clock.Now().Add(c.ttl),
+	}
+	c.Unlock()
+
+	return addrs, err
+}
+
+type dialContext = func(ctx context.Context, network, addr string) (net.Conn, error)
+
+func (c *DNSCache) dialContext(dial dialContext) dialContext {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		if net.ParseIP(host) != nil {
+			return dial(ctx, network, addr)
+		}
+
+		addrs, err := c.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ip := range addrs {
+			var conn net.Conn
+			conn, err = dial(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+		}
+
+		if err == nil {
+			err = &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+
+		return nil, err
+	}
+}
+
+func withDNSCache(cat *Protocol, ttl time.Duration) error {
+	return withDNS(cat, NewDNSCache(ttl))
+}
+
+func withDNS(cat *Protocol, cache *DNSCache) error {
+	cli, err := clientOf(cat)
+	if err != nil {
+		return err
+	}
+
+	t, err := transportOf(cli)
+	if err != nil {
+		return err
+	}
+
+	if cat.unixSocket == "" {
+		dial := t.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+		t.DialContext = cache.dialContext(dial)
+	}
+
+	cat.dns = cache
+	return nil
+}
@@ -0,0 +1,98 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestDNSCache(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	url := strings.Replace(ts.URL, "127.0.0.1", "localhost", 1)
+
+	t.Run("WithDNSCache", func(t *testing.T) {
+		cat := µ.New(µ.WithDNSCache(time.Minute))
+		err := cat.IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/ok", ø.Authority(url)),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("WithDNS", func(t *testing.T) {
+		dns := µ.NewDNSCache(time.Minute)
+		cat := µ.New(µ.WithDNS(dns))
+
+		for i := 0; i < 2; i++ {
+			err := cat.IO(context.Background(),
+				µ.GET(
+					ø.URI("%s/ok", ø.Authority(url)),
+					ƒ.Status.OK,
+				),
+			)
+			it.Then(t).Should(it.Nil(err))
+			dns.Flush()
+		}
+	})
+
+	t.Run("WithDialer", func(t *testing.T) {
+		for _, dialFirst := range []bool{true, false} {
+			d := &dialer{}
+			order := []µ.Option{µ.WithDialer(d), µ.WithDNSCache(time.Minute)}
+			if !dialFirst {
+				order[0], order[1] = order[1], order[0]
+			}
+
+			cat := µ.New(append(order, µ.WithStats())...).(*µ.Protocol)
+			err := cat.IO(context.Background(),
+				µ.GET(
+					ø.URI("%s/ok", ø.Authority(url)),
+					ƒ.Status.OK,
+				),
+			)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(d.count, 1),
+				it.Equal(cat.Stats().DNSCacheMisses, 1),
+				it.Equal(cat.Stats().IdleConns, 1),
+			)
+		}
+	})
+
+	t.Run("UnsupportedSocket", func(t *testing.T) {
+		socket := µ.SocketFunc(func(req *http.Request) (*http.Response, error) { return nil, nil })
+		_, err := µ.NewStack(µ.WithClient(socket), µ.WithDNSCache(time.Minute))
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+
+	t.Run("LookupHost", func(t *testing.T) {
+		dns := µ.NewDNSCache(time.Minute)
+		a, err := dns.LookupHost(context.Background(), "localhost")
+		it.Then(t).Should(it.Nil(err))
+
+		b, err := dns.LookupHost(context.Background(), "localhost")
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(b).Equal(a...),
+		)
+	})
+}
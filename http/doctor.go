@@ -0,0 +1,447 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+//
+// The file implements diagnostic of connectivity and configuration, the
+// target is checked step by step: DNS, TCP, TLS, HTTP and authorization.
+//
+
+// Outcomes of the diagnostic check
+const (
+	CheckPass = "pass"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+	CheckSkip = "skip"
+)
+
+// Diagnostic checks, in the order of evaluation
+const (
+	CheckDNS  = "dns"
+	CheckTCP  = "tcp"
+	CheckTLS  = "tls"
+	CheckHTTP = "http"
+	CheckAuth = "auth"
+)
+
+// DoctorCheck is the outcome of single diagnostic step
+type DoctorCheck struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Duration time.Duration `json:"duration"`
+	Detail   string        `json:"detail,omitempty"`
+	Reason   string        `json:"reason,omitempty"`
+	Hint     string        `json:"hint,omitempty"`
+}
+
+// DoctorReport is the outcome of connectivity diagnostic. Checks following
+// the failed one are skipped.
+type DoctorReport struct {
+	URL     string        `json:"url"`
+	Healthy bool          `json:"healthy"`
+	Checks  []DoctorCheck `json:"checks"`
+}
+
+func (r *DoctorReport) String() string {
+	var sb strings.Builder
+	sb.WriteString(r.URL)
+	sb.WriteString("\n")
+	for _, c := range r.Checks {
+		fmt.Fprintf(&sb, "  [%s] %-4s %8v", c.Status, c.Name, c.Duration.Round(time.Microsecond))
+		if c.Detail != "" {
+			fmt.Fprintf(&sb, " %s", c.Detail)
+		}
+		if c.Reason != "" {
+			fmt.Fprintf(&sb, "\n         reason: %s", c.Reason)
+		}
+		if c.Hint != "" {
+			fmt.Fprintf(&sb, "\n         hint: %s", c.Hint)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Doctor diagnoses connectivity to the url using configuration of the stack
+// (DNS cache, proxy, TLS, headers and middleware). It is the self-service
+// troubleshooting of requests that "just fail", each check reports timing
+// and explanation of the failure. The error is returned only if url is
+// malformed, failures of checks are reported.
+//
+//	report, err := http.Doctor(context.Background(), stack, "https://example.com/health")
+//	fmt.Println(report)
+func Doctor(ctx context.Context, stack Stack, uri string) (*DoctorReport, error) {
+	target, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q, http or https is required", target.Scheme)
+	}
+
+	cat, _ := stack.(*Protocol)
+	doc := &doctor{ctx: ctx, stack: stack, cat: cat, target: target}
+	report := &DoctorReport{URL: target.String()}
+
+	steps := []struct {
+		name  string
+		check func(*DoctorCheck)
+	}{
+		{CheckDNS, doc.dns},
+		{CheckTCP, doc.tcp},
+		{CheckTLS, doc.tls},
+		{CheckHTTP, doc.http},
+		{CheckAuth, doc.auth},
+	}
+
+	failed := false
+	for _, step := range steps {
+		check := DoctorCheck{Name: step.name, Status: CheckSkip}
+		if !failed {
+			step.check(&check)
+			failed = check.Status == CheckFail
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	if doc.conn != nil {
+		doc.conn.Close()
+	}
+
+	report.Healthy = !failed
+	return report, nil
+}
+
+type doctor struct {
+	ctx    context.Context
+	stack  Stack
+	cat    *Protocol
+	target *url.URL
+	addrs  []string
+	proxy  *url.URL
+	conn   net.Conn
+	status int
+	header http.Header
+}
+
+func (doc *doctor) port() string {
+	if port := doc.target.Port(); port != "" {
+		return port
+	}
+	if doc.target.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+func (doc *doctor) clock() Clock {
+	return clockOf(doc.cat)
+}
+
+func (doc *doctor) transport() *http.Transport {
+	if doc.cat == nil {
+		return nil
+	}
+	if cli, ok := doc.cat.Socket.(*http.Client); ok {
+		if t, err := transportOf(cli); err == nil {
+			return t
+		}
+	}
+	return nil
+}
+
+func (doc *doctor) dns(check *DoctorCheck) {
+	host := doc.target.Hostname()
+	if doc.cat != nil && doc.cat.unixSocket != "" {
+		doc.addrs = []string{host}
+		check.Status = CheckSkip
+		check.Detail = "unix socket " + doc.cat.unixSocket
+		return
+	}
+
+	if t := doc.transport(); t != nil && t.Proxy != nil {
+		req := &http.Request{Method: http.MethodGet, URL: doc.target, Header: http.Header{}}
+		if proxy, err := t.Proxy(req); err == nil && proxy != nil {
+			doc.proxy = proxy
+			host = proxy.Hostname()
+			check.Detail = fmt.Sprintf("via proxy %s;", proxy.Host)
+		}
+	}
+
+	if net.ParseIP(host) != nil {
+		doc.addrs = []string{host}
+		check.Status = CheckPass
+		check.Detail = strings.TrimSpace(check.Detail + " ip address " + host)
+		return
+	}
+
+	var resolver interface {
+		LookupHost(ctx context.Context, host string) ([]string, error)
+	} = net.DefaultResolver
+	if doc.cat != nil && doc.cat.dns != nil {
+		resolver = doc.cat.dns
+	}
+
+	t := doc.clock().Now()
+	addrs, err := resolver.LookupHost(doc.ctx, host)
+	check.Duration = doc.clock().Now().Sub(t)
+	if err != nil {
+		check.Status = CheckFail
+		check.Reason = err.Error()
+		check.Hint = hintDNS(err)
+		return
+	}
+
+	doc.addrs = addrs
+	check.Status = CheckPass
+	check.Detail = strings.TrimSpace(fmt.Sprintf("%s %s -> %s", check.Detail, host, strings.Join(addrs, ", ")))
+}
+
+func (doc *doctor) tcp(check *DoctorCheck) {
+	port := doc.port()
+	if doc.proxy != nil {
+		port = doc.proxy.Port()
+		if port == "" {
+			port = "80"
+			if doc.proxy.Scheme == "https" {
+				port = "443"
+			}
+		}
+	}
+
+	// Note: the connection is established by the dialer of stack transport
+	//       (e.g. WithDialer, WithUnixSocket), so that the check follows
+	//       the path of the stack.
+	dial := (&net.Dialer{}).DialContext
+	if t := doc.transport(); t != nil && t.DialContext != nil {
+		dial = t.DialContext
+	}
+
+	ctx, cancel := context.WithTimeout(doc.ctx, 10*time.Second)
+	defer cancel()
+
+	t := doc.clock().Now()
+	for _, ip := range doc.addrs {
+		addr := net.JoinHostPort(ip, port)
+		conn, err := dial(ctx, "tcp", addr)
+		if err != nil {
+			check.Reason = err.Error()
+			check.Hint = hintTCP(err)
+			continue
+		}
+
+		doc.conn = conn
+		check.Duration = doc.clock().Now().Sub(t)
+		check.Status = CheckPass
+		check.Detail = fmt.Sprintf("connected %s -> %s", conn.LocalAddr(), addr)
+		check.Reason, check.Hint = "", ""
+		return
+	}
+
+	check.Duration = doc.clock().Now().Sub(t)
+	check.Status = CheckFail
+}
+
+func (doc *doctor) tls(check *DoctorCheck) {
+	switch {
+	case doc.target.Scheme != "https":
+		check.Status = CheckSkip
+		check.Detail = "plain http"
+		return
+	case doc.proxy != nil:
+		check.Status = CheckSkip
+		check.Detail = "tunneled via proxy, see http check"
+		return
+	}
+
+	conf := &tls.Config{}
+	if t := doc.transport(); t != nil && t.TLSClientConfig != nil {
+		conf = t.TLSClientConfig.Clone()
+	}
+	if conf.ServerName == "" {
+		conf.ServerName = doc.target.Hostname()
+	}
+
+	conn := tls.Client(doc.conn, conf)
+
+	t := doc.clock().Now()
+	err := conn.HandshakeContext(doc.ctx)
+	check.Duration = doc.clock().Now().Sub(t)
+	if err != nil {
+		check.Status = CheckFail
+		check.Reason = err.Error()
+		check.Hint = hintTLS(err)
+		return
+	}
+
+	state := conn.ConnectionState()
+	check.Status = CheckPass
+	check.Detail = fmt.Sprintf("%s %s", tls.VersionName(state.Version), tls.CipherSuiteName(state.CipherSuite))
+
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		check.Detail += fmt.Sprintf(", certificate %q expires %s", cert.Subject.CommonName, cert.NotAfter.Format(time.DateOnly))
+		if cert.NotAfter.Sub(doc.clock().Now()) < 14*24*time.Hour {
+			check.Status = CheckWarn
+			check.Hint = "certificate expires soon, renew it"
+		}
+	}
+}
+
+func (doc *doctor) http(check *DoctorCheck) {
+	c := doc.stack.WithContext(doc.ctx)
+	req, err := NewRequest(http.MethodGet, doc.target.String())
+	if err != nil {
+		check.Status = CheckFail
+		check.Reason = err.Error()
+		return
+	}
+	// Note: the check requires network I/O, the response is revalidated
+	//       if it is cached by the stack.
+	req.Header.Set("Cache-Control", "no-cache")
+	c.Request = req
+
+	t := doc.clock().Now()
+	err = c.Unsafe()
+	check.Duration = doc.clock().Now().Sub(t)
+	if err != nil {
+		check.Status = CheckFail
+		check.Reason = err.Error()
+		check.Hint = hintHTTP(err)
+		return
+	}
+
+	doc.status = c.Response.StatusCode
+	doc.header = c.Response.Header
+	c.discardBody()
+
+	check.Detail = fmt.Sprintf("GET %s %d %s", doc.target.RequestURI(), doc.status, http.StatusText(doc.status))
+	switch {
+	case doc.status == http.StatusProxyAuthRequired:
+		check.Status = CheckFail
+		check.Hint = "proxy requires authentication, embed credentials into proxy url"
+	case doc.status == http.StatusTooManyRequests:
+		check.Status = CheckWarn
+		check.Hint = "request is throttled, consider http.WithRateLimit"
+	case doc.status >= 500:
+		check.Status = CheckFail
+		check.Hint = "server failed to process the request, it is not a client issue"
+	case doc.status == http.StatusNotFound:
+		check.Status = CheckWarn
+		check.Hint = "server is reachable but path is not found, check the path and base url"
+	default:
+		check.Status = CheckPass
+	}
+}
+
+func (doc *doctor) auth(check *DoctorCheck) {
+	check.Status = CheckPass
+	switch doc.status {
+	case http.StatusUnauthorized:
+		check.Status = CheckFail
+		check.Reason = StatusCode(doc.status).Error()
+		check.Hint = "credentials are missing or rejected, check Authorization header or API key"
+		if challenge := doc.header.Get("WWW-Authenticate"); challenge != "" {
+			check.Detail = "WWW-Authenticate: " + challenge
+		}
+	case http.StatusForbidden:
+		check.Status = CheckFail
+		check.Reason = StatusCode(doc.status).Error()
+		check.Hint = "credentials are accepted but lack permissions to the resource"
+	}
+}
+
+func hintDNS(err error) string {
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		return "host name is not known, check spelling of the host or VPN and private DNS zones"
+	case errors.As(err, &dnsErr) && dnsErr.IsTimeout:
+		return "DNS server does not respond, check network connectivity and resolver configuration"
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return "lookup is cancelled by the context"
+	}
+	return "lookup is failed, check resolver configuration"
+}
+
+func hintTCP(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "nothing listens on the port, check the port and the service is running"
+	case errors.Is(err, syscall.ENETUNREACH), errors.Is(err, syscall.EHOSTUNREACH):
+		return "network is unreachable, check routing, VPN or IPv6 availability"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "connection is timed out, firewall or security group drops packets"
+	}
+	return "connection is failed"
+}
+
+func hintTLS(err error) string {
+	var (
+		authErr  x509.UnknownAuthorityError
+		hostErr  x509.HostnameError
+		certErr  x509.CertificateInvalidError
+		recErr   tls.RecordHeaderError
+		alertErr tls.AlertError
+		verify   *tls.CertificateVerificationError
+	)
+
+	if errors.As(err, &verify) && verify.Err != nil {
+		err = verify.Err
+	}
+
+	switch {
+	case errors.As(err, &authErr):
+		return "certificate is signed by unknown authority, use http.WithRootCAs with private CA"
+	case errors.As(err, &hostErr):
+		return "certificate is not valid for the host, check the host name or server configuration"
+	case errors.As(err, &certErr) && certErr.Reason == x509.Expired:
+		return "certificate is expired or system clock is wrong"
+	case errors.As(err, &recErr):
+		return "server does not speak TLS, try http:// scheme or check the port"
+	case errors.As(err, &alertErr):
+		return "server rejected the handshake, it might require client certificate (http.WithClientCertificate)"
+	}
+	return "handshake is failed, check TLS configuration"
+}
+
+func hintHTTP(err error) string {
+	var (
+		proxyErr *ProxyError
+		limitErr *HeaderLimitError
+		openErr  *CircuitOpen
+	)
+
+	switch {
+	case errors.As(err, &proxyErr):
+		return "proxy rejected the tunnel, check proxy url and credentials"
+	case errors.As(err, &limitErr):
+		return "response headers exceed the configured limits"
+	case errors.As(err, &openErr):
+		return "circuit breaker is open due to recent failures"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "response is timed out, service is slow or the timeout is too short"
+	}
+	return "request is failed"
+}
@@ -0,0 +1,152 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	"github.com/fogfish/it/v2"
+)
+
+func TestDoctor(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/secret":
+			if r.Header.Get("Authorization") == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="example"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	tls := httptest.NewTLSServer(handler)
+	defer tls.Close()
+
+	status := func(report *µ.DoctorReport) []string {
+		seq := make([]string, 0, len(report.Checks))
+		for _, c := range report.Checks {
+			seq = append(seq, c.Name+":"+c.Status)
+		}
+		return seq
+	}
+
+	t.Run("Healthy", func(t *testing.T) {
+		report, err := µ.Doctor(context.Background(), µ.New(), ts.URL+"/health")
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(report.Healthy),
+			it.Seq(status(report)).Equal("dns:pass", "tcp:pass", "tls:skip", "http:pass", "auth:pass"),
+		)
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		stack := µ.New(
+			µ.WithTLSConfig(tls.Client().Transport.(*http.Transport).TLSClientConfig),
+		)
+		report, err := µ.Doctor(context.Background(), stack, tls.URL)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(report.Healthy),
+			it.Seq(status(report)).Equal("dns:pass", "tcp:pass", "tls:pass", "http:pass", "auth:pass"),
+			it.True(strings.HasPrefix(report.Checks[2].Detail, "TLS 1.3")),
+		)
+	})
+
+	t.Run("UnixSocket", func(t *testing.T) {
+		sock := filepath.Join(t.TempDir(), "doctor.sock")
+		ln, err := net.Listen("unix", sock)
+		it.Then(t).Should(it.Nil(err))
+
+		srv := &http.Server{Handler: handler}
+		go srv.Serve(ln)
+		defer srv.Close()
+
+		stack := µ.New(µ.WithUnixSocket(sock), µ.WithDNSCache(time.Minute))
+		report, err := µ.Doctor(context.Background(), stack, "http://docker/health")
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(report.Healthy),
+			it.Seq(status(report)).Equal("dns:skip", "tcp:pass", "tls:skip", "http:pass", "auth:pass"),
+		)
+	})
+
+	t.Run("UnknownAuthority", func(t *testing.T) {
+		report, err := µ.Doctor(context.Background(), µ.New(), tls.URL)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(!report.Healthy),
+			it.Seq(status(report)).Equal("dns:pass", "tcp:pass", "tls:fail", "http:skip", "auth:skip"),
+			it.True(strings.Contains(report.Checks[2].Hint, "http.WithRootCAs")),
+		)
+	})
+
+	t.Run("Refused", func(t *testing.T) {
+		down := httptest.NewServer(handler)
+		down.Close()
+
+		report, err := µ.Doctor(context.Background(), µ.New(), down.URL)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(!report.Healthy),
+			it.Seq(status(report)).Equal("dns:pass", "tcp:fail", "tls:skip", "http:skip", "auth:skip"),
+			it.True(strings.Contains(report.Checks[1].Hint, "nothing listens")),
+		)
+	})
+
+	t.Run("Server", func(t *testing.T) {
+		report, err := µ.Doctor(context.Background(), µ.New(), ts.URL+"/fail")
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(!report.Healthy),
+			it.Seq(status(report)).Equal("dns:pass", "tcp:pass", "tls:skip", "http:fail", "auth:skip"),
+		)
+	})
+
+	t.Run("Auth", func(t *testing.T) {
+		report, err := µ.Doctor(context.Background(), µ.New(), ts.URL+"/secret")
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(!report.Healthy),
+			it.Seq(status(report)).Equal("dns:pass", "tcp:pass", "tls:skip", "http:pass", "auth:fail"),
+			it.Equal(report.Checks[4].Detail, `WWW-Authenticate: Bearer realm="example"`),
+		)
+
+		report, err = µ.Doctor(context.Background(),
+			µ.New(µ.WithDefaultHeader("Authorization", "Bearer token")),
+			ts.URL+"/secret",
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(report.Healthy),
+		)
+	})
+
+	t.Run("Scheme", func(t *testing.T) {
+		_, err := µ.Doctor(context.Background(), µ.New(), "ftp://example.com")
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+	})
+}
@@ -0,0 +1,72 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+//
+// The file implements transparent decoding of compressed response
+//
+
+// decompress wraps response body with decoder defined by Content-Encoding.
+// Content-Encoding header remains intact, so that it can be matched.
+func decompress(in *http.Response) error {
+	encoding := strings.ToLower(strings.TrimSpace(in.Header.Get("Content-Encoding")))
+	if encoding == "" || encoding == "identity" || in.Uncompressed {
+		return nil
+	}
+
+	var (
+		r   io.ReadCloser
+		err error
+	)
+
+	switch encoding {
+	case "gzip", "x-gzip":
+		r, err = gzip.NewReader(in.Body)
+	case "deflate":
+		// Note: "deflate" is zlib data format (RFC 9110, Section 8.4.1.2)
+		r, err = zlib.NewReader(in.Body)
+	case "br":
+		r = io.NopCloser(brotli.NewReader(in.Body))
+	default:
+		return nil
+	}
+
+	if err != nil {
+		// Note: empty body of HEAD, 204 or 304 responses
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+
+	in.Body = &decompressor{ReadCloser: r, body: in.Body}
+	in.ContentLength = -1
+	in.Uncompressed = true
+	return nil
+}
+
+type decompressor struct {
+	io.ReadCloser
+	body io.ReadCloser
+}
+
+func (d *decompressor) Close() error {
+	d.ReadCloser.Close()
+	return d.body.Close()
+}
@@ -0,0 +1,114 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func compressed() *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				buf bytes.Buffer
+				enc io.WriteCloser
+			)
+
+			encoding := r.Header.Get("Accept-Encoding")
+			switch encoding {
+			case "gzip":
+				enc = gzip.NewWriter(&buf)
+			case "deflate":
+				enc = zlib.NewWriter(&buf)
+			case "br":
+				enc = brotli.NewWriter(&buf)
+			}
+			enc.Write([]byte(`{"site": "example.com"}`))
+			enc.Close()
+
+			w.Header().Add("Content-Type", "application/json")
+			w.Header().Add("Content-Encoding", encoding)
+			w.Write(buf.Bytes())
+		}),
+	)
+}
+
+func TestCompression(t *testing.T) {
+	ts := compressed()
+	defer ts.Close()
+
+	type Site struct {
+		Site string `json:"site"`
+	}
+
+	for _, encoding := range []µ.Arrow{
+		ø.AcceptEncodings.Gzip,
+		ø.AcceptEncodings.Deflate,
+		ø.AcceptEncodings.Brotli,
+	} {
+		var site Site
+		err := µ.New(µ.WithCompression()).IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				encoding,
+				ƒ.Status.OK,
+				ƒ.ContentEncoding.Any,
+				ƒ.Body(&site),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(site.Site, "example.com"),
+		)
+	}
+
+	t.Run("Match", func(t *testing.T) {
+		err := µ.New(µ.WithCompression()).IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ø.AcceptEncodings.Gzip,
+				ƒ.Status.OK,
+				ƒ.Match(`{"site": "example.com"}`),
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("NoCompression", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ø.AcceptEncodings.Gzip,
+				ƒ.Status.OK,
+				ƒ.Bytes(buf),
+			),
+		)
+
+		r, _ := gzip.NewReader(buf)
+		raw, _ := io.ReadAll(r)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(string(raw), `{"site": "example.com"}`),
+		)
+	})
+}
@@ -0,0 +1,35 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+//
+// The file implements mapping of library errors to caller-domain errors
+//
+
+// ErrorMapper converts errors of the stack (status mismatch, gurl.NoMatch,
+// transport errors) into caller-domain errors. The context of failed
+// evaluation is given to the mapper, the response is still available unless
+// it has been consumed by arrows.
+type ErrorMapper = func(*Context, error) error
+
+func withErrorMapper(cat *Protocol, f ErrorMapper) error {
+	cat.errmapper = append(cat.errmapper, f)
+	return nil
+}
+
+func (stack *Protocol) mapError(ctx *Context, err error) error {
+	for _, f := range stack.errmapper {
+		err = f(ctx, err)
+		if err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
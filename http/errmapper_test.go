@@ -0,0 +1,95 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestErrorMapper(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	errDomain := errors.New("domain")
+
+	t.Run("Map", func(t *testing.T) {
+		var status int
+		stack := µ.New(
+			µ.WithErrorMapper(func(ctx *µ.Context, err error) error {
+				if ctx.Response != nil {
+					status = ctx.Response.StatusCode
+				}
+				if errors.As(err, new(*gurl.NoMatch)) {
+					return errDomain
+				}
+				return err
+			}),
+		)
+
+		err := stack.IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.Status.NotFound,
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, errDomain)),
+			it.Equal(status, 200),
+		)
+	})
+
+	t.Run("Chain", func(t *testing.T) {
+		var seq []string
+		stack := µ.New(
+			µ.WithErrorMapper(func(ctx *µ.Context, err error) error {
+				seq = append(seq, "a")
+				return errDomain
+			}),
+			µ.WithErrorMapper(func(ctx *µ.Context, err error) error {
+				seq = append(seq, "b")
+				return nil
+			}),
+		)
+
+		err := stack.IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.Status.NotFound,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal("a", "b"),
+		)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		stack := µ.New(
+			µ.WithErrorMapper(func(ctx *µ.Context, err error) error {
+				return errDomain
+			}),
+		)
+
+		err := stack.IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+	})
+}
@@ -0,0 +1,409 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fogfish/gurl/v2"
+)
+
+//
+// The file implements recording of requests and responses in HTTP Archive
+// (HAR 1.2) format, sessions are inspectable with browser devtools.
+//
+
+// HAR records requests and responses of the stack in HTTP Archive format.
+// Bodies are recorded up to the limit of bytes, zero limit disables it.
+// Values of credentials headers and cookies are redacted, set Redact to
+// nil to record them as is.
+//
+//	har := http.NewHAR(64 * 1024)
+//	stack := http.New(http.WithHAR(har))
+//	...
+//	har.WriteTo(file)
+type HAR struct {
+	lock   sync.Mutex
+	limit  int
+	Redact []string `json:"-"`
+	Log    HARLog   `json:"log"`
+}
+
+// Placeholder of redacted values
+const harRedacted = "[REDACTED]"
+
+// HARLog is the root of HTTP Archive
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+// HARCreator is the application recording HTTP Archive
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry is the recorded request and response
+type HAREntry struct {
+	StartedDateTime time.Time         `json:"startedDateTime"`
+	Time            float64           `json:"time"`
+	Request         HARRequest        `json:"request"`
+	Response        HARResponse       `json:"response"`
+	Cache           struct{}          `json:"cache"`
+	Timings         HARTimings        `json:"timings"`
+	Tags            map[string]string `json:"_tags,omitempty"`
+	Comment         string            `json:"comment,omitempty"`
+}
+
+// HARRequest is the recorded request
+type HARRequest struct {
+	Method      string       `json:"method"`
+	URL         string       `json:"url"`
+	HTTPVersion string       `json:"httpVersion"`
+	Cookies     []HARCookie  `json:"cookies"`
+	Headers     []HARPair    `json:"headers"`
+	QueryString []HARPair    `json:"queryString"`
+	PostData    *HARPostData `json:"postData,omitempty"`
+	HeadersSize int          `json:"headersSize"`
+	BodySize    int64        `json:"bodySize"`
+}
+
+// HARResponse is the recorded response
+type HARResponse struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []HARCookie `json:"cookies"`
+	Headers     []HARPair   `json:"headers"`
+	Content     HARContent  `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+// HARPair is the name, value pair of headers and query string
+type HARPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARCookie is the recorded cookie
+type HARCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData is the recorded payload of request
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent is the recorded payload of response
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings are durations of request phases in milliseconds,
+// -1 is used if phase is not applicable.
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// Creates new instance of HAR recorder
+func NewHAR(limit int) *HAR {
+	return &HAR{
+		limit:  limit,
+		Redact: []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"},
+		Log: HARLog{
+			Version: "1.2",
+			Creator: HARCreator{Name: "gurl", Version: gurl.Version},
+			Entries: []HAREntry{},
+		},
+	}
+}
+
+// Entries returns copy of recorded entries
+func (h *HAR) Entries() []HAREntry {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return append([]HAREntry{}, h.Log.Entries...)
+}
+
+// WriteTo outputs HTTP Archive
+func (h *HAR) WriteTo(w io.Writer) (int64, error) {
+	h.lock.Lock()
+	bytes, err := json.MarshalIndent(h, "", "  ")
+	h.lock.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(bytes)
+	return int64(n), err
+}
+
+// Wrap the socket, implements Middleware interface. Requests failed at
+// transport are recorded with the error as comment of the entry.
+func (h *HAR) Wrap(next Socket) Socket {
+	return h.wrap(next, SystemClock)
+}
+
+func (h *HAR) wrap(next Socket, clock Clock) Socket {
+	return SocketFunc(func(req *http.Request) (*http.Response, error) {
+		trace := &harTrace{clock: clock}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace()))
+
+		var err error
+		entry := HAREntry{StartedDateTime: clock.Now().UTC(), Tags: TagsOf(req.Context())}
+		if entry.Request, err = h.request(req); err != nil {
+			return nil, err
+		}
+
+		t := clock.Now()
+		in, err := next.Do(req)
+		if err == nil {
+			entry.Response, err = h.response(in)
+		}
+		end := clock.Now()
+		entry.Timings = trace.timings(end)
+		entry.Time = ms(end.Sub(t))
+
+		if err != nil {
+			entry.Response = HARResponse{
+				Cookies:     []HARCookie{},
+				Headers:     []HARPair{},
+				HeadersSize: -1,
+				BodySize:    -1,
+			}
+			entry.Comment = err.Error()
+		}
+
+		h.lock.Lock()
+		h.Log.Entries = append(h.Log.Entries, entry)
+		h.lock.Unlock()
+
+		if err != nil {
+			return nil, err
+		}
+		return in, nil
+	})
+}
+
+func (h *HAR) redacted(header string) bool {
+	for _, key := range h.Redact {
+		if http.CanonicalHeaderKey(key) == header {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *HAR) headers(header http.Header) []HARPair {
+	seq := []HARPair{}
+	for key, vals := range header {
+		for _, val := range vals {
+			if h.redacted(key) {
+				val = harRedacted
+			}
+			seq = append(seq, HARPair{Name: key, Value: val})
+		}
+	}
+	return seq
+}
+
+func (h *HAR) cookies(header string, cookies []*http.Cookie) []HARCookie {
+	seq := []HARCookie{}
+	for _, c := range cookies {
+		val := c.Value
+		if h.redacted(header) {
+			val = harRedacted
+		}
+		seq = append(seq, HARCookie{Name: c.Name, Value: val})
+	}
+	return seq
+}
+
+func (h *HAR) request(req *http.Request) (HARRequest, error) {
+	r := HARRequest{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		HTTPVersion: req.Proto,
+		Cookies:     h.cookies("Cookie", req.Cookies()),
+		Headers:     h.headers(req.Header),
+		QueryString: []HARPair{},
+		HeadersSize: -1,
+		BodySize:    req.ContentLength,
+	}
+
+	for key, vals := range req.URL.Query() {
+		for _, val := range vals {
+			r.QueryString = append(r.QueryString, HARPair{Name: key, Value: val})
+		}
+	}
+
+	if h.limit > 0 && req.Body != nil && req.Body != http.NoBody {
+		buf, body, err := peekBody(req.Body, h.limit)
+		if err != nil {
+			return r, err
+		}
+		req.Body = body
+		r.PostData = &HARPostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     string(buf),
+		}
+	}
+
+	return r, nil
+}
+
+// peekBody buffers prefix of the body up to the limit, the returned body
+// streams both prefix and remaining payload.
+func peekBody(body io.ReadCloser, limit int) ([]byte, io.ReadCloser, error) {
+	buf, err := io.ReadAll(io.LimitReader(body, int64(limit)))
+	if err != nil {
+		body.Close()
+		return nil, nil, err
+	}
+
+	return buf, struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), body), body}, nil
+}
+
+func (h *HAR) response(in *http.Response) (HARResponse, error) {
+	r := HARResponse{
+		Status:      in.StatusCode,
+		StatusText:  http.StatusText(in.StatusCode),
+		HTTPVersion: in.Proto,
+		Cookies:     h.cookies("Set-Cookie", in.Cookies()),
+		Headers:     h.headers(in.Header),
+		Content: HARContent{
+			Size:     in.ContentLength,
+			MimeType: in.Header.Get("Content-Type"),
+		},
+		RedirectURL: in.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    in.ContentLength,
+	}
+
+	if h.limit > 0 {
+		// Note: only prefix of the body is buffered, the remaining
+		//       payload is streamed to the consumer.
+		buf, body, err := peekBody(in.Body, h.limit)
+		if err != nil {
+			return r, err
+		}
+		in.Body = body
+
+		if utf8.Valid(buf) {
+			r.Content.Text = string(buf)
+		} else {
+			r.Content.Text = base64.StdEncoding.EncodeToString(buf)
+			r.Content.Encoding = "base64"
+		}
+	}
+
+	return r, nil
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// harTrace captures timings of request phases
+type harTrace struct {
+	sync.Mutex
+	clock                     Clock
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	gotConn, wroteRequest     time.Time
+	firstByte                 time.Time
+}
+
+func (t *harTrace) clientTrace() *httptrace.ClientTrace {
+	at := func(v *time.Time) {
+		t.Lock()
+		*v = t.clock.Now()
+		t.Unlock()
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { at(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { at(&t.dnsDone) },
+		ConnectStart:         func(string, string) { at(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { at(&t.connectDone) },
+		TLSHandshakeStart:    func() { at(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { at(&t.tlsDone) },
+		GotConn:              func(httptrace.GotConnInfo) { at(&t.gotConn) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { at(&t.wroteRequest) },
+		GotFirstResponseByte: func() { at(&t.firstByte) },
+	}
+}
+
+func (t *harTrace) timings(end time.Time) HARTimings {
+	t.Lock()
+	defer t.Unlock()
+
+	span := func(a, b time.Time) float64 {
+		if a.IsZero() || b.IsZero() {
+			return -1
+		}
+		return ms(b.Sub(a))
+	}
+
+	timings := HARTimings{
+		Blocked: -1,
+		DNS:     span(t.dnsStart, t.dnsDone),
+		Connect: span(t.connectStart, t.connectDone),
+		SSL:     span(t.tlsStart, t.tlsDone),
+		Send:    span(t.gotConn, t.wroteRequest),
+		Wait:    span(t.wroteRequest, t.firstByte),
+		Receive: span(t.firstByte, end),
+	}
+
+	// Note: send, wait and receive are required by the format
+	timings.Send = max(timings.Send, 0)
+	timings.Wait = max(timings.Wait, 0)
+	timings.Receive = max(timings.Receive, 0)
+
+	return timings
+}
+
+// Note: the middleware is built when the stack is chained, after all
+//
+//	options are applied, so that the clock of the stack is used.
+func withHAR(cat *Protocol, har *HAR) error {
+	return withMiddleware(cat, func(next Socket) Socket {
+		return har.wrap(next, clockOf(cat))
+	})
+}
@@ -0,0 +1,155 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestHAR(t *testing.T) {
+	ts := echo()
+	defer ts.Close()
+
+	type Site struct {
+		Site string `json:"site"`
+	}
+
+	har := µ.NewHAR(8)
+	stack := µ.New(µ.WithHAR(har))
+
+	var site Site
+	err := stack.IO(context.Background(),
+		µ.POST(
+			ø.URI("%s/echo", ø.Authority(ts.URL)),
+			ø.Param("q", "a"),
+			ø.ContentType.JSON,
+			ø.Send(Site{Site: "example.com"}),
+			ƒ.Status.OK,
+			ƒ.Body(&site),
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(site.Site, "example.com"),
+	)
+
+	seq := har.Entries()
+	it.Then(t).Should(
+		it.Equal(len(seq), 1),
+		it.Equal(seq[0].Request.Method, "POST"),
+		it.Equal(seq[0].Request.URL, ts.URL+"/echo?q=a"),
+		it.Equal(seq[0].Request.QueryString[0].Value, "a"),
+		it.Equal(seq[0].Request.PostData.Text, `{"site":`),
+		it.Equal(seq[0].Response.Status, 200),
+		it.Equal(seq[0].Response.Content.MimeType, "application/json"),
+		it.Equal(seq[0].Response.Content.Text, `{"site":`),
+		it.True(seq[0].Time > 0),
+	)
+
+	buf := bytes.Buffer{}
+	_, err = har.WriteTo(&buf)
+	it.Then(t).Should(it.Nil(err))
+
+	var doc struct {
+		Log struct {
+			Version string            `json:"version"`
+			Entries []json.RawMessage `json:"entries"`
+		} `json:"log"`
+	}
+	err = json.Unmarshal(buf.Bytes(), &doc)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(doc.Log.Version, "1.2"),
+		it.Equal(len(doc.Log.Entries), 1),
+	)
+}
+
+func TestHARRedact(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer ts.Close()
+
+	req := µ.GET(
+		ø.URI(ts.URL),
+		ø.Authorization.Set("Bearer secret"),
+		ø.Header("Cookie", "session=secret"),
+		ƒ.Status.OK,
+	)
+
+	value := func(seq []µ.HARPair, key string) string {
+		for _, p := range seq {
+			if p.Name == key {
+				return p.Value
+			}
+		}
+		return ""
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		har := µ.NewHAR(0)
+		err := µ.New(µ.WithHAR(har)).IO(context.Background(), req)
+
+		seq := har.Entries()
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(value(seq[0].Request.Headers, "Authorization"), "[REDACTED]"),
+			it.Equal(value(seq[0].Request.Headers, "Cookie"), "[REDACTED]"),
+			it.Equal(seq[0].Request.Cookies[0].Value, "[REDACTED]"),
+			it.Equal(value(seq[0].Response.Headers, "Set-Cookie"), "[REDACTED]"),
+			it.Equal(seq[0].Response.Cookies[0].Value, "[REDACTED]"),
+		)
+	})
+
+	t.Run("OptOut", func(t *testing.T) {
+		har := µ.NewHAR(0)
+		har.Redact = nil
+		err := µ.New(µ.WithHAR(har)).IO(context.Background(), req)
+
+		seq := har.Entries()
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(value(seq[0].Request.Headers, "Authorization"), "Bearer secret"),
+			it.Equal(seq[0].Request.Cookies[0].Value, "secret"),
+			it.Equal(seq[0].Response.Cookies[0].Value, "secret"),
+		)
+	})
+}
+
+func TestHARFailure(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close()
+
+	har := µ.NewHAR(0)
+	err := µ.New(µ.WithHAR(har)).IO(context.Background(),
+		µ.GET(ø.URI(ts.URL), ƒ.Status.OK),
+	)
+
+	seq := har.Entries()
+	it.Then(t).ShouldNot(it.Nil(err))
+	it.Then(t).Should(
+		it.Equal(len(seq), 1),
+		it.Equal(seq[0].Request.URL, ts.URL),
+		it.Equal(seq[0].Response.Status, 0),
+		it.True(seq[0].Comment != ""),
+	)
+}
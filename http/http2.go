@@ -0,0 +1,85 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+
+	"golang.org/x/net/http2"
+)
+
+//
+// The file implements configuration of HTTP/2 transport
+//
+
+func withHTTP2(cat *Protocol, priorKnowledge bool) error {
+	cli, err := clientOf(cat)
+	if err != nil {
+		return err
+	}
+
+	t, ok := cli.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unsupported transport type %T", cli.Transport)
+	}
+
+	t.ForceAttemptHTTP2 = true
+	if !priorKnowledge {
+		return nil
+	}
+
+	// Note: the dialer of transport is resolved on each connection, so that
+	//       options wrapping it (e.g. WithStats) apply to h2c as well.
+	cli.Transport = &h2cTransport{
+		Transport: t,
+		h2c: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				dial := t.DialContext
+				if dial == nil {
+					dial = (&net.Dialer{}).DialContext
+				}
+				return dial(ctx, network, addr)
+			},
+		},
+	}
+
+	return nil
+}
+
+// transportOf returns the transport dialing connections of the client
+func transportOf(cli *http.Client) (*http.Transport, error) {
+	switch t := cli.Transport.(type) {
+	case *http.Transport:
+		return t, nil
+	case *h2cTransport:
+		return t.Transport, nil
+	default:
+		return nil, fmt.Errorf("unsupported transport type %T", t)
+	}
+}
+
+// h2cTransport uses HTTP/2 with prior knowledge (h2c) for cleartext
+// requests, TLS requests negotiate protocol using ALPN.
+type h2cTransport struct {
+	*http.Transport
+	h2c *http2.Transport
+}
+
+func (t *h2cTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "http" {
+		return t.h2c.RoundTrip(req)
+	}
+
+	return t.Transport.RoundTrip(req)
+}
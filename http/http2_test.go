@@ -0,0 +1,118 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestHTTP2(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		ts := httptest.NewUnstartedServer(handler)
+		ts.EnableHTTP2 = true
+		ts.StartTLS()
+		defer ts.Close()
+
+		var proto string
+		err := µ.New(µ.WithInsecureTLS(), µ.WithHTTP2(false)).IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.Proto.HTTP2,
+				ƒ.Proto.To(&proto),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(proto, "HTTP/2.0"),
+		)
+	})
+
+	t.Run("TLSDefault", func(t *testing.T) {
+		ts := httptest.NewUnstartedServer(handler)
+		ts.EnableHTTP2 = true
+		ts.StartTLS()
+		defer ts.Close()
+
+		err := µ.New(µ.WithInsecureTLS()).IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.Proto.HTTP11,
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("PriorKnowledge", func(t *testing.T) {
+		ts := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+		defer ts.Close()
+
+		err := µ.New(µ.WithHTTP2(true)).IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.Proto.Is("HTTP/2.0"),
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("PriorKnowledgeStats", func(t *testing.T) {
+		ts := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+		defer ts.Close()
+
+		stack := µ.New(µ.WithHTTP2(true), µ.WithStats()).(*µ.Protocol)
+		err := stack.IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.Proto.Is("HTTP/2.0"),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(stack.Stats().IdleConns, 1),
+		)
+	})
+
+	t.Run("UnsupportedTransport", func(t *testing.T) {
+		cli := &http.Client{Transport: &http2.Transport{}}
+		_, err := µ.NewStack(µ.WithClient(cli), µ.WithStats())
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		ts := httptest.NewServer(handler)
+		defer ts.Close()
+
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.Proto.HTTP2,
+			),
+		)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+}
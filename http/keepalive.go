@@ -0,0 +1,137 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"syscall"
+	"time"
+)
+
+//
+// The file implements diagnostic of keep-alive connections, used for
+// validating keep-alive and load balancer idle-timeout configurations.
+//
+
+// KeepAliveSample is the outcome of single request
+type KeepAliveSample struct {
+	Seq      int           `json:"seq"`
+	Duration time.Duration `json:"duration"`
+	Conn     string        `json:"conn,omitempty"`
+	Reused   bool          `json:"reused"`
+	Reset    bool          `json:"reset,omitempty"`
+	Reason   string        `json:"reason,omitempty"`
+}
+
+// KeepAliveReport is the outcome of keep-alive diagnostic. The connection
+// is reset if the request is not able to reuse it, or the request is failed
+// due to closed connection.
+type KeepAliveReport struct {
+	Requests int               `json:"requests"`
+	Resets   int               `json:"resets"`
+	Failures int               `json:"failures"`
+	Mean     time.Duration     `json:"mean"`
+	Max      time.Duration     `json:"max"`
+	Samples  []KeepAliveSample `json:"samples"`
+}
+
+func (r *KeepAliveReport) String() string {
+	return fmt.Sprintf("requests: %d, resets: %d, failures: %d, latency mean: %v, max: %v",
+		r.Requests, r.Resets, r.Failures, r.Mean, r.Max)
+}
+
+// KeepAlive issues n sequential requests over the single connection, the
+// stack is not able to grow the pool. Requests are separated by idle
+// interval, set it close to idle timeout of the server or load balancer.
+//
+//	report, err := http.KeepAlive(context.Background(), 10, 55*time.Second,
+//		http.GET(
+//			ø.URI("https://example.com/health"),
+//			ƒ.Status.OK,
+//		),
+//	)
+func KeepAlive(ctx context.Context, n int, idle time.Duration, arrow Arrow, opt ...Option) (*KeepAliveReport, error) {
+	stack, err := NewStack(append(opt, WithSingleConnection())...)
+	if err != nil {
+		return nil, err
+	}
+
+	clock := stack.WithContext(ctx).Clock()
+	report := &KeepAliveReport{Samples: make([]KeepAliveSample, 0, n)}
+	for i := 0; i < n; i++ {
+		if i > 0 && idle > 0 {
+			select {
+			case <-ctx.Done():
+				return report, ctx.Err()
+			case <-clock.After(idle):
+			}
+		}
+
+		sample := KeepAliveSample{Seq: i}
+		trace := &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) {
+				sample.Reused = info.Reused
+				sample.Conn = info.Conn.LocalAddr().String()
+			},
+		}
+
+		c := stack.WithContext(httptrace.WithClientTrace(ctx, trace))
+		t := clock.Now()
+		err := c.IO(arrow)
+		sample.Duration = clock.Now().Sub(t)
+		sample.Reset = (i > 0 && !sample.Reused) || isConnReset(err)
+		if err != nil {
+			sample.Reason = err.Error()
+			report.Failures++
+		}
+		if sample.Reset {
+			report.Resets++
+		}
+
+		report.Requests++
+		report.Mean += sample.Duration
+		report.Max = max(report.Max, sample.Duration)
+		report.Samples = append(report.Samples, sample)
+	}
+
+	if report.Requests > 0 {
+		report.Mean /= time.Duration(report.Requests)
+	}
+
+	return report, nil
+}
+
+func isConnReset(err error) bool {
+	return err != nil && (errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF))
+}
+
+func withSingleConnection(cat *Protocol) error {
+	cli, err := clientOf(cat)
+	if err != nil {
+		return err
+	}
+
+	switch t := cli.Transport.(type) {
+	case *http.Transport:
+		t.DisableKeepAlives = false
+		t.MaxConnsPerHost = 1
+		t.MaxIdleConnsPerHost = 1
+	default:
+		return fmt.Errorf("unsupported transport type %T", t)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestKeepAlive(t *testing.T) {
+	t.Run("Reused", func(t *testing.T) {
+		ts := mock()
+		defer ts.Close()
+
+		report, err := µ.KeepAlive(context.Background(), 5, 0,
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(report.Requests, 5),
+			it.Equal(report.Resets, 0),
+			it.Equal(report.Failures, 0),
+			it.True(!report.Samples[0].Reused),
+			it.True(report.Samples[4].Reused),
+			it.Equal(report.Samples[0].Conn, report.Samples[4].Conn),
+		)
+	})
+
+	t.Run("IdleTimeout", func(t *testing.T) {
+		ts := httptest.NewUnstartedServer(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}),
+		)
+		ts.Config.IdleTimeout = 10 * time.Millisecond
+		ts.Start()
+		defer ts.Close()
+
+		report, err := µ.KeepAlive(context.Background(), 3, 100*time.Millisecond,
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(report.Requests, 3),
+			it.Equal(report.Resets, 2),
+			it.True(report.Samples[1].Reset),
+		)
+	})
+}
@@ -0,0 +1,92 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+//
+// The file implements limits of response headers, protecting the stack
+// from malicious or broken upstreams.
+//
+
+// Kinds of response header limits
+const (
+	LimitHeaderBytes = "bytes"
+	LimitHeaderCount = "count"
+)
+
+// HeaderLimitError is returned if response headers exceed the limit of
+// the stack. The actual size is unknown (0) if the bytes limit is exceeded,
+// the transport aborts reading of headers.
+type HeaderLimitError struct {
+	Limit  string
+	Max    int64
+	Actual int64
+	Host   string
+}
+
+func (e *HeaderLimitError) Error() string {
+	if e.Actual > 0 {
+		return fmt.Sprintf("response headers of %s exceed %s limit %d: %d", e.Host, e.Limit, e.Max, e.Actual)
+	}
+	return fmt.Sprintf("response headers of %s exceed %s limit %d", e.Host, e.Limit, e.Max)
+}
+
+func withMaxResponseHeaderBytes(cat *Protocol, n int64) error {
+	cli, err := clientOf(cat)
+	if err != nil {
+		return err
+	}
+
+	switch t := cli.Transport.(type) {
+	case *http.Transport:
+		t.MaxResponseHeaderBytes = n
+	default:
+		return fmt.Errorf("unsupported transport type %T", t)
+	}
+
+	cat.maxHeaderBytes = n
+	return nil
+}
+
+func withMaxResponseHeaders(cat *Protocol, n int) error {
+	cat.maxHeaders = n
+	return nil
+}
+
+// guards the response against limits of headers
+func (stack *Protocol) limitHeaders(req *http.Request, in *http.Response, err error) (*http.Response, error) {
+	if err != nil {
+		// Note: transport does not define the error type
+		if stack.maxHeaderBytes > 0 && strings.Contains(err.Error(), "server response headers exceeded") {
+			return nil, &HeaderLimitError{Limit: LimitHeaderBytes, Max: stack.maxHeaderBytes, Host: req.URL.Host}
+		}
+		return in, err
+	}
+
+	if stack.maxHeaders <= 0 {
+		return in, nil
+	}
+
+	n := 0
+	for _, vals := range in.Header {
+		n += len(vals)
+	}
+
+	if n > stack.maxHeaders {
+		in.Body.Close()
+		return nil, &HeaderLimitError{Limit: LimitHeaderCount, Max: int64(stack.maxHeaders), Actual: int64(n), Host: req.URL.Host}
+	}
+
+	return in, nil
+}
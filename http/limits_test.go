@@ -0,0 +1,69 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestHeaderLimits(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for i := 0; i < 10; i++ {
+				w.Header().Add(fmt.Sprintf("X-Value-%d", i), strings.Repeat("x", 100))
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer ts.Close()
+
+	req := µ.GET(ø.URI(ts.URL), ƒ.Status.OK)
+
+	t.Run("Bytes", func(t *testing.T) {
+		err := µ.New(µ.WithMaxResponseHeaderBytes(512)).IO(context.Background(), req)
+
+		var e *µ.HeaderLimitError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Equal(e.Limit, µ.LimitHeaderBytes),
+			it.Equal(e.Max, 512),
+		)
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		err := µ.New(µ.WithMaxResponseHeaders(5)).IO(context.Background(), req)
+
+		var e *µ.HeaderLimitError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Equal(e.Limit, µ.LimitHeaderCount),
+			it.Equal(e.Max, 5),
+			it.True(e.Actual > 10),
+		)
+	})
+
+	t.Run("Within", func(t *testing.T) {
+		err := µ.New(
+			µ.WithMaxResponseHeaderBytes(64*1024),
+			µ.WithMaxResponseHeaders(100),
+		).IO(context.Background(), req)
+		it.Then(t).Should(it.Nil(err))
+	})
+}
@@ -0,0 +1,80 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"net/http"
+)
+
+//
+// The file implements chain of socket middlewares
+//
+
+// Middleware is cross-cutting behavior (metrics, auth, tracing, etc) wrapping
+// the Socket of the stack. Use method value to install it
+//
+//	stack := http.New(http.WithMiddleware(mw.Wrap))
+type Middleware interface {
+	Wrap(Socket) Socket
+}
+
+// SocketFunc is an adapter to use ordinary functions as Socket
+//
+//	func(next http.Socket) http.Socket {
+//		return http.SocketFunc(func(req *http.Request) (*http.Response, error) {
+//			// ...
+//			return next.Do(req)
+//		})
+//	}
+type SocketFunc func(*http.Request) (*http.Response, error)
+
+// Do implements Socket interface
+func (f SocketFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
+
+func withMiddleware(cat *Protocol, mw func(Socket) Socket) error {
+	cat.middleware = append(cat.middleware, mw)
+	return nil
+}
+
+// builds chain of middlewares, the first one is outermost
+func (stack *Protocol) chain() {
+	if len(stack.middleware) == 0 {
+		return
+	}
+
+	socket := stack.Socket
+	for i := len(stack.middleware) - 1; i >= 0; i-- {
+		socket = stack.middleware[i](socket)
+	}
+	stack.socket = socket
+}
+
+func (stack *Protocol) do(req *http.Request) (*http.Response, error) {
+	if stack.cache != nil {
+		return stack.cache.do(req, stack.count)
+	}
+
+	return stack.count(req)
+}
+
+func (stack *Protocol) count(req *http.Request) (*http.Response, error) {
+	if stack.stats != nil {
+		return stack.stats.do(req, stack.send)
+	}
+
+	return stack.send(req)
+}
+
+func (stack *Protocol) send(req *http.Request) (*http.Response, error) {
+	if stack.socket != nil {
+		return stack.socket.Do(req)
+	}
+
+	return stack.Socket.Do(req)
+}
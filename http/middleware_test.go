@@ -0,0 +1,60 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+type tagger string
+
+func (tag tagger) Wrap(next µ.Socket) µ.Socket {
+	return µ.SocketFunc(func(req *http.Request) (*http.Response, error) {
+		req.Header.Add("X-Chain", string(tag))
+		return next.Do(req)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	var chain []string
+	trace := func(next µ.Socket) µ.Socket {
+		return µ.SocketFunc(func(req *http.Request) (*http.Response, error) {
+			in, err := next.Do(req)
+			chain = req.Header.Values("X-Chain")
+			return in, err
+		})
+	}
+
+	cat := µ.New(
+		µ.WithMiddleware(trace),
+		µ.WithMiddleware(tagger("a").Wrap),
+		µ.WithMiddleware(tagger("b").Wrap),
+	)
+
+	err := cat.IO(context.Background(),
+		µ.GET(
+			ø.URI("%s/ok", ø.Authority(ts.URL)),
+			ƒ.Status.OK,
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Seq(chain).Equal("a", "b"),
+	)
+}
@@ -10,25 +10,203 @@ package mock
 
 import (
 	"bytes"
-	µ "github.com/fogfish/gurl/v2/http"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
 )
 
 // Mocks HTTP client
 type Mock struct {
-	r   *http.Response
-	err error
+	sync.Mutex
+	status  int
+	header  http.Header
+	body    []byte
+	ioerr   error
+	err     error
+	latency time.Duration
+	routes  []*route
+	calls   []Call
+	expects []expect
+}
+
+// expect is the assertion of outgoing request
+type expect func(req *http.Request, body []byte) error
+
+// Call is the request received by the mock
+type Call struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+type route struct {
+	method    string
+	path      []string
+	responses []*Mock
+	calls     int
+}
+
+func newMock() *Mock {
+	return &Mock{
+		status: http.StatusOK,
+		header: http.Header{},
+	}
 }
 
 func (mock *Mock) Do(req *http.Request) (*http.Response, error) {
+	call := Call{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		call.Body = body
+	}
+
+	mock.Lock()
+	mock.calls = append(mock.calls, call)
+	responder := mock
+	for _, r := range mock.routes {
+		if r.match(req.Method, req.URL.Path) {
+			responder = r.responses[min(r.calls, len(r.responses)-1)]
+			r.calls++
+			break
+		}
+	}
+	mock.Unlock()
+
+	if err := mock.assert(req, call.Body); err != nil {
+		return nil, err
+	}
+
+	if responder != mock {
+		if err := responder.assert(req, call.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	return responder.reply(req)
+}
+
+func (mock *Mock) assert(req *http.Request, body []byte) error {
+	for _, f := range mock.expects {
+		if err := f(req, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mock *Mock) reply(req *http.Request) (*http.Response, error) {
+	if mock.latency > 0 {
+		select {
+		case <-time.After(mock.latency):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
 	if mock.err != nil {
 		return nil, mock.err
 	}
 
-	return mock.r, nil
+	var body io.ReadCloser = io.NopCloser(bytes.NewReader(mock.body))
+	if mock.ioerr != nil {
+		body = io.NopCloser(errReader{mock.ioerr})
+	}
+
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", mock.status, http.StatusText(mock.status)),
+		StatusCode: mock.status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     mock.header.Clone(),
+		Body:       body,
+		Request:    req,
+	}, nil
 }
 
+// Calls returns requests matching the pattern (e.g. "POST /users/*")
+func (mock *Mock) Calls(pattern string) []Call {
+	method, path := parsePattern(pattern)
+	r := route{method: method, path: path}
+
+	mock.Lock()
+	defer mock.Unlock()
+
+	seq := []Call{}
+	for _, call := range mock.calls {
+		if r.match(call.Method, pathOf(call.URL)) {
+			seq = append(seq, call)
+		}
+	}
+	return seq
+}
+
+// Verify number of requests matching the pattern, it expects at least one
+// request if expectations are not defined.
+//
+//	m.Verify(t, "POST /users", mock.Times(2))
+func (mock *Mock) Verify(t testing.TB, pattern string, expect ...Expect) bool {
+	t.Helper()
+
+	if len(expect) == 0 {
+		expect = []Expect{AtLeast(1)}
+	}
+
+	n := len(mock.Calls(pattern))
+	for _, f := range expect {
+		if err := f(n); err != nil {
+			t.Errorf("mock %s: %v", pattern, err)
+			return false
+		}
+	}
+	return true
+}
+
+// Expect is the assertion of number of calls
+type Expect func(n int) error
+
+// Times expects exactly n calls
+func Times(n int) Expect {
+	return func(x int) error {
+		if x != n {
+			return fmt.Errorf("expected %d calls, received %d", n, x)
+		}
+		return nil
+	}
+}
+
+// AtLeast expects n or more calls
+func AtLeast(n int) Expect {
+	return func(x int) error {
+		if x < n {
+			return fmt.Errorf("expected at least %d calls, received %d", n, x)
+		}
+		return nil
+	}
+}
+
+// Never expects no calls
+func Never() Expect { return Times(0) }
+
 // Mocks Option
 type Option func(m *Mock)
 
@@ -50,46 +228,187 @@ func Fail(err error) Option {
 // Mock response with status code (default 200)
 func Status(code int) Option {
 	return func(m *Mock) {
-		m.r.StatusCode = code
+		m.status = code
 	}
 }
 
 // Mock response with HTTP header (default none)
 func Header(h, v string) Option {
 	return func(m *Mock) {
-		m.r.Header.Set(h, v)
+		m.header.Set(h, v)
 	}
 }
 
 // Mock response body (default empty)
 func Body(body []byte) Option {
 	return func(m *Mock) {
-		m.r.Body = io.NopCloser(bytes.NewBuffer(body))
+		m.body = body
 	}
 }
 
 // Mock response body I/O error (default nil)
 func IOError(err error) Option {
 	return func(m *Mock) {
-		m.r.Body = io.NopCloser(errReader{err})
+		m.ioerr = err
 	}
 }
 
-// Mock HTTP Client
-func New(opts ...Option) µ.Option {
-	m := &Mock{
-		r: &http.Response{
-			StatusCode: http.StatusOK,
-			Header:     http.Header{},
-			Body:       io.NopCloser(bytes.NewBuffer([]byte{})),
-		},
+// Mock latency of response (default none)
+func Latency(d time.Duration) Option {
+	return func(m *Mock) {
+		m.latency = d
 	}
+}
+
+// Expect header of outgoing request, the value "*" matches any value.
+// The mismatch fails the I/O with gurl.NoMatch.
+func ExpectHeader(header, value string) Option {
+	return func(m *Mock) {
+		m.expects = append(m.expects, func(req *http.Request, _ []byte) error {
+			actual := req.Header.Get(header)
+			if (value == "*" && actual != "") || (value != "*" && actual == value) {
+				return nil
+			}
+
+			return &gurl.NoMatch{
+				ID:       "mock.Header",
+				Diff:     fmt.Sprintf("+ %s: %s\n- %s: %s", header, actual, header, value),
+				Protocol: header,
+				Expect:   value,
+				Actual:   actual,
+			}
+		})
+	}
+}
+
+// Expect query parameter of outgoing request, the value "*" matches any
+// value. The mismatch fails the I/O with gurl.NoMatch.
+func ExpectQuery(key, value string) Option {
+	return func(m *Mock) {
+		m.expects = append(m.expects, func(req *http.Request, _ []byte) error {
+			query := req.URL.Query()
+			actual := query.Get(key)
+			if (value == "*" && query.Has(key)) || (value != "*" && query.Has(key) && actual == value) {
+				return nil
+			}
+
+			return &gurl.NoMatch{
+				ID:       "mock.Query",
+				Diff:     fmt.Sprintf("+ %s=%s\n- %s=%s", key, actual, key, value),
+				Protocol: "query",
+				Expect:   value,
+				Actual:   actual,
+			}
+		})
+	}
+}
+
+// Expect JSON payload of outgoing request matching the pattern, the pattern
+// syntax is same as ƒ.Match. The mismatch fails the I/O with gurl.NoMatch.
+//
+//	mock.ExpectBodyJSON(`{"id": "_", "name": "joe"}`)
+func ExpectBodyJSON(pattern string) Option {
+	match := ƒ.Match(pattern)
+
+	return func(m *Mock) {
+		m.expects = append(m.expects, func(_ *http.Request, body []byte) error {
+			ctx := &µ.Context{
+				Response: &http.Response{
+					Header: http.Header{"Content-Type": {"application/json"}},
+					Body:   io.NopCloser(bytes.NewReader(body)),
+				},
+			}
+
+			if err := match(ctx); err != nil {
+				var e *gurl.NoMatch
+				if errors.As(err, &e) {
+					e.ID = "mock.Body"
+				}
+				return err
+			}
+			return nil
+		})
+	}
+}
+
+// Mock responses of the route, the pattern is method and path, the path
+// segment "*" matches any segment, the method is optional. Responses are
+// served in the order of calls, the last one is repeated. Requests not
+// matching any route are served with default response.
+//
+//	mock.Route("GET /users/*",
+//		mock.Preset(mock.Status(200), mock.Body(joe)),
+//		mock.Status(404),
+//	)
+func Route(pattern string, responses ...Option) Option {
+	method, path := parsePattern(pattern)
 
+	return func(m *Mock) {
+		r := &route{method: method, path: path}
+		for _, opt := range responses {
+			resp := newMock()
+			opt(resp)
+			r.responses = append(r.responses, resp)
+		}
+		if len(r.responses) == 0 {
+			r.responses = append(r.responses, newMock())
+		}
+
+		m.routes = append(m.routes, r)
+	}
+}
+
+func parsePattern(pattern string) (string, []string) {
+	method, path, has := strings.Cut(strings.TrimSpace(pattern), " ")
+	if !has {
+		method, path = "", method
+	}
+
+	return strings.ToUpper(method), strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func pathOf(uri string) string {
+	if u, err := url.Parse(uri); err == nil {
+		return u.Path
+	}
+	return uri
+}
+
+func (r *route) match(method, path string) bool {
+	if r.method != "" && r.method != method {
+		return false
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) != len(r.path) {
+		return false
+	}
+
+	for i, seg := range r.path {
+		if seg != "*" && seg != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Client creates mock of HTTP client, use it for assertion of calls
+//
+//	m := mock.Client(mock.Route("POST /users", mock.Status(201)))
+//	stack := http.New(http.WithClient(m))
+//	...
+//	m.Verify(t, "POST /users", mock.Times(1))
+func Client(opts ...Option) *Mock {
+	m := newMock()
 	for _, opt := range opts {
 		opt(m)
 	}
+	return m
+}
 
-	return µ.WithClient(m)
+// Mock HTTP Client
+func New(opts ...Option) µ.Option {
+	return µ.WithClient(Client(opts...))
 }
 
 type errReader struct{ err error }
@@ -0,0 +1,196 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package mock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+	"github.com/fogfish/gurl/v2/http/mock"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestMock(t *testing.T) {
+	type User struct {
+		ID string `json:"id"`
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		stack := µ.New(mock.New(mock.Body([]byte("hello"))))
+		for i := 0; i < 2; i++ {
+			var text string
+			err := stack.IO(context.Background(),
+				µ.GET(
+					ø.URI("http://example.com"),
+					ƒ.Status.OK,
+					ƒ.Bytes(textWriter{&text}),
+				),
+			)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(text, "hello"),
+			)
+		}
+	})
+
+	t.Run("Route", func(t *testing.T) {
+		m := mock.Client(
+			mock.Route("GET /users/*",
+				mock.Preset(
+					mock.Header("Content-Type", "application/json"),
+					mock.Body([]byte(`{"id": "joe"}`)),
+				),
+				mock.Status(404),
+			),
+			mock.Route("POST /users", mock.Status(201)),
+			mock.Status(501),
+		)
+		stack := µ.New(µ.WithClient(m))
+
+		var user User
+		err := stack.IO(context.Background(),
+			µ.GET(
+				ø.URI("http://example.com/users/joe"),
+				ƒ.Status.OK,
+				ƒ.Body(&user),
+			),
+			µ.GET(
+				ø.URI("http://example.com/users/joe"),
+				ƒ.Status.NotFound,
+			),
+			µ.GET(
+				ø.URI("http://example.com/users/joe"),
+				ƒ.Status.NotFound,
+			),
+			µ.POST(
+				ø.URI("http://example.com/users"),
+				ø.ContentType.JSON,
+				ø.Send(User{ID: "joe"}),
+				ƒ.Status.Created,
+			),
+			µ.DELETE(
+				ø.URI("http://example.com/users/joe"),
+				ƒ.Status.NotImplemented,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(user.ID, "joe"),
+			it.Equal(len(m.Calls("/users/*")), 4),
+			it.Equal(string(m.Calls("POST /users")[0].Body), `{"id":"joe"}`),
+			it.True(m.Verify(t, "GET /users/*", mock.Times(3))),
+			it.True(m.Verify(t, "POST /users")),
+			it.True(m.Verify(t, "PUT /users", mock.Never())),
+		)
+	})
+
+	t.Run("Latency", func(t *testing.T) {
+		stack := µ.New(mock.New(mock.Latency(50 * time.Millisecond)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := stack.IO(ctx,
+			µ.GET(
+				ø.URI("http://example.com"),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, context.DeadlineExceeded)),
+		)
+	})
+}
+
+func TestMockExpect(t *testing.T) {
+	type User struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	request := func(user User, page string) µ.Arrow {
+		return µ.POST(
+			ø.URI("http://example.com/users"),
+			ø.Param("page", page),
+			ø.ContentType.JSON,
+			ø.Authorization.Set("Bearer token"),
+			ø.Send(user),
+			ƒ.Status.Created,
+		)
+	}
+
+	stack := µ.New(
+		mock.New(
+			mock.ExpectHeader("Authorization", "*"),
+			mock.ExpectHeader("Content-Type", "application/json"),
+			mock.Route("POST /users",
+				mock.Preset(
+					mock.Status(201),
+					mock.ExpectQuery("page", "1"),
+					mock.ExpectBodyJSON(`{"id": "_", "name": "joe"}`),
+				),
+			),
+		),
+	)
+
+	t.Run("Match", func(t *testing.T) {
+		err := stack.IO(context.Background(), request(User{ID: "1", Name: "joe"}, "1"))
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("Body", func(t *testing.T) {
+		err := stack.IO(context.Background(), request(User{ID: "1", Name: "ann"}, "1"))
+
+		var e *gurl.NoMatch
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Equal(e.ID, "mock.Body"),
+		)
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		err := stack.IO(context.Background(), request(User{ID: "1", Name: "joe"}, "2"))
+
+		var e *gurl.NoMatch
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Equal(e.ID, "mock.Query"),
+		)
+	})
+
+	t.Run("Header", func(t *testing.T) {
+		err := stack.IO(context.Background(),
+			µ.POST(
+				ø.URI("http://example.com/users"),
+				ø.ContentType.Text,
+				ø.Send("joe"),
+				ƒ.Status.Created,
+			),
+		)
+
+		var e *gurl.NoMatch
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Equal(e.ID, "mock.Header"),
+		)
+	})
+}
+
+type textWriter struct{ s *string }
+
+func (w textWriter) Write(p []byte) (int, error) {
+	*w.s += string(p)
+	return len(p), nil
+}
@@ -11,6 +11,8 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"reflect"
 	"runtime"
@@ -21,27 +23,112 @@ import (
 )
 
 type Status struct {
-	ID       string        `json:"id"`
-	Status   string        `json:"status"`
-	Duration time.Duration `json:"duration"`
-	Reason   string        `json:"reason,omitempty"`
-	Payload  string        `json:"payload"`
+	ID       string            `json:"id"`
+	Status   string            `json:"status"`
+	Duration time.Duration     `json:"duration"`
+	Reason   string            `json:"reason,omitempty"`
+	Reasons  []string          `json:"reasons,omitempty"`
+	Scenario string            `json:"scenario,omitempty"`
+	Stage    string            `json:"stage,omitempty"`
+	Payload  string            `json:"payload"`
+	Endpoint string            `json:"endpoint,omitempty"`
+	Schema   *Schema           `json:"schema,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+	Attempts []Attempt         `json:"attempts,omitempty"`
 }
 
 // Evaluates sequence of tests, returns status object for each
 func Once(stack Stack, tests ...func() Arrow) []Status {
-	status := make([]Status, len(tests))
-
+	suites := make([]Suite, len(tests))
 	for i, test := range tests {
-		arr := test()
-		ctx := stack.WithContext(context.Background())
+		suites[i] = Suite{Name: arrowName(test), Spec: test}
+	}
+
+	return OnceSuites(stack, suites...)
+}
+
+// Evaluates sequence of registered suites, returns status object for each.
+// The status is identified by the name of suite. Deferred arrows are
+// evaluated at the end of the suite, their failures are joined into the
+// status of the last suite, use OnceDeferred to get own status of each.
+func OnceSuites(stack Stack, suites ...Suite) []Status {
+	status, _ := once(stack, suites, true)
+	return status
+}
+
+// OnceDeferred is OnceSuites that also returns status of deferred arrows
+// (see http.Defer) evaluated at the end of the suite, each one with own
+// status. It fails if the stack is not created by http.New or http.NewStack,
+// the cleanup is not supported by other implementations of Stack.
+func OnceDeferred(stack Stack, suites ...Suite) ([]Status, []Status, error) {
+	if _, ok := stack.(*Protocol); !ok {
+		return nil, nil, fmt.Errorf("deferred arrows are not supported by stack %T", stack)
+	}
+
+	status, deferred := once(stack, suites, false)
+	return status, deferred, nil
+}
+
+// once evaluates suites, failures of deferred arrows are joined into the
+// status of the last suite if requested.
+func once(stack Stack, suites []Suite, join bool) ([]Status, []Status) {
+	status := make([]Status, len(suites))
+	scope, d := withDeferred(context.Background())
 
-		t := time.Now()
+	var (
+		last     *Context
+		lastDur  time.Duration
+		lastErr  error
+		failures []error
+	)
+
+	for i, suite := range suites {
+		arr := suite.Spec()
+		ctx := stack.WithContext(scope)
+
+		t := ctx.Clock().Now()
 		err := ctx.IO(arr)
-		status[i] = newStatus(ctx, arrowName(test), time.Since(t), err)
+		dur := ctx.Clock().Now().Sub(t)
+		status[i] = newStatus(ctx, suite.Name, dur, err)
+		status[i].Attempts = ctx.Attempts
+		status[i].Tags = ctx.tagged
+		if ctx.Request != nil {
+			status[i].Endpoint = endpointOf(ctx.Request)
+		}
+		last, lastDur, lastErr = ctx, dur, err
 	}
 
-	return status
+	// Note: schemas are inferred across the suite, the status refers
+	//       the schema of endpoint
+	if p, ok := stack.(*Protocol); ok && p.schemas != nil {
+		for i := range status {
+			status[i].Schema, _ = p.schemas.Schema(status[i].Endpoint)
+		}
+	}
+
+	// Note: deferred arrows are evaluated at the end of the suite,
+	//       each one is reported with own status.
+	var deferred []Status
+	if p, ok := stack.(*Protocol); ok {
+		p.cleanup(scope, d, func(ctx *Context, dur time.Duration, err error) {
+			deferred = append(deferred, newStatus(ctx, "defer", dur, err))
+			if err != nil {
+				failures = append(failures, err)
+			}
+		})
+	}
+
+	if join && len(failures) != 0 && last != nil {
+		i := len(status) - 1
+		joined := newStatus(last, status[i].ID, lastDur, errors.Join(append([]error{lastErr}, failures...)...))
+		joined.Attempts = status[i].Attempts
+		joined.Tags = status[i].Tags
+		joined.Endpoint = status[i].Endpoint
+		joined.Schema = status[i].Schema
+		status[i] = joined
+	}
+
+	return status, deferred
 }
 
 func WriteOnce(w io.Writer, stack Stack, tests ...func() Arrow) error {
@@ -61,19 +148,54 @@ func WriteOnce(w io.Writer, stack Stack, tests ...func() Arrow) error {
 }
 
 func newStatus(ctx *Context, id string, dur time.Duration, err error) Status {
-	switch v := (err).(type) {
-	case nil:
+	errs := unwrapJoined(err)
+	if len(errs) == 1 {
+		err = errs[0]
+
+		var scenario *ScenarioError
+		if errors.As(err, &scenario) {
+			status := newStatus(ctx, id, dur, scenario.Err)
+			status.Scenario = scenario.Scenario
+			status.Stage = scenario.Stage
+			return status
+		}
+	}
+
+	if len(errs) > 1 {
+		status := Status{
+			ID:       id,
+			Status:   "nomatch",
+			Duration: dur,
+			Payload:  string(ctx.Payload),
+		}
+
+		for _, e := range errs {
+			sub := newStatus(ctx, id, dur, e)
+			if sub.Status != "nomatch" {
+				status.Status = "failure"
+			}
+			status.Reasons = append(status.Reasons, sub.Reason)
+		}
+		status.Reason = strings.Join(status.Reasons, "\n")
+
+		return status
+	}
+
+	// Note: NoMatch is wrapped by middlewares (e.g. tags, retries)
+	var nomatch *gurl.NoMatch
+	switch {
+	case err == nil:
 		return Status{
 			ID:       id,
 			Status:   "success",
 			Duration: dur,
 			Payload:  string(ctx.Payload),
 		}
-	case *gurl.NoMatch:
-		diff := v.Diff
+	case errors.As(err, &nomatch):
+		diff := nomatch.Diff
 		if diff == "" {
-			expect, _ := json.Marshal(v.Expect)
-			actual, _ := json.Marshal(v.Actual)
+			expect, _ := json.Marshal(nomatch.Expect)
+			actual, _ := json.Marshal(nomatch.Actual)
 			diff = "- " + string(expect) + "\n+ " + string(actual)
 		}
 		return Status{
@@ -94,6 +216,24 @@ func newStatus(ctx *Context, id string, dur time.Duration, err error) Status {
 	}
 }
 
+// flattens errors joined by errors.Join (e.g. http.Soft, http.JoinParallel)
+func unwrapJoined(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+
+	var seq []error
+	for _, e := range joined.Unwrap() {
+		seq = append(seq, unwrapJoined(e)...)
+	}
+	return seq
+}
+
 func arrowName(i interface{}) string {
 	name := runtime.FuncForPC(reflect.ValueOf(i).Pointer()).Name()
 	name = strings.TrimPrefix(name, "main.")
@@ -11,8 +11,10 @@ package http_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"testing"
 
+	"github.com/fogfish/gurl/v2"
 	"github.com/fogfish/gurl/v2/http"
 	ƒ "github.com/fogfish/gurl/v2/http/recv"
 	ø "github.com/fogfish/gurl/v2/http/send"
@@ -74,3 +76,82 @@ func TestWriteOnceNoMatch(t *testing.T) {
 		it.Equal(seq[0].Reason, "+ Content-Type: application/json\n- Content-Type: application/x-www-form-urlencoded"),
 	)
 }
+
+func TestOnceWrappedNoMatch(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	unittest := func() http.Arrow {
+		return http.GET(
+			ø.URI("/json"),
+			func(*http.Context) error {
+				return fmt.Errorf("check: %w", &gurl.NoMatch{Diff: "- X-Unknown: *"})
+			},
+		)
+	}
+
+	status := http.Once(http.New(http.WithHost(ts.URL)), unittest)
+	it.Then(t).Should(
+		it.Equal(status[0].Status, "nomatch"),
+		it.Equal(status[0].Reason, "- X-Unknown: *"),
+	)
+}
+
+func TestWriteOnceJoined(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	t.Run("NoMatch", func(t *testing.T) {
+		unittest := func() http.Arrow {
+			return http.GET(
+				ø.URI("/json"),
+				http.Soft(
+					ƒ.Status.OK,
+					ƒ.ContentType.Form,
+					ƒ.Header("X-Unknown", "*"),
+				),
+			)
+		}
+
+		buf := bytes.Buffer{}
+		hts := http.New(http.WithHost(ts.URL))
+		err := http.WriteOnce(&buf, hts, unittest)
+		it.Then(t).Should(it.Nil(err))
+
+		var seq []http.Status
+		err = json.Unmarshal(buf.Bytes(), &seq)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(seq[0].Status, "nomatch"),
+			it.Seq(seq[0].Reasons).Equal(
+				"+ Content-Type: application/json\n- Content-Type: application/x-www-form-urlencoded",
+				"- X-Unknown: *",
+			),
+		)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		unittest := func() http.Arrow {
+			return http.JoinParallel(
+				http.GET(ø.URI("/json"), ƒ.Status.Created),
+				http.GET(ø.URI("/json"), func(*http.Context) error { return fmt.Errorf("failed") }),
+			)
+		}
+
+		buf := bytes.Buffer{}
+		hts := http.New(http.WithHost(ts.URL))
+		err := http.WriteOnce(&buf, hts, unittest)
+		it.Then(t).Should(it.Nil(err))
+
+		var seq []http.Status
+		err = json.Unmarshal(buf.Bytes(), &seq)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(seq[0].Status, "failure"),
+			it.Seq(seq[0].Reasons).Equal(
+				"+ Status Code: 200\n- Status Code: 201",
+				"failed",
+			),
+		)
+	})
+}
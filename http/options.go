@@ -11,8 +11,11 @@ package http
 import (
 	"crypto/tls"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/http/cookiejar"
+	"time"
 
 	"github.com/fogfish/opts"
 	"golang.org/x/net/publicsuffix"
@@ -28,6 +31,10 @@ var (
 	//	type Socket interface {
 	//	  Do(req *http.Request) (*http.Response, error)
 	//	}
+	//
+	// Options configuring the client (dialer, proxy, HTTP/2, etc) fail on
+	// the custom Socket, use WithMiddleware to wrap the client instead.
+	// WithInsecureTLS, WithCookieJar and WithRedirects are no-op for it.
 	WithClient = opts.ForType[Protocol, Socket]()
 
 	// Set the default host for http stack.
@@ -40,9 +47,149 @@ var (
 	// Buffers HTTP Response Payload into context.
 	WithMementoPayload = WithMemento(true)
 
+	// Infers JSON schema of observed responses per endpoint, the option
+	// enables Memento. Reports of http.Once include the schema of endpoint.
+	//
+	//	schemas := http.NewSchemaInference()
+	//	stack := http.New(http.WithSchemaInference(schemas))
+	//	...
+	//	schemas.Schemas()
+	WithSchemaInference = opts.FMap(withSchemaInference)
+
 	// Disables TLS certificate validation for HTTP(S) sessions.
 	WithInsecureTLS = opts.From(withInsecureTLS)
 
+	// Accumulates counters of the stack, see Protocol.Stats.
+	//
+	//	stack := http.New(http.WithStats())
+	//	stack.(*http.Protocol).Stats()
+	WithStats = opts.From(withStats)
+
+	// Enables validation of URI templates, ø.URI fails with gurl.InvalidURI
+	// before any I/O if template is malformed. Enable it at test suites.
+	WithStrictURI = opts.From(withStrictURI)
+
+	// Replaces TLS configuration of the stack transport.
+	WithTLSConfig = opts.FMap(withTLSConfig)
+
+	// Verifies servers certificates using the pool of root certificates
+	// instead of the system one.
+	WithRootCAs = opts.FMap(withRootCAs)
+
+	// Authenticates the stack with client certificate (mutual TLS).
+	WithClientCertificate = opts.FMap(withClientCertificate)
+
+	// Enables HTTP/2 for TLS connections, the protocol is negotiated using ALPN.
+	// The prior knowledge enables HTTP/2 over cleartext (h2c) for "http"
+	// scheme, it requires support of HTTP/2 at the server. Options configuring
+	// transport must precede WithHTTP2(true).
+	WithHTTP2 = opts.FMap(withHTTP2)
+
+	// Establishes connections using the dialer instead of default one.
+	WithDialer = opts.FMap(withDialer)
+
+	// Establishes all connections to the unix domain socket, the host of URI
+	// is ignored but it is required by HTTP.
+	//
+	//	stack := http.New(http.WithUnixSocket("/var/run/docker.sock"))
+	//	stack.IO(context.Background(),
+	//		http.GET(
+	//			ø.URI("http://unix/containers/json"),
+	//			ƒ.Status.OK,
+	//		),
+	//	)
+	WithUnixSocket = opts.FMap(withUnixSocket)
+
+	// Forces the stack to use single keep-alive connection per host, the pool
+	// does not grow. Concurrent requests wait for the connection.
+	WithSingleConnection = opts.From(withSingleConnection)
+
+	// Limits size of response headers in bytes, the I/O fails with
+	// http.HeaderLimitError if the limit is exceeded.
+	WithMaxResponseHeaderBytes = opts.FMap(withMaxResponseHeaderBytes)
+
+	// Limits number of response headers (values of repeated headers are
+	// counted individually), the I/O fails with http.HeaderLimitError if the
+	// limit is exceeded.
+	WithMaxResponseHeaders = opts.FMap(withMaxResponseHeaders)
+
+	// Enables in-process caching of DNS lookups for given time-to-live.
+	WithDNSCache = opts.FMap(withDNSCache)
+
+	// Enables in-process caching of DNS lookups using given cache instance.
+	// Use the instance to flush the cache.
+	//
+	//	dns := http.NewDNSCache(5 * time.Minute)
+	//	stack := http.New(http.WithDNS(dns))
+	//	...
+	//	dns.Flush()
+	WithDNS = opts.FMap(withDNS)
+
+	// Enables private HTTP cache of GET requests (RFC 9111). The cache obeys
+	// Cache-Control, Expires and Vary, stale responses are revalidated with
+	// ETag or Last-Modified. Requests with unsafe methods invalidate the
+	// cached target URI.
+	//
+	//	stack := http.New(http.WithCache(http.NewMemoryCache(1024)))
+	WithCache = opts.FMap(withCache)
+
+	// Routes requests through the proxy, empty string configures proxy from
+	// environment variables (HTTP_PROXY, HTTPS_PROXY and NO_PROXY).
+	// The option enables diagnostic of proxy CONNECT handshake, see Context.Proxy.
+	WithProxy = opts.FMap(withProxy)
+
+	// Installs middleware wrapping the socket of the stack. Middlewares are
+	// chained in the order of options, the first one is outermost.
+	//
+	//	http.New(
+	//		http.WithMiddleware(func(next http.Socket) http.Socket { /* ... */ }),
+	//		http.WithMiddleware(tracer.Wrap),
+	//	)
+	WithMiddleware = opts.FMap(withMiddleware)
+
+	// Records every request and response in HTTP Archive (HAR) format.
+	//
+	//	har := http.NewHAR(64 * 1024)
+	//	stack := http.New(http.WithHAR(har))
+	//	...
+	//	har.WriteTo(file)
+	WithHAR = opts.FMap(withHAR)
+
+	// Rewrites responses (headers or body) before arrows see them. The rewrite
+	// is applied after decoding of compressed payload.
+	//
+	//	http.New(http.WithRewrite(http.UnwrapJSON("data")))
+	WithRewrite = opts.FMap(withRewrite)
+
+	// Converts errors of IO into caller-domain errors. Mappers are applied
+	// in the order of options, the mapper returning nil suppresses the error.
+	//
+	//	http.New(
+	//		http.WithErrorMapper(func(ctx *http.Context, err error) error {
+	//			if ctx.Response != nil && ctx.Response.StatusCode == 404 {
+	//				return ErrUserNotFound
+	//			}
+	//			return err
+	//		}),
+	//	)
+	WithErrorMapper = opts.FMap(withErrorMapper)
+
+	// Sets headers to every request of the stack, the headers declared by
+	// arrows of the request take precedence.
+	//
+	//	http.New(
+	//		http.WithDefaultHeaders(map[string]string{
+	//			"User-Agent": "my-client/1.0",
+	//			"X-Tenant":   "acme",
+	//		}),
+	//	)
+	WithDefaultHeaders = opts.FMap(withDefaultHeaders)
+
+	// Uses the clock for timing decisions of the stack (retries, circuit
+	// breaker, rate limiting, DNS cache, timeouts) and for measurements
+	// (durations of requests and suites, HAR), see http.ManualClock for tests.
+	WithClock = opts.FMap(withClock)
+
 	// Enables automated cookie handling across requests originated from the session.
 	WithCookieJar = opts.From(withCookieJar)
 
@@ -50,6 +197,14 @@ var (
 	// It enables the HTTP stack automatically follows redirects
 	WithRedirects = opts.From(withRedirects)
 
+	// Enables the HTTP stack to follow up to max redirects. The redirect chain
+	// is recorded at Context.Redirects.
+	WithFollowRedirects = opts.FMap(withFollowRedirects)
+
+	// Enables transparent decoding of compressed responses
+	// (Content-Encoding: gzip, deflate, br).
+	WithCompression = opts.From(withCompression)
+
 	// Enable log level
 	WithLogLevel = opts.ForName[Protocol, int]("LogLevel")
 
@@ -63,8 +218,112 @@ var (
 
 	// Enable debug logging.
 	WithDebugPayload = WithLogLevel(3)
+
+	// Routes debug logs to the writer instead of the standard logger.
+	// Use it to isolate logs of parallel tests.
+	WithLogWriter = opts.FMap(withLogWriter)
+
+	// Enables pretty-printing of JSON payloads in debug logs.
+	WithLogPrettyJSON = opts.From(withLogPrettyJSON)
+
+	// Enable debug logging, payloads are truncated to the limit of bytes.
+	// The total size of payload is reported.
+	WithDebugPayloadLimit = opts.ForName("LogPayloadLimit",
+		func(cat *Protocol, limit int) error {
+			cat.LogLevel = 3
+			return nil
+		},
+	)
 )
 
+// Sets the header to every request of the stack (e.g. User-Agent, tenant id).
+// The header declared by arrows of the request takes precedence.
+//
+//	http.New(http.WithDefaultHeader("User-Agent", "my-client/1.0"))
+func WithDefaultHeader(key, value string) Option {
+	return WithDefaultHeaders(map[string]string{key: value})
+}
+
+// Enables per-host circuit breaking. After threshold of consecutive failures
+// (transport errors or 5xx responses) requests to the host fail fast with
+// CircuitOpen error until cooldown expires. Then a single probe request is
+// let through, its outcome either closes or opens the circuit again.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return opts.FMap(withCircuitBreaker)(newCircuitBreaker(threshold, cooldown))
+}
+
+// Throttles outgoing requests of the stack using token-bucket algorithm,
+// requests per second with bursts of given size.
+func WithRateLimit(rps float64, burst int) Option {
+	return opts.FMap(withRateLimit)(newRateLimiter(rps, burst, false))
+}
+
+// Throttles outgoing requests using token-bucket algorithm, each host has
+// own bucket of requests per second with bursts of given size.
+func WithRateLimitPerHost(rps float64, burst int) Option {
+	return opts.FMap(withRateLimit)(newRateLimiter(rps, burst, true))
+}
+
+// Authenticates the stack with client certificate (mutual TLS) loaded from
+// the pair of PEM encoded files.
+func WithClientTLS(certFile, keyFile string) Option {
+	return opts.From(func(cat *Protocol) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+
+		return withClientCertificate(cat, cert)
+	})()
+}
+
+func withDefaultHeaders(cat *Protocol, headers map[string]string) error {
+	if cat.headers == nil {
+		cat.headers = http.Header{}
+	}
+	for key, val := range headers {
+		cat.headers.Set(key, val)
+	}
+	return nil
+}
+
+func withCompression(cat *Protocol) error {
+	cat.Compression = true
+	return nil
+}
+
+func withLogPrettyJSON(cat *Protocol) error {
+	cat.LogPrettyJSON = true
+	return nil
+}
+
+func withLogWriter(cat *Protocol, w io.Writer) error {
+	cat.logger = log.New(w, "", log.LstdFlags)
+	return nil
+}
+
+// clientOf returns the client of the stack, options configuring the client
+// fail if the stack uses other Socket (see WithClient). Use WithMiddleware
+// to wrap the client instead of replacing it. WithInsecureTLS, WithCookieJar
+// and WithRedirects remain no-op for other sockets.
+func clientOf(cat *Protocol) (*http.Client, error) {
+	cli, ok := cat.Socket.(*http.Client)
+	if !ok {
+		return nil, fmt.Errorf("unsupported socket type %T", cat.Socket)
+	}
+	return cli, nil
+}
+
+func withStrictURI(cat *Protocol) error {
+	cat.strictURI = true
+	return nil
+}
+
+// StrictURI reports if the stack validates URI templates, see WithStrictURI.
+func (ctx *Context) StrictURI() bool {
+	return ctx.stack != nil && ctx.stack.strictURI
+}
+
 func withInsecureTLS(cat *Protocol) error {
 	if cli, ok := cat.Socket.(*http.Client); ok {
 		switch t := cli.Transport.(type) {
@@ -0,0 +1,115 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"context"
+	"strings"
+)
+
+//
+// The file implements pagination, the "fetch until EOF" loop
+//
+
+// Cursor to the page. It supports page-number, offset/limit and
+// cursor-token strategies. The Link is the URL of next page, it is
+// discovered from Link header (rel="next") of the response.
+// The zero cursor is the first page or the end of pages.
+type Cursor struct {
+	Page   int
+	Offset int
+	Limit  int
+	Token  string
+	Link   string
+}
+
+// Paginate fetches pages until EOF. The builder makes a request for the
+// page at cursor, the response is decoded to T using Content-Type as a hint.
+// The extract makes the cursor of next page from the decoded one, the zero
+// cursor means there are no more pages. The Link header of the response
+// takes part in the cursor of next page.
+//
+//	type Page struct {
+//		Items []Item `json:"items"`
+//		Next  string `json:"next,omitempty"`
+//	}
+//
+//	pages, err := http.Paginate(context.Background(), stack,
+//		func(cursor http.Cursor) http.Arrow {
+//			return http.GET(
+//				ø.URI("https://example.com/items"),
+//				ø.Param("cursor", cursor.Token),
+//				ƒ.Status.OK,
+//			)
+//		},
+//		func(page *Page) http.Cursor {
+//			return http.Cursor{Token: page.Next}
+//		},
+//	)
+func Paginate[T any](ctx context.Context, stack Stack, builder func(Cursor) Arrow, extract func(*T) Cursor) ([]T, error) {
+	var (
+		pages  []T
+		cursor Cursor
+	)
+
+	for {
+		cat := stack.WithContext(ctx)
+		val, err := IO[T](cat, builder(cursor))
+		if err != nil {
+			cat.discardBody()
+			return pages, err
+		}
+
+		link := ""
+		if cat.Response != nil {
+			link = linkNext(cat.Response.Header.Values("Link"))
+		}
+		cat.discardBody()
+
+		pages = append(pages, *val)
+
+		cursor = extract(val)
+		cursor.Link = link
+		if cursor == (Cursor{}) {
+			return pages, nil
+		}
+	}
+}
+
+// parses Link header (RFC 8288) looking for rel="next"
+func linkNext(links []string) string {
+	for _, header := range links {
+		for _, link := range strings.Split(header, ",") {
+			segments := strings.Split(link, ";")
+			if len(segments) < 2 {
+				continue
+			}
+
+			uri := strings.TrimSpace(segments[0])
+			if !strings.HasPrefix(uri, "<") || !strings.HasSuffix(uri, ">") {
+				continue
+			}
+
+			for _, param := range segments[1:] {
+				kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+				if len(kv) != 2 || strings.ToLower(kv[0]) != "rel" {
+					continue
+				}
+
+				for _, rel := range strings.Fields(strings.Trim(kv[1], `"`)) {
+					if strings.ToLower(rel) == "next" {
+						return uri[1 : len(uri)-1]
+					}
+				}
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,112 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestPaginate(t *testing.T) {
+	type Page struct {
+		Items []int `json:"items"`
+	}
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			if page == 0 {
+				page = 1
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			if r.URL.Path == "/link" && page < 3 {
+				w.Header().Add("Link", fmt.Sprintf(`<%s/link?page=%d>; rel="next", <%s/link?page=3>; rel="last"`, ts.URL, page+1, ts.URL))
+			}
+
+			if page > 3 {
+				w.Write([]byte(`{"items":[]}`))
+				return
+			}
+			w.Write([]byte(fmt.Sprintf(`{"items":[%d]}`, page)))
+		}),
+	)
+	defer ts.Close()
+
+	t.Run("PageNumber", func(t *testing.T) {
+		pages, err := µ.Paginate(context.Background(), µ.New(),
+			func(cursor µ.Cursor) µ.Arrow {
+				return µ.GET(
+					ø.URI(ts.URL+"/items"),
+					ø.Param("page", cursor.Page),
+					ƒ.Status.OK,
+				)
+			},
+			func(page *Page) µ.Cursor {
+				if len(page.Items) == 0 {
+					return µ.Cursor{}
+				}
+				return µ.Cursor{Page: page.Items[0] + 1}
+			},
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(pages), 4),
+			it.Seq(pages[2].Items).Equal(3),
+		)
+	})
+
+	t.Run("Link", func(t *testing.T) {
+		pages, err := µ.Paginate(context.Background(), µ.New(),
+			func(cursor µ.Cursor) µ.Arrow {
+				uri := ts.URL + "/link"
+				if cursor.Link != "" {
+					uri = cursor.Link
+				}
+				return µ.GET(
+					ø.URI(uri),
+					ƒ.Status.OK,
+				)
+			},
+			func(page *Page) µ.Cursor { return µ.Cursor{} },
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(pages), 3),
+			it.Seq(pages[2].Items).Equal(3),
+		)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		pages, err := µ.Paginate(context.Background(), µ.New(),
+			func(cursor µ.Cursor) µ.Arrow {
+				return µ.GET(
+					ø.URI(ts.URL+"/items"),
+					ƒ.Status.NotFound,
+				)
+			},
+			func(page *Page) µ.Cursor { return µ.Cursor{} },
+		)
+		it.Then(t).Should(
+			it.Equal(len(pages), 0),
+		).ShouldNot(
+			it.Nil(err),
+		)
+	})
+}
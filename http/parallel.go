@@ -0,0 +1,65 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+//
+// The file implements concurrent fan-out of independent arrows
+//
+
+// JoinParallel composes independent HTTP arrows to high-order function,
+// arrows are evaluated concurrently over separate contexts. Errors of
+// arrows are joined.
+func JoinParallel(arrows ...Arrow) Arrow {
+	return JoinParallelN(len(arrows), arrows...)
+}
+
+// JoinParallelN is JoinParallel with parallelism bounded to n arrows.
+func JoinParallelN(n int, arrows ...Arrow) Arrow {
+	return func(cat *Context) error {
+		return parallel(cat.stack, cat.Context, n, arrows)
+	}
+}
+
+// IOParallel executes independent arrows concurrently, see JoinParallel.
+func (stack *Protocol) IOParallel(ctx context.Context, arrows ...Arrow) error {
+	return parallel(stack, ctx, len(arrows), arrows)
+}
+
+func parallel(stack *Protocol, ctx context.Context, n int, arrows []Arrow) error {
+	if n <= 0 {
+		n = 1
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(arrows))
+		sema = make(chan struct{}, n)
+	)
+
+	for i, f := range arrows {
+		wg.Add(1)
+		sema <- struct{}{}
+		go func(i int, f Arrow) {
+			defer wg.Done()
+			defer func() { <-sema }()
+
+			errs[i] = stack.IO(ctx, f)
+		}(i, f)
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
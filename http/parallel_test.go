@@ -0,0 +1,86 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestJoinParallel(t *testing.T) {
+	var inflight, peak int32
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&inflight, 1)
+			defer atomic.AddInt32(&inflight, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+
+			time.Sleep(20 * time.Millisecond)
+			if r.URL.Path == "/fail" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer ts.Close()
+
+	ok := µ.GET(ø.URI(ts.URL+"/ok"), ƒ.Status.OK)
+	fail := µ.GET(ø.URI(ts.URL+"/fail"), ƒ.Status.OK)
+
+	t.Run("JoinParallel", func(t *testing.T) {
+		atomic.StoreInt32(&peak, 0)
+
+		err := µ.New().IO(context.Background(),
+			µ.JoinParallel(ok, ok, ok, ok),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(atomic.LoadInt32(&peak), 4),
+		)
+	})
+
+	t.Run("JoinParallelN", func(t *testing.T) {
+		atomic.StoreInt32(&peak, 0)
+
+		err := µ.New().IO(context.Background(),
+			µ.JoinParallelN(2, ok, ok, ok, ok),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(atomic.LoadInt32(&peak) <= 2),
+		)
+	})
+
+	t.Run("IOParallel", func(t *testing.T) {
+		var seq interface{ Unwrap() []error }
+
+		err := µ.New().IOParallel(context.Background(), ok, fail, ok, fail)
+		it.Then(t).Should(
+			it.True(errors.As(err, &seq)),
+			it.Equal(len(seq.Unwrap()), 2),
+		)
+	})
+}
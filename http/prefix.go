@@ -0,0 +1,45 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+//
+// The file implements stripping of prefixes (BOM, XSSI guards) from payloads
+//
+
+// PrefixBOM is UTF-8 byte order mark
+const PrefixBOM = "\xef\xbb\xbf"
+
+// PrefixXSSI is known XSSI guards of JSON payloads (e.g. Google, Facebook
+// APIs), they are stripped before decoding.
+var PrefixXSSI = []string{")]}',\n", ")]}'\n", ")]}'", "while(1);", "for(;;);"}
+
+// PrefixReader strips the first matching prefix from the stream. The UTF-8
+// BOM is always stripped.
+func PrefixReader(stream io.Reader, prefixes ...string) io.Reader {
+	r := bufio.NewReader(stream)
+
+	if b, _ := r.Peek(len(PrefixBOM)); bytes.Equal(b, []byte(PrefixBOM)) {
+		r.Discard(len(b))
+	}
+
+	for _, prefix := range prefixes {
+		if b, _ := r.Peek(len(prefix)); bytes.Equal(b, []byte(prefix)) {
+			r.Discard(len(b))
+			break
+		}
+	}
+
+	return r
+}
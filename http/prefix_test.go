@@ -0,0 +1,88 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestPrefix(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/bom":
+				w.Write([]byte("\xef\xbb\xbf{\"site\": \"example.com\"}"))
+			case "/xssi":
+				w.Write([]byte(")]}',\n{\"site\": \"example.com\"}"))
+			case "/custom":
+				w.Write([]byte("\xef\xbb\xbf/*guard*/ {\"site\": \"example.com\"}"))
+			default:
+				w.Write([]byte(" \n{\"site\": \"example.com\"}"))
+			}
+		}),
+	)
+	defer ts.Close()
+
+	type Site struct {
+		Site string `json:"site"`
+	}
+
+	for _, path := range []string{"/bom", "/xssi", "/whitespace"} {
+		t.Run(path, func(t *testing.T) {
+			var site Site
+			err := µ.New().IO(context.Background(),
+				µ.GET(
+					ø.URI("%s%s", ø.Authority(ts.URL), ø.Path(path)),
+					ƒ.Status.OK,
+					ƒ.Body(&site),
+				),
+			)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(site.Site, "example.com"),
+			)
+		})
+	}
+
+	t.Run("TrimPrefix", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/custom", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.TrimPrefix("/*guard*/"),
+				ƒ.Match(`{"site": "example.com"}`),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+		)
+	})
+
+	t.Run("NoTrimPrefix", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/custom", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Match(`{"site": "example.com"}`),
+			),
+		)
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+	})
+}
@@ -0,0 +1,96 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+//
+// The file implements RFC 7807 problem details for HTTP APIs
+//
+
+// Problem is RFC 7807 problem details, the error envelope carried by
+// `application/problem+json` payloads. Members not defined by RFC are
+// preserved as extensions.
+type Problem struct {
+	Type       string         `json:"type,omitempty"`
+	Title      string         `json:"title,omitempty"`
+	Status     int            `json:"status,omitempty"`
+	Detail     string         `json:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+type problem Problem
+
+// UnmarshalJSON decodes problem details, unknown members become extensions
+func (p *Problem) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, (*problem)(p)); err != nil {
+		return err
+	}
+
+	var ext map[string]any
+	if err := json.Unmarshal(b, &ext); err != nil {
+		return err
+	}
+
+	for _, key := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(ext, key)
+	}
+
+	p.Extensions = nil
+	if len(ext) != 0 {
+		p.Extensions = ext
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes problem details together with extensions
+func (p Problem) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+
+	var obj map[string]any
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, err
+	}
+
+	for key, val := range p.Extensions {
+		if _, has := obj[key]; !has {
+			obj[key] = val
+		}
+	}
+
+	return json.Marshal(obj)
+}
+
+// ProblemError is the failure of HTTP I/O carrying problem details,
+// it wraps the StatusCode error of the response.
+type ProblemError struct {
+	Problem
+	StatusCode StatusCode
+}
+
+func (e *ProblemError) Error() string {
+	switch {
+	case e.Detail != "":
+		return fmt.Sprintf("%s: %s: %s", e.StatusCode.Error(), e.Title, e.Detail)
+	case e.Title != "":
+		return fmt.Sprintf("%s: %s", e.StatusCode.Error(), e.Title)
+	default:
+		return e.StatusCode.Error()
+	}
+}
+
+func (e *ProblemError) Unwrap() error { return e.StatusCode }
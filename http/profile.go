@@ -0,0 +1,196 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fogfish/opts"
+)
+
+//
+// The file implements profiles, named variables of the environment
+//
+
+// Profile is a set of named variables (host, credentials, tenant ids, etc)
+// specific to the environment.
+type Profile map[string]string
+
+// Profiles of environments (e.g. dev, stage, prod)
+//
+//	profiles := http.Profiles{
+//		"dev":  http.Profile{"host": "https://dev.example.com", "tenant": "t1"},
+//		"prod": http.Profile{"host": "https://example.com", "tenant": "t9"},
+//	}
+//
+//	stack := http.New(http.WithProfile(profiles, "dev"))
+//	stack.IO(context.Background(),
+//		http.GET(
+//			ø.URI("${host}/tenants/${tenant}"),
+//			ƒ.Status.OK,
+//		),
+//	)
+type Profiles map[string]Profile
+
+// Selects the profile of the stack, variables of the profile are resolvable
+// inside ø.URI and ø.Header using ${var} syntax.
+func WithProfile(profiles Profiles, name string) Option {
+	return opts.From(func(cat *Protocol) error {
+		profile, has := profiles[name]
+		if !has {
+			return fmt.Errorf("undefined profile %s", name)
+		}
+		cat.profile = profile
+		return nil
+	})()
+}
+
+// Overrides variables of the profile from environment variables with
+// the prefix, the name of variable is lower cased name of environment
+// variable without the prefix (e.g. APP_HOST overrides ${host}).
+//
+// The precedence of variables is defined by the source, not by the order
+// of options:
+//
+//  1. flags (http.WithVarFlags)
+//  2. environment (http.WithEnvOverrides)
+//  3. the profile (http.WithProfile)
+//
+// Example:
+//
+//	vars := http.VarFlags{}
+//	flag.Var(vars, "var", "override variable of profile (name=value)")
+//	flag.Parse()
+//
+//	stack := http.New(
+//		http.WithProfile(profiles, "dev"),
+//		http.WithEnvOverrides("APP_"),
+//		http.WithVarFlags(vars),
+//	)
+func WithEnvOverrides(prefix string) Option {
+	return opts.From(func(cat *Protocol) error {
+		if cat.envVars == nil {
+			cat.envVars = Profile{}
+		}
+		for _, kv := range os.Environ() {
+			key, val, _ := strings.Cut(kv, "=")
+			if name, has := strings.CutPrefix(key, prefix); has && name != "" {
+				cat.envVars[strings.ToLower(name)] = val
+			}
+		}
+		return nil
+	})()
+}
+
+// Overrides variables of the profile and environment from flags of the
+// suite binary, see WithEnvOverrides for precedence.
+func WithVarFlags(vars VarFlags) Option {
+	return opts.From(func(cat *Protocol) error {
+		if cat.flagVars == nil {
+			cat.flagVars = VarFlags{}
+		}
+		for key, val := range vars {
+			cat.flagVars[key] = val
+		}
+		return nil
+	})()
+}
+
+// VarFlags is the flag.Value collecting "name=value" overrides of variables
+// from the command line, the flag is repeatable.
+type VarFlags Profile
+
+func (vars VarFlags) String() string {
+	seq := make([]string, 0, len(vars))
+	for key, val := range vars {
+		seq = append(seq, key+"="+val)
+	}
+	sort.Strings(seq)
+	return strings.Join(seq, ",")
+}
+
+// Set parses "name=value" pair
+func (vars VarFlags) Set(s string) error {
+	key, val, has := strings.Cut(s, "=")
+	if !has || key == "" {
+		return fmt.Errorf("invalid variable %q, expected name=value", s)
+	}
+	vars[key] = val
+	return nil
+}
+
+// Var returns value of the profile variable
+func (ctx *Context) Var(name string) (string, error) {
+	if ctx.stack != nil {
+		if val, has := ctx.stack.flagVars[name]; has {
+			return val, nil
+		}
+		if val, has := ctx.stack.envVars[name]; has {
+			return val, nil
+		}
+		if val, has := ctx.stack.profile[name]; has {
+			return val, nil
+		}
+	}
+
+	return "", fmt.Errorf("undefined variable ${%s}", name)
+}
+
+// Expand replaces ${var} with values of the profile variables. The string
+// is returned unchanged if the stack has neither profile nor variables.
+func (ctx *Context) Expand(s string) (string, error) {
+	return ctx.expand(s, false)
+}
+
+// ExpandFormat is Expand for format strings, the `%` of substituted values
+// is escaped as `%%` so that values are not interpreted as format verbs.
+func (ctx *Context) ExpandFormat(s string) (string, error) {
+	return ctx.expand(s, true)
+}
+
+func (ctx *Context) hasVars() bool {
+	return ctx.stack != nil &&
+		(ctx.stack.profile != nil || ctx.stack.envVars != nil || ctx.stack.flagVars != nil)
+}
+
+func (ctx *Context) expand(s string, format bool) (string, error) {
+	if !ctx.hasVars() || !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	for {
+		at := strings.Index(s, "${")
+		if at == -1 {
+			sb.WriteString(s)
+			return sb.String(), nil
+		}
+
+		end := strings.IndexByte(s[at:], '}')
+		if end == -1 {
+			return "", fmt.Errorf("malformed variable %s", s[at:])
+		}
+
+		val, err := ctx.Var(s[at+2 : at+end])
+		if err != nil {
+			return "", err
+		}
+
+		if format {
+			val = strings.ReplaceAll(val, "%", "%%")
+		}
+
+		sb.WriteString(s[:at])
+		sb.WriteString(val)
+		s = s[at+end+1:]
+	}
+}
@@ -0,0 +1,168 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"flag"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestProfile(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	profiles := µ.Profiles{
+		"dev":  µ.Profile{"host": ts.URL, "path": "json", "token": "dev"},
+		"prod": µ.Profile{"host": "https://example.com", "path": "json", "token": "prod"},
+	}
+
+	t.Run("Expand", func(t *testing.T) {
+		cat := µ.New(µ.WithProfile(profiles, "dev"))
+		ctx := cat.WithContext(context.Background())
+
+		err := ctx.IO(
+			µ.GET(
+				ø.URI("${host}/${path}"),
+				ø.Authorization.Set("Bearer ${token}"),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(ctx.Request.Header.Get("Authorization"), "Bearer dev"),
+		)
+	})
+
+	t.Run("RuntimeValue", func(t *testing.T) {
+		t.Setenv("GURL_TOKEN", "s3cr${token}")
+
+		cat := µ.New(µ.WithProfile(profiles, "dev"))
+		ctx := cat.WithContext(context.Background())
+
+		err := ctx.IO(
+			µ.GET(
+				ø.URI("${host}/${path}"),
+				ø.Authorization.From(µ.Secret(µ.EnvSecrets("GURL_"), "TOKEN")),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(ctx.Request.Header.Get("Authorization"), "s3cr${token}"),
+		)
+	})
+
+	t.Run("NoProfile", func(t *testing.T) {
+		ctx := µ.New().WithContext(context.Background())
+
+		err := ctx.IO(
+			µ.GET(
+				ø.URI(ts.URL+"/json?q=${literal}"),
+				ø.Header("X-Value", "${literal}"),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(ctx.Request.URL.RawQuery, "q=${literal}"),
+			it.Equal(ctx.Request.Header.Get("X-Value"), "${literal}"),
+		)
+	})
+
+	t.Run("PercentValue", func(t *testing.T) {
+		cat := µ.New(µ.WithProfile(µ.Profiles{"dev": µ.Profile{"host": ts.URL, "tag": "50%25"}}, "dev"))
+		ctx := cat.WithContext(context.Background())
+
+		err := ctx.IO(
+			µ.GET(
+				ø.URI("${host}/%s?tag=${tag}", "json"),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(ctx.Request.URL.Path, "/json"),
+			it.Equal(ctx.Request.URL.RawQuery, "tag=50%25"),
+		)
+	})
+
+	t.Run("Var", func(t *testing.T) {
+		ctx := µ.New(µ.WithProfile(profiles, "prod")).WithContext(context.Background())
+		val, err := ctx.Var("host")
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(val, "https://example.com"),
+		)
+	})
+
+	t.Run("Undefined", func(t *testing.T) {
+		err := µ.New(µ.WithProfile(profiles, "dev")).IO(context.Background(),
+			µ.GET(
+				ø.URI("${host}/${unknown}"),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+
+	t.Run("UndefinedProfile", func(t *testing.T) {
+		_, err := µ.NewStack(µ.WithProfile(profiles, "stage"))
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+
+	t.Run("Overrides", func(t *testing.T) {
+		t.Setenv("TEST_PROFILE_TOKEN", "env")
+		t.Setenv("TEST_PROFILE_TENANT", "env")
+
+		vars := µ.VarFlags{}
+		fs := flag.NewFlagSet("suite", flag.ContinueOnError)
+		fs.Var(vars, "var", "override variable")
+		fs.Parse([]string{"-var", "tenant=flag"})
+
+		for order, options := range map[string][]µ.Option{
+			"ProfileEnvFlags": {
+				µ.WithProfile(profiles, "dev"),
+				µ.WithEnvOverrides("TEST_PROFILE_"),
+				µ.WithVarFlags(vars),
+			},
+			"FlagsEnvProfile": {
+				µ.WithVarFlags(vars),
+				µ.WithEnvOverrides("TEST_PROFILE_"),
+				µ.WithProfile(profiles, "dev"),
+			},
+		} {
+			t.Run(order, func(t *testing.T) {
+				ctx := µ.New(options...).WithContext(context.Background())
+
+				host, _ := ctx.Var("host")
+				token, _ := ctx.Var("token")
+				tenant, _ := ctx.Var("tenant")
+				it.Then(t).Should(
+					it.Equal(host, ts.URL),
+					it.Equal(token, "env"),
+					it.Equal(tenant, "flag"),
+				)
+			})
+		}
+
+		it.Then(t).Should(
+			it.Equal(vars.String(), "tenant=flag"),
+		)
+	})
+
+	t.Run("InvalidFlag", func(t *testing.T) {
+		it.Then(t).ShouldNot(it.Nil(µ.VarFlags{}.Set("tenant")))
+	})
+}
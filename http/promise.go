@@ -0,0 +1,105 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"reflect"
+
+	"github.com/fogfish/gurl/v2"
+)
+
+//
+// The file implements promises, values produced by reader morphisms (ƒ)
+// and consumed by writer morphisms (ø) of sub-sequent requests.
+//
+
+// Future is a value resolvable at evaluation time of the arrow
+type Future interface {
+	Resolve() (any, error)
+}
+
+// Promise is a placeholder of value, which is fulfilled by ƒ lens and later
+// consumed by ø arrows. Reading unfulfilled promise fails with
+// gurl.Unfulfilled error, enforcing the evaluation order of composition.
+//
+//	var id http.Promise[string]
+//
+//	http.Join(
+//		http.GET(
+//			ø.URI("https://example.com/id"),
+//			ƒ.Status.OK,
+//			ƒ.Fulfill(&id),
+//		),
+//		http.GET(
+//			ø.URI("https://example.com/user/%s", &id),
+//			ƒ.Status.OK,
+//		),
+//	)
+type Promise[T any] struct {
+	value     T
+	fulfilled bool
+	source    func() (T, error)
+}
+
+// Fulfill the promise with value
+func (p *Promise[T]) Fulfill(value T) {
+	p.value = value
+	p.fulfilled = true
+}
+
+// Fulfilled returns true if value is available
+func (p *Promise[T]) Fulfilled() bool {
+	if p.source != nil {
+		_, err := p.source()
+		return err == nil
+	}
+
+	return p.fulfilled
+}
+
+// Value of the promise, it fails if promise is not fulfilled yet.
+func (p *Promise[T]) Value() (T, error) {
+	if p.source != nil {
+		return p.source()
+	}
+
+	if !p.fulfilled {
+		return p.value, &gurl.Unfulfilled{Type: typeOf(p)}
+	}
+
+	return p.value, nil
+}
+
+// Resolve value of the promise, implements Future interface
+func (p *Promise[T]) Resolve() (any, error) { return p.Value() }
+
+// Map derives a new promise, the function f is applied lazily when value
+// of original promise is available.
+func (p *Promise[T]) Map(f func(T) T) *Promise[T] {
+	return Then(p, f)
+}
+
+// Then derives a new promise of other type, the function f is applied lazily
+// when value of original promise is available.
+func Then[A, B any](p *Promise[A], f func(A) B) *Promise[B] {
+	return &Promise[B]{
+		source: func() (B, error) {
+			a, err := p.Value()
+			if err != nil {
+				var b B
+				return b, err
+			}
+			return f(a), nil
+		},
+	}
+}
+
+func typeOf[T any](*Promise[T]) string {
+	return reflect.TypeOf((*T)(nil)).Elem().String()
+}
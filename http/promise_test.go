@@ -0,0 +1,107 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestPromise(t *testing.T) {
+	type Site struct {
+		Site string `json:"site"`
+	}
+
+	t.Run("Value", func(t *testing.T) {
+		var p µ.Promise[string]
+		_, err := p.Value()
+		it.Then(t).Should(
+			it.True(!p.Fulfilled()),
+			it.True(errors.As(err, new(*gurl.Unfulfilled))),
+		)
+
+		p.Fulfill("a")
+		val, err := p.Value()
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(p.Fulfilled()),
+			it.Equal(val, "a"),
+		)
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		var p µ.Promise[string]
+		m := p.Map(strings.ToUpper)
+		n := µ.Then(&p, func(s string) int { return len(s) })
+
+		_, err := m.Value()
+		it.Then(t).ShouldNot(it.Nil(err))
+
+		p.Fulfill("abc")
+		a, _ := m.Value()
+		b, _ := n.Value()
+		it.Then(t).Should(
+			it.Equal(a, "ABC"),
+			it.Equal(b, 3),
+		)
+	})
+
+	t.Run("Chain", func(t *testing.T) {
+		ts := mock()
+		defer ts.Close()
+
+		var site µ.Promise[Site]
+		var ctype µ.Promise[string]
+		req := µ.Join(
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.HeaderOf[string]("Content-Type").Fulfill(&ctype),
+				ƒ.Fulfill(&site),
+			),
+			µ.GET(
+				ø.URI("%s/%s", ø.Authority(ts.URL), µ.Then(&site, func(s Site) string { return "ok" })),
+				ƒ.Status.OK,
+			),
+		)
+
+		err := µ.New().IO(context.Background(), req)
+		val, _ := site.Value()
+		typ, _ := ctype.Value()
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(val.Site, "example.com"),
+			it.Equal(typ, "application/json"),
+		)
+	})
+
+	t.Run("Unfulfilled", func(t *testing.T) {
+		ts := mock()
+		defer ts.Close()
+
+		var site µ.Promise[string]
+		req := µ.GET(
+			ø.URI("%s/%s", ø.Authority(ts.URL), &site),
+			ƒ.Status.OK,
+		)
+
+		err := µ.New().IO(context.Background(), req)
+		it.Then(t).Should(
+			it.True(errors.As(err, new(*gurl.Unfulfilled))),
+		)
+	})
+}
@@ -0,0 +1,163 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+//
+// The file implements diagnostic of proxy CONNECT handshake
+//
+
+// ProxyConnect is diagnostic of CONNECT handshake with proxy. It is
+// available at Context only if the connection is established during
+// the request, reused connections do not perform handshake. Plain http
+// requests are forwarded to proxy without handshake, the diagnostic is
+// available only if proxy rejects authentication.
+type ProxyConnect struct {
+	URL        string
+	StatusCode int
+	Duration   time.Duration
+}
+
+// ProxyError is returned if proxy rejects CONNECT handshake or responds
+// 407 Proxy Authentication Required to the forwarded plain http request.
+// The error distinguishes proxy failures from origin ones. It wraps
+// StatusCode, use errors.Is(err, http.StatusProxyAuthRequired) to detect
+// proxy authentication failures.
+type ProxyError struct {
+	URL      string
+	Status   StatusCode
+	Duration time.Duration
+}
+
+func (e *ProxyError) Error() string {
+	return fmt.Sprintf("proxy %s failed: %s", e.URL, e.Status.Error())
+}
+
+func (e *ProxyError) Unwrap() error { return e.Status }
+
+type proxyTraceKey struct{}
+
+type proxyTrace struct {
+	proxy       *url.URL
+	sentAt      time.Time
+	connectedAt time.Time
+	ctx         *Context
+}
+
+func withProxyTrace(ctx *Context, eg *http.Request) *http.Request {
+	trace := &proxyTrace{ctx: ctx}
+	return eg.WithContext(context.WithValue(eg.Context(), proxyTraceKey{}, trace))
+}
+
+func withProxy(cat *Protocol, proxy string) error {
+	cli, err := clientOf(cat)
+	if err != nil {
+		return err
+	}
+
+	t, ok := cli.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unsupported transport type %T", cli.Transport)
+	}
+
+	proxyOf := http.ProxyFromEnvironment
+	if proxy != "" {
+		uri, err := url.Parse(proxy)
+		if err != nil {
+			return err
+		}
+		proxyOf = http.ProxyURL(uri)
+	}
+
+	// Note: the proxy is resolved for each request, empty one bypasses it
+	t.Proxy = func(req *http.Request) (*url.URL, error) {
+		uri, err := proxyOf(req)
+		if trace, ok := req.Context().Value(proxyTraceKey{}).(*proxyTrace); ok && uri != nil {
+			trace.proxy = uri
+			trace.sentAt = trace.ctx.Clock().Now()
+		}
+		return uri, err
+	}
+
+	dial := t.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if trace, ok := ctx.Value(proxyTraceKey{}).(*proxyTrace); ok {
+			trace.connectedAt = trace.ctx.Clock().Now()
+		}
+		return conn, err
+	}
+
+	t.OnProxyConnectResponse = func(ctx context.Context, proxyURL *url.URL, connectReq *http.Request, connectRes *http.Response) error {
+		trace, ok := ctx.Value(proxyTraceKey{}).(*proxyTrace)
+		if !ok {
+			return nil
+		}
+
+		diag := &ProxyConnect{
+			URL:        proxyURL.Redacted(),
+			StatusCode: connectRes.StatusCode,
+			Duration:   trace.ctx.Clock().Now().Sub(trace.connectedAt),
+		}
+		trace.ctx.Proxy = diag
+
+		if connectRes.StatusCode != http.StatusOK {
+			return &ProxyError{
+				URL:      diag.URL,
+				Status:   NewStatusCode(connectRes.StatusCode),
+				Duration: diag.Duration,
+			}
+		}
+
+		return nil
+	}
+
+	cat.proxy = true
+	return nil
+}
+
+// proxyStatus maps 407 Proxy Authentication Required of the plain http
+// request forwarded via proxy to ProxyError, as CONNECT handshake does.
+func (stack *Protocol) proxyStatus(eg *http.Request, in *http.Response, err error) (*http.Response, error) {
+	if err != nil || !stack.proxy || eg.URL.Scheme != "http" || in.StatusCode != http.StatusProxyAuthRequired {
+		return in, err
+	}
+
+	trace, ok := eg.Context().Value(proxyTraceKey{}).(*proxyTrace)
+	if !ok || trace.proxy == nil {
+		return in, err
+	}
+
+	in.Body.Close()
+
+	diag := &ProxyConnect{
+		URL:        trace.proxy.Redacted(),
+		StatusCode: in.StatusCode,
+		Duration:   trace.ctx.Clock().Now().Sub(trace.sentAt),
+	}
+	trace.ctx.Proxy = diag
+
+	return nil, &ProxyError{
+		URL:      diag.URL,
+		Status:   NewStatusCode(in.StatusCode),
+		Duration: diag.Duration,
+	}
+}
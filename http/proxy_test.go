@@ -0,0 +1,126 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestProxy(t *testing.T) {
+	origin := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer origin.Close()
+
+	proxy := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Proxy-Authorization") == "" {
+				w.WriteHeader(http.StatusProxyAuthRequired)
+				return
+			}
+
+			// forward proxy of plain http request
+			if r.Method != http.MethodConnect {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			dst, err := net.Dial("tcp", r.Host)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+
+			src, _, _ := w.(http.Hijacker).Hijack()
+			go func() { io.Copy(dst, src); dst.Close() }()
+			io.Copy(src, dst)
+			src.Close()
+		}),
+	)
+	defer proxy.Close()
+
+	t.Run("Connect", func(t *testing.T) {
+		uri := strings.Replace(proxy.URL, "http://", "http://user:pass@", 1)
+		cat := µ.New(µ.WithInsecureTLS(), µ.WithProxy(uri))
+		ctx := cat.WithContext(context.Background())
+		err := ctx.IO(
+			µ.GET(
+				ø.URI(origin.URL),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(ctx.Proxy.StatusCode, http.StatusOK),
+			it.True(ctx.Proxy.Duration > 0),
+		)
+	})
+
+	t.Run("AuthRequired", func(t *testing.T) {
+		cat := µ.New(µ.WithInsecureTLS(), µ.WithProxy(proxy.URL))
+		ctx := cat.WithContext(context.Background())
+		err := ctx.IO(
+			µ.GET(
+				ø.URI(origin.URL),
+				ƒ.Status.OK,
+			),
+		)
+
+		var perr *µ.ProxyError
+		it.Then(t).Should(
+			it.True(errors.As(err, &perr)),
+			it.True(errors.Is(err, µ.StatusProxyAuthRequired)),
+			it.Equal(ctx.Proxy.StatusCode, http.StatusProxyAuthRequired),
+		)
+	})
+
+	t.Run("Forward", func(t *testing.T) {
+		uri := strings.Replace(proxy.URL, "http://", "http://user:pass@", 1)
+		err := µ.New(µ.WithProxy(uri)).IO(context.Background(),
+			µ.GET(
+				ø.URI("http://example.com"),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("ForwardAuthRequired", func(t *testing.T) {
+		cat := µ.New(µ.WithProxy(proxy.URL))
+		ctx := cat.WithContext(context.Background())
+		err := ctx.IO(
+			µ.GET(
+				ø.URI("http://example.com"),
+				ƒ.Status.OK,
+			),
+		)
+
+		var perr *µ.ProxyError
+		it.Then(t).Should(
+			it.True(errors.As(err, &perr)),
+			it.True(errors.Is(err, µ.StatusProxyAuthRequired)),
+			it.Equal(ctx.Proxy.StatusCode, http.StatusProxyAuthRequired),
+		)
+	})
+}
@@ -0,0 +1,78 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+//
+// The file implements token-bucket throttling of outgoing requests
+//
+
+type rateLimiter struct {
+	sync.Mutex
+	rps     rate.Limit
+	burst   int
+	perHost bool
+	global  *rate.Limiter
+	hosts   map[string]*rate.Limiter
+}
+
+func newRateLimiter(rps float64, burst int, perHost bool) *rateLimiter {
+	return &rateLimiter{
+		rps:     rate.Limit(rps),
+		burst:   burst,
+		perHost: perHost,
+		global:  rate.NewLimiter(rate.Limit(rps), burst),
+		hosts:   map[string]*rate.Limiter{},
+	}
+}
+
+func (rl *rateLimiter) wait(ctx context.Context, host string, clock Clock) error {
+	limiter := rl.global
+	if rl.perHost {
+		rl.Lock()
+		var has bool
+		limiter, has = rl.hosts[host]
+		if !has {
+			limiter = rate.NewLimiter(rl.rps, rl.burst)
+			rl.hosts[host] = limiter
+		}
+		rl.Unlock()
+	}
+
+	now := clock.Now()
+	r := limiter.ReserveN(now, 1)
+	if !r.OK() {
+		return fmt.Errorf("rate limit: burst %d exceeded", rl.burst)
+	}
+
+	delay := r.DelayFrom(now)
+	if delay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-clock.After(delay):
+		return nil
+	case <-ctx.Done():
+		r.CancelAt(clock.Now())
+		return ctx.Err()
+	}
+}
+
+func withRateLimit(cat *Protocol, rl *rateLimiter) error {
+	cat.limiter = rl
+	return nil
+}
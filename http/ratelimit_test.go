@@ -0,0 +1,56 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestRateLimit(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	req := µ.GET(
+		ø.URI("%s/ok", ø.Authority(ts.URL)),
+		ƒ.Status.OK,
+	)
+
+	for name, opt := range map[string]µ.Option{
+		"Stack":   µ.WithRateLimit(20, 1),
+		"PerHost": µ.WithRateLimitPerHost(20, 1),
+	} {
+		t.Run(name, func(t *testing.T) {
+			cat := µ.New(opt)
+
+			t0 := time.Now()
+			err := cat.IO(context.Background(), req, req, req)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.True(time.Since(t0) >= 90*time.Millisecond),
+			)
+		})
+	}
+
+	t.Run("Cancel", func(t *testing.T) {
+		cat := µ.New(µ.WithRateLimit(0.1, 1))
+
+		c, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := cat.IO(c, req, req)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+}
@@ -10,9 +10,11 @@
 package recv
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"io"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -32,7 +34,11 @@ import (
 // received one. The execution fails StatusCode error if service responds
 // with other value then specified one.
 func Code(code ...http.StatusCode) http.Arrow {
-	return func(cat *http.Context) error {
+	return http.Annotated(func(cat *http.Context) error {
+		if cat.Annotate(http.Step{Kind: "recv.Code", Status: statusText(code...)}) {
+			return nil
+		}
+
 		if err := cat.Unsafe(); err != nil {
 			return err
 		}
@@ -41,282 +47,299 @@ func Code(code ...http.StatusCode) http.Arrow {
 		if !hasCode(code, status) {
 			return &gurl.NoMatch{
 				ID:       "http.Code",
-				Diff:     fmt.Sprintf("+ Status Code: %d\n- Status Code: %d", status, code[0]),
+				Diff:     fmt.Sprintf("+ Status Code: %d\n- Status Code: %s", status, code[0].Text()),
 				Protocol: "StatusCode",
 				Expect:   code[0],
 				Actual:   status,
 			}
 		}
 		return nil
+	})
+}
+
+func statusText(code ...http.StatusCode) []string {
+	seq := make([]string, len(code))
+	for i, c := range code {
+		seq[i] = c.Text()
 	}
+	return seq
 }
 
 func hasCode(s []http.StatusCode, e int) bool {
 	for _, a := range s {
-		if a.StatusCode() == e {
+		if a.Match(e) {
 			return true
 		}
 	}
 	return false
 }
 
-// StatusCode is a warpper type over http.StatusCode
+// StatusCode is a collection of arrows matching HTTP Status Code, it is
+// an alternative to
 //
 //	http.Join(
 //		...
 //		ƒ.Code(http.StatusOK),
 //	)
 //
-// so that response code is matched using constant
+// so that response code is matched using field
 //
 //	http.Join(
 //		...
 //		ƒ.Status.OK,
 //	)
-type StatusCode int
+type StatusCode struct {
+	// Success ⟼ any of 2xx status codes
+	Success http.Arrow
 
-// Status is collection of constants for HTTP Status Code checks
-//
-//	ƒ.Status.OK
-//	ƒ.Status.NotFound
-const Status = StatusCode(0)
+	// Redirection ⟼ any of 3xx status codes
+	Redirection http.Arrow
 
-func (StatusCode) eval(code http.StatusCode, cat *http.Context) error {
-	if err := cat.Unsafe(); err != nil {
-		return err
-	}
+	// ClientError ⟼ any of 4xx status codes
+	ClientError http.Arrow
 
-	status := cat.Response.StatusCode
-	if !hasCode([]http.StatusCode{code}, status) {
-		return &gurl.NoMatch{
-			ID:       "http.Code",
-			Diff:     fmt.Sprintf("+ Status Code: %d\n- Status Code: %d", status, code),
-			Protocol: "StatusCode",
-			Expect:   code,
-			Actual:   status,
-		}
-	}
+	// ServerError ⟼ any of 5xx status codes
+	ServerError http.Arrow
 
-	return nil
-}
+	/*
+		TODO:
+		Continue
+		SwitchingProtocols
+		Processing
+		EarlyHints
+	*/
 
-/*
-TODO:
-  Continue
-	SwitchingProtocols
-	Processing
-	EarlyHints
-*/
+	// OK ⟼ http.StatusOK
+	OK http.Arrow
 
-// OK ⟼ http.StatusOK
-func (code StatusCode) OK(cat *http.Context) error {
-	return code.eval(http.StatusOK, cat)
-}
+	// Created ⟼ http.StatusCreated
+	Created http.Arrow
 
-// Created ⟼ http.StatusCreated
-func (code StatusCode) Created(cat *http.Context) error {
-	return code.eval(http.StatusCreated, cat)
-}
+	// Accepted ⟼ http.StatusAccepted
+	Accepted http.Arrow
 
-// Accepted ⟼ http.StatusAccepted
-func (code StatusCode) Accepted(cat *http.Context) error {
-	return code.eval(http.StatusAccepted, cat)
-}
+	// NonAuthoritativeInfo ⟼ http.StatusNonAuthoritativeInfo
+	NonAuthoritativeInfo http.Arrow
 
-// NonAuthoritativeInfo ⟼ http.StatusNonAuthoritativeInfo
-func (code StatusCode) NonAuthoritativeInfo(cat *http.Context) error {
-	return code.eval(http.StatusNonAuthoritativeInfo, cat)
-}
+	// NoContent ⟼ http.StatusNoContent
+	NoContent http.Arrow
 
-// NoContent ⟼ http.StatusNoContent
-func (code StatusCode) NoContent(cat *http.Context) error {
-	return code.eval(http.StatusNoContent, cat)
-}
+	// ResetContent ⟼ http.StatusResetContent
+	ResetContent http.Arrow
 
-// ResetContent ⟼ http.StatusResetContent
-func (code StatusCode) ResetContent(cat *http.Context) error {
-	return code.eval(http.StatusResetContent, cat)
-}
+	/*
+		TODO:
+		PartialContent
+		MultiStatus
+		AlreadyReported
+		IMUsed
+	*/
 
-/*
-TODO:
-	PartialContent
-	MultiStatus
-	AlreadyReported
-	IMUsed
-*/
+	// MultipleChoices ⟼ http.StatusMultipleChoices
+	MultipleChoices http.Arrow
 
-// MultipleChoices ⟼ http.StatusMultipleChoices
-func (code StatusCode) MultipleChoices(cat *http.Context) error {
-	return code.eval(http.StatusMultipleChoices, cat)
-}
+	// MovedPermanently ⟼ http.StatusMovedPermanently
+	MovedPermanently http.Arrow
 
-// MovedPermanently ⟼ http.StatusMovedPermanently
-func (code StatusCode) MovedPermanently(cat *http.Context) error {
-	return code.eval(http.StatusMovedPermanently, cat)
-}
+	// Found ⟼ http.StatusFound
+	Found http.Arrow
 
-// Found ⟼ http.StatusFound
-func (code StatusCode) Found(cat *http.Context) error {
-	return code.eval(http.StatusFound, cat)
-}
+	// SeeOther ⟼ http.StatusSeeOther
+	SeeOther http.Arrow
 
-// SeeOther ⟼ http.StatusSeeOther
-func (code StatusCode) SeeOther(cat *http.Context) error {
-	return code.eval(http.StatusSeeOther, cat)
-}
+	// NotModified ⟼ http.StatusNotModified
+	NotModified http.Arrow
 
-// NotModified ⟼ http.StatusNotModified
-func (code StatusCode) NotModified(cat *http.Context) error {
-	return code.eval(http.StatusNotModified, cat)
-}
+	// UseProxy ⟼ http.StatusUseProxy
+	UseProxy http.Arrow
 
-// UseProxy ⟼ http.StatusUseProxy
-func (code StatusCode) UseProxy(cat *http.Context) error {
-	return code.eval(http.StatusUseProxy, cat)
-}
+	/*
+		TODO:
+		TemporaryRedirect
+		PermanentRedirect
+	*/
 
-/*
-TODO:
-	TemporaryRedirect
-	PermanentRedirect
-*/
+	// BadRequest ⟼ http.StatusBadRequest
+	BadRequest http.Arrow
 
-// BadRequest ⟼ http.StatusBadRequest
-func (code StatusCode) BadRequest(cat *http.Context) error {
-	return code.eval(http.StatusBadRequest, cat)
-}
+	// Unauthorized ⟼ http.StatusUnauthorized
+	Unauthorized http.Arrow
 
-// Unauthorized ⟼ http.StatusUnauthorized
-func (code StatusCode) Unauthorized(cat *http.Context) error {
-	return code.eval(http.StatusUnauthorized, cat)
-}
+	// PaymentRequired ⟼ http.StatusPaymentRequired
+	PaymentRequired http.Arrow
 
-// PaymentRequired ⟼ http.StatusPaymentRequired
-func (code StatusCode) PaymentRequired(cat *http.Context) error {
-	return code.eval(http.StatusPaymentRequired, cat)
-}
+	// Forbidden ⟼ http.StatusForbidden
+	Forbidden http.Arrow
 
-// Forbidden ⟼ http.StatusForbidden
-func (code StatusCode) Forbidden(cat *http.Context) error {
-	return code.eval(http.StatusForbidden, cat)
-}
+	// NotFound ⟼ http.StatusNotFound
+	NotFound http.Arrow
 
-// NotFound ⟼ http.StatusNotFound
-func (code StatusCode) NotFound(cat *http.Context) error {
-	return code.eval(http.StatusNotFound, cat)
-}
+	// MethodNotAllowed ⟼ http.StatusMethodNotAllowed
+	MethodNotAllowed http.Arrow
 
-// MethodNotAllowed ⟼ http.StatusMethodNotAllowed
-func (code StatusCode) MethodNotAllowed(cat *http.Context) error {
-	return code.eval(http.StatusMethodNotAllowed, cat)
-}
+	// NotAcceptable ⟼ http.StatusNotAcceptable
+	NotAcceptable http.Arrow
 
-// NotAcceptable ⟼ http.StatusNotAcceptable
-func (code StatusCode) NotAcceptable(cat *http.Context) error {
-	return code.eval(http.StatusNotAcceptable, cat)
-}
+	// ProxyAuthRequired ⟼ http.StatusProxyAuthRequired
+	ProxyAuthRequired http.Arrow
 
-// ProxyAuthRequired ⟼ http.StatusProxyAuthRequired
-func (code StatusCode) ProxyAuthRequired(cat *http.Context) error {
-	return code.eval(http.StatusProxyAuthRequired, cat)
-}
+	// RequestTimeout ⟼ http.StatusRequestTimeout
+	RequestTimeout http.Arrow
 
-// RequestTimeout ⟼ http.StatusRequestTimeout
-func (code StatusCode) RequestTimeout(cat *http.Context) error {
-	return code.eval(http.StatusRequestTimeout, cat)
-}
+	// Conflict ⟼ http.StatusConflict
+	Conflict http.Arrow
 
-// Conflict ⟼ http.StatusConflict
-func (code StatusCode) Conflict(cat *http.Context) error {
-	return code.eval(http.StatusConflict, cat)
-}
+	// Gone ⟼ http.StatusGone
+	Gone http.Arrow
 
-// Gone ⟼ http.StatusGone
-func (code StatusCode) Gone(cat *http.Context) error {
-	return code.eval(http.StatusGone, cat)
-}
+	// LengthRequired ⟼ http.StatusLengthRequired
+	LengthRequired http.Arrow
 
-// LengthRequired ⟼ http.StatusLengthRequired
-func (code StatusCode) LengthRequired(cat *http.Context) error {
-	return code.eval(http.StatusLengthRequired, cat)
-}
+	// PreconditionFailed ⟼ http.StatusPreconditionFailed
+	PreconditionFailed http.Arrow
 
-// PreconditionFailed ⟼ http.StatusPreconditionFailed
-func (code StatusCode) PreconditionFailed(cat *http.Context) error {
-	return code.eval(http.StatusPreconditionFailed, cat)
-}
+	// RequestEntityTooLarge ⟼ http.StatusRequestEntityTooLarge
+	RequestEntityTooLarge http.Arrow
 
-// RequestEntityTooLarge ⟼ http.StatusRequestEntityTooLarge
-func (code StatusCode) RequestEntityTooLarge(cat *http.Context) error {
-	return code.eval(http.StatusRequestEntityTooLarge, cat)
-}
+	// RequestURITooLong ⟼ http.StatusRequestURITooLong
+	RequestURITooLong http.Arrow
 
-// RequestURITooLong ⟼ http.StatusRequestURITooLong
-func (code StatusCode) RequestURITooLong(cat *http.Context) error {
-	return code.eval(http.StatusRequestURITooLong, cat)
-}
+	// UnsupportedMediaType ⟼ http.StatusUnsupportedMediaType
+	UnsupportedMediaType http.Arrow
 
-// UnsupportedMediaType ⟼ http.StatusUnsupportedMediaType
-func (code StatusCode) UnsupportedMediaType(cat *http.Context) error {
-	return code.eval(http.StatusUnsupportedMediaType, cat)
-}
+	/*
+		TODO:
+		RequestedRangeNotSatisfiable
+		ExpectationFailed
+		Teapot
+		MisdirectedRequest
+		UnprocessableEntity
+		Locked
+		FailedDependency
+		TooEarly
+		UpgradeRequired
+		PreconditionRequired
+		TooManyRequests
+		RequestHeaderFieldsTooLarge
+		UnavailableForLegalReasons
+	*/
 
-/*
-TODO:
-	RequestedRangeNotSatisfiable
-	ExpectationFailed
-	Teapot
-	MisdirectedRequest
-	UnprocessableEntity
-	Locked
-	FailedDependency
-	TooEarly
-	UpgradeRequired
-	PreconditionRequired
-	TooManyRequests
-	RequestHeaderFieldsTooLarge
-	UnavailableForLegalReasons
-*/
+	// InternalServerError ⟼ http.StatusInternalServerError
+	InternalServerError http.Arrow
 
-// InternalServerError ⟼ http.StatusInternalServerError
-func (code StatusCode) InternalServerError(cat *http.Context) error {
-	return code.eval(http.StatusInternalServerError, cat)
-}
+	// NotImplemented ⟼ http.StatusNotImplemented
+	NotImplemented http.Arrow
 
-// NotImplemented ⟼ http.StatusNotImplemented
-func (code StatusCode) NotImplemented(cat *http.Context) error {
-	return code.eval(http.StatusNotImplemented, cat)
-}
+	// BadGateway ⟼ http.StatusBadGateway
+	BadGateway http.Arrow
 
-// BadGateway ⟼ http.StatusBadGateway
-func (code StatusCode) BadGateway(cat *http.Context) error {
-	return code.eval(http.StatusBadGateway, cat)
-}
+	// ServiceUnavailable ⟼ http.StatusServiceUnavailable
+	ServiceUnavailable http.Arrow
 
-// ServiceUnavailable ⟼ http.StatusServiceUnavailable
-func (code StatusCode) ServiceUnavailable(cat *http.Context) error {
-	return code.eval(http.StatusServiceUnavailable, cat)
-}
+	// GatewayTimeout ⟼ http.StatusGatewayTimeout
+	GatewayTimeout http.Arrow
+
+	// HTTPVersionNotSupported ⟼ http.StatusHTTPVersionNotSupported
+	HTTPVersionNotSupported http.Arrow
 
-// GatewayTimeout ⟼ http.StatusGatewayTimeout
-func (code StatusCode) GatewayTimeout(cat *http.Context) error {
-	return code.eval(http.StatusGatewayTimeout, cat)
+	/*
+		TODO:
+		VariantAlsoNegotiates
+		InsufficientStorage
+		LoopDetected
+		NotExtended
+		NetworkAuthenticationRequired
+	*/
 }
 
-// HTTPVersionNotSupported ⟼ http.StatusHTTPVersionNotSupported
-func (code StatusCode) HTTPVersionNotSupported(cat *http.Context) error {
-	return code.eval(http.StatusHTTPVersionNotSupported, cat)
+// Status is collection of arrows for HTTP Status Code checks
+//
+//	ƒ.Status.OK
+//	ƒ.Status.NotFound
+var Status = StatusCode{
+	Success:                 status(http.StatusClass2xx),
+	Redirection:             status(http.StatusClass3xx),
+	ClientError:             status(http.StatusClass4xx),
+	ServerError:             status(http.StatusClass5xx),
+	OK:                      status(http.StatusOK),
+	Created:                 status(http.StatusCreated),
+	Accepted:                status(http.StatusAccepted),
+	NonAuthoritativeInfo:    status(http.StatusNonAuthoritativeInfo),
+	NoContent:               status(http.StatusNoContent),
+	ResetContent:            status(http.StatusResetContent),
+	MultipleChoices:         status(http.StatusMultipleChoices),
+	MovedPermanently:        status(http.StatusMovedPermanently),
+	Found:                   status(http.StatusFound),
+	SeeOther:                status(http.StatusSeeOther),
+	NotModified:             status(http.StatusNotModified),
+	UseProxy:                status(http.StatusUseProxy),
+	BadRequest:              status(http.StatusBadRequest),
+	Unauthorized:            status(http.StatusUnauthorized),
+	PaymentRequired:         status(http.StatusPaymentRequired),
+	Forbidden:               status(http.StatusForbidden),
+	NotFound:                status(http.StatusNotFound),
+	MethodNotAllowed:        status(http.StatusMethodNotAllowed),
+	NotAcceptable:           status(http.StatusNotAcceptable),
+	ProxyAuthRequired:       status(http.StatusProxyAuthRequired),
+	RequestTimeout:          status(http.StatusRequestTimeout),
+	Conflict:                status(http.StatusConflict),
+	Gone:                    status(http.StatusGone),
+	LengthRequired:          status(http.StatusLengthRequired),
+	PreconditionFailed:      status(http.StatusPreconditionFailed),
+	RequestEntityTooLarge:   status(http.StatusRequestEntityTooLarge),
+	RequestURITooLong:       status(http.StatusRequestURITooLong),
+	UnsupportedMediaType:    status(http.StatusUnsupportedMediaType),
+	InternalServerError:     status(http.StatusInternalServerError),
+	NotImplemented:          status(http.StatusNotImplemented),
+	BadGateway:              status(http.StatusBadGateway),
+	ServiceUnavailable:      status(http.StatusServiceUnavailable),
+	GatewayTimeout:          status(http.StatusGatewayTimeout),
+	HTTPVersionNotSupported: status(http.StatusHTTPVersionNotSupported),
+}
+
+func status(code http.StatusCode) http.Arrow {
+	return http.Annotated(func(cat *http.Context) error {
+		if cat.Annotate(http.Step{Kind: "recv.Status", Status: statusText(code)}) {
+			return nil
+		}
+
+		if err := cat.Unsafe(); err != nil {
+			return err
+		}
+
+		status := cat.Response.StatusCode
+		if !hasCode([]http.StatusCode{code}, status) {
+			return &gurl.NoMatch{
+				ID:       "http.Code",
+				Diff:     fmt.Sprintf("+ Status Code: %d\n- Status Code: %s", status, code.Text()),
+				Protocol: "StatusCode",
+				Expect:   code,
+				Actual:   status,
+			}
+		}
+
+		return nil
+	})
 }
 
-/*
-TODO:
-	VariantAlsoNegotiates
-	InsufficientStorage
-	LoopDetected
-	NotExtended
-	NetworkAuthenticationRequired
-*/
+// To lifts status code of response into variable, it does not fail on
+// any status code, enabling branching logic after the evaluation.
+//
+//	var code int
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		ƒ.Status.To(&code),
+//	)
+func (StatusCode) To(code *int) http.Arrow {
+	return func(cat *http.Context) error {
+		if err := cat.Unsafe(); err != nil {
+			return err
+		}
+
+		*code = cat.Response.StatusCode
+		return nil
+	}
+}
 
 // helper function to match HTTP header to value
 func match(ctx *http.Context, header string, value string) error {
@@ -397,6 +420,72 @@ func liftTime(ctx *http.Context, header string, value *time.Time) error {
 	return nil
 }
 
+// helper function to lift header value to duration. The value is either
+// delta-seconds (Retry-After: 120), HTTP date (Retry-After: <date>),
+// parameter "timeout" (Keep-Alive: timeout=5, max=100) or Golang's duration
+// (X-Timeout: 1.5s). Fractional delta-seconds (Retry-After: 1.5) are rejected.
+func liftDuration(ctx *http.Context, header string, value *time.Duration) error {
+	val := ctx.Response.Header.Get(string(header))
+	if val == "" {
+		return &gurl.NoMatch{
+			ID:       "http.Header",
+			Diff:     fmt.Sprintf("- %s: *", string(header)),
+			Protocol: header,
+		}
+	}
+
+	d, err := parseDuration(val, ctx.Clock().Now())
+	if err != nil {
+		return err
+	}
+
+	*value = d
+	return nil
+}
+
+func parseDuration(val string, now time.Time) (time.Duration, error) {
+	for _, param := range strings.Split(val, ",") {
+		if k, v, has := strings.Cut(strings.TrimSpace(param), "="); has && strings.EqualFold(k, "timeout") {
+			val = v
+		}
+	}
+
+	val = strings.TrimSpace(val)
+
+	if sec, err := strconv.Atoi(val); err == nil {
+		return time.Duration(sec) * time.Second, nil
+	}
+
+	if _, err := strconv.ParseFloat(val, 64); err == nil {
+		return 0, fmt.Errorf("delta-seconds %s is not whole seconds", val)
+	}
+
+	if t, err := time.Parse(time.RFC1123, val); err == nil {
+		return t.Sub(now), nil
+	}
+
+	return time.ParseDuration(val)
+}
+
+func matchDuration(ctx *http.Context, header string, value time.Duration) error {
+	var val time.Duration
+	if err := liftDuration(ctx, header, &val); err != nil {
+		return err
+	}
+
+	if val != value {
+		return &gurl.NoMatch{
+			ID:       "http.Header",
+			Diff:     fmt.Sprintf("+ %s: %s\n- %s: %s", header, val, header, value),
+			Protocol: header,
+			Expect:   value,
+			Actual:   val,
+		}
+	}
+
+	return nil
+}
+
 // Header matches or lifts header value
 func Header[T http.MatchableHeaderValues](header string, value T) http.Arrow {
 	switch v := any(value).(type) {
@@ -406,12 +495,16 @@ func Header[T http.MatchableHeaderValues](header string, value T) http.Arrow {
 		return HeaderOf[int](header).Is(v)
 	case time.Time:
 		return HeaderOf[time.Time](header).Is(v)
+	case time.Duration:
+		return HeaderOf[time.Duration](header).Is(v)
 	case *string:
 		return HeaderOf[string](header).To(v)
 	case *int:
 		return HeaderOf[int](header).To(v)
 	case *time.Time:
 		return HeaderOf[time.Time](header).To(v)
+	case *time.Duration:
+		return HeaderOf[time.Duration](header).To(v)
 	default:
 		panic("invalid type")
 	}
@@ -447,6 +540,10 @@ func (h HeaderOf[T]) Is(value T) http.Arrow {
 		return func(ctx *http.Context) error {
 			return match(ctx, string(h), v.UTC().Format(time.RFC1123))
 		}
+	case time.Duration:
+		return func(ctx *http.Context) error {
+			return matchDuration(ctx, string(h), v)
+		}
 	default:
 		panic("invalid type")
 	}
@@ -467,11 +564,139 @@ func (h HeaderOf[T]) To(value *T) http.Arrow {
 		return func(ctx *http.Context) error {
 			return liftTime(ctx, string(h), v)
 		}
+	case *time.Duration:
+		return func(ctx *http.Context) error {
+			return liftDuration(ctx, string(h), v)
+		}
 	default:
 		panic("invalid type")
 	}
 }
 
+// Lifts all values of multi-valued HTTP header (e.g. Set-Cookie, Link) to
+// the sequence. It fails if header do not exists.
+//
+//	var links []string
+//	ƒ.HeaderOf[string]("Link").ToSeq(&links)
+func (h HeaderOf[T]) ToSeq(value *[]T) http.Arrow {
+	return func(ctx *http.Context) error {
+		vals := ctx.Response.Header.Values(string(h))
+		if len(vals) == 0 {
+			return &gurl.NoMatch{
+				ID:       "http.Header",
+				Diff:     fmt.Sprintf("- %s: *", string(h)),
+				Protocol: string(h),
+			}
+		}
+
+		seq := make([]T, 0, len(vals))
+		for _, val := range vals {
+			x, err := headerValueOf[T](val, ctx.Clock().Now())
+			if err != nil {
+				return err
+			}
+			seq = append(seq, x)
+		}
+
+		*value = seq
+		return nil
+	}
+}
+
+func headerValueOf[T http.ReadableHeaderValues](val string, now time.Time) (T, error) {
+	var x T
+	switch v := any(&x).(type) {
+	case *string:
+		*v = val
+	case *int:
+		num, err := strconv.Atoi(val)
+		if err != nil {
+			return x, err
+		}
+		*v = num
+	case *time.Time:
+		t, err := time.Parse(time.RFC1123, val)
+		if err != nil {
+			return x, err
+		}
+		*v = t
+	case *time.Duration:
+		d, err := parseDuration(val, now)
+		if err != nil {
+			return x, err
+		}
+		*v = d
+	}
+	return x, nil
+}
+
+// Lifts value of HTTP header to the promise. It fails if header do not exists
+func (h HeaderOf[T]) Fulfill(value *http.Promise[T]) http.Arrow {
+	return func(ctx *http.Context) error {
+		var val T
+		if err := h.To(&val)(ctx); err != nil {
+			return err
+		}
+
+		value.Fulfill(val)
+		return nil
+	}
+}
+
+// Matches value of HTTP header against the regular expression
+//
+//	ƒ.HeaderOf[string]("X-Request-Id").Match(regexp.MustCompile(`^[0-9a-f-]{36}$`))
+func (h HeaderOf[T]) Match(re *regexp.Regexp) http.Arrow {
+	return func(ctx *http.Context) error {
+		var val string
+		if err := liftString(ctx, string(h), &val); err != nil {
+			return err
+		}
+
+		if !re.MatchString(val) {
+			return &gurl.NoMatch{
+				ID:       "http.Header",
+				Diff:     fmt.Sprintf("+ %s: %s\n- %s: /%s/", string(h), val, string(h), re.String()),
+				Protocol: string(h),
+				Expect:   re.String(),
+				Actual:   val,
+			}
+		}
+
+		return nil
+	}
+}
+
+// Checks value of HTTP header with the predicate, the value is lifted to
+// the type of header. The predicate returns error if value is not expected.
+//
+//	ƒ.HeaderOf[int]("X-RateLimit-Remaining").Check(func(n int) error {
+//		if n < 10 {
+//			return fmt.Errorf("limit is about to exceed")
+//		}
+//		return nil
+//	})
+func (h HeaderOf[T]) Check(f func(T) error) http.Arrow {
+	return func(ctx *http.Context) error {
+		var val T
+		if err := h.To(&val)(ctx); err != nil {
+			return err
+		}
+
+		if err := f(val); err != nil {
+			return &gurl.NoMatch{
+				ID:       "http.Header",
+				Diff:     fmt.Sprintf("+ %s: %v\n- %s: %s", string(h), val, string(h), err),
+				Protocol: string(h),
+				Expect:   err.Error(),
+				Actual:   val,
+			}
+		}
+
+		return nil
+	}
+}
+
 // Type of HTTP Header, Content-Type enumeration
 //
 //	const ContentType = HeaderEnumContent("Content-Type")
@@ -512,6 +737,31 @@ func (h HeaderEnumContent) Form(ctx *http.Context) error {
 	return match(ctx, string(h), "application/x-www-form-urlencoded")
 }
 
+// XML defined Header `???: application/xml`
+func (h HeaderEnumContent) XML(ctx *http.Context) error {
+	return match(ctx, string(h), "application/xml")
+}
+
+// TextXML defined Header `???: text/xml`
+func (h HeaderEnumContent) TextXML(ctx *http.Context) error {
+	return match(ctx, string(h), "text/xml")
+}
+
+// Protobuf defined Header `???: application/protobuf`
+func (h HeaderEnumContent) Protobuf(ctx *http.Context) error {
+	return match(ctx, string(h), "application/protobuf")
+}
+
+// MsgPack defined Header `???: application/msgpack`
+func (h HeaderEnumContent) MsgPack(ctx *http.Context) error {
+	return match(ctx, string(h), "application/msgpack")
+}
+
+// CBOR defined Header `???: application/cbor`
+func (h HeaderEnumContent) CBOR(ctx *http.Context) error {
+	return match(ctx, string(h), "application/cbor")
+}
+
 // TextPlain defined Header `???: text/plain`
 func (h HeaderEnumContent) TextPlain(ctx *http.Context) error {
 	return match(ctx, string(h), "text/plain")
@@ -605,32 +855,35 @@ func (h HeaderEnumTransferEncoding) Identity(ctx *http.Context) error {
 // List of supported HTTP header constants
 // https://en.wikipedia.org/wiki/List_of_HTTP_header_fields#Response_fields
 const (
-	Age              = HeaderOf[int]("Age")
-	CacheControl     = HeaderOf[string]("Cache-Control")
-	Connection       = HeaderEnumConnection("Connection")
-	ContentEncoding  = HeaderOf[string]("Content-Encoding")
-	ContentLanguage  = HeaderOf[string]("Content-Language")
-	ContentLength    = HeaderOf[int]("Content-Length")
-	ContentLocation  = HeaderOf[string]("Content-Location")
-	ContentMD5       = HeaderOf[string]("Content-MD5")
-	ContentRange     = HeaderOf[string]("Content-Range")
-	ContentType      = HeaderEnumContent("Content-Type")
-	Date             = HeaderOf[time.Time]("Date")
-	ETag             = HeaderOf[string]("ETag")
-	Expires          = HeaderOf[time.Time]("Expires")
-	LastModified     = HeaderOf[time.Time]("Last-Modified")
-	Link             = HeaderOf[string]("Link")
-	Location         = HeaderOf[string]("Location")
-	RetryAfter       = HeaderOf[time.Time]("Retry-After")
-	Server           = HeaderOf[string]("Server")
-	SetCookie        = HeaderOf[string]("Set-Cookie")
-	TransferEncoding = HeaderEnumTransferEncoding("Transfer-Encoding")
-	Via              = HeaderOf[string]("Via")
+	AccessControlMaxAge = HeaderOf[time.Duration]("Access-Control-Max-Age")
+	Age                 = HeaderOf[int]("Age")
+	CacheControl        = HeaderOf[string]("Cache-Control")
+	Connection          = HeaderEnumConnection("Connection")
+	ContentEncoding     = HeaderOf[string]("Content-Encoding")
+	ContentLanguage     = HeaderOf[string]("Content-Language")
+	ContentLength       = HeaderOf[int]("Content-Length")
+	ContentLocation     = HeaderOf[string]("Content-Location")
+	ContentMD5          = HeaderOf[string]("Content-MD5")
+	ContentRange        = HeaderOf[string]("Content-Range")
+	ContentType         = HeaderEnumContent("Content-Type")
+	Date                = HeaderOf[time.Time]("Date")
+	ETag                = HeaderOf[string]("ETag")
+	Expires             = HeaderOf[time.Time]("Expires")
+	KeepAlive           = HeaderOf[time.Duration]("Keep-Alive")
+	LastModified        = HeaderOf[time.Time]("Last-Modified")
+	Link                = HeaderOf[string]("Link")
+	Location            = HeaderOf[string]("Location")
+	RetryAfter          = HeaderOf[time.Time]("Retry-After")
+	Server              = HeaderOf[string]("Server")
+	SetCookie           = HeaderOf[string]("Set-Cookie")
+	TransferEncoding    = HeaderEnumTransferEncoding("Transfer-Encoding")
+	Via                 = HeaderOf[string]("Via")
 )
 
 // Body applies auto decoders for response and returns either binary or
 // native Go data structure. The Content-Type header give a hint to decoder.
-// Supply the pointer to data target data structure.
+// Supply the pointer to data target data structure. Types implementing
+// gurl.Decodable decode the payload on their own.
 func Body[T any](out *T) http.Arrow {
 	return func(cat *http.Context) error {
 		err := http.HintedContentCodec(
@@ -644,6 +897,20 @@ func Body[T any](out *T) http.Arrow {
 	}
 }
 
+// Fulfill decodes response payload into the promise, making it available
+// for sub-sequent arrows.
+func Fulfill[T any](promise *http.Promise[T]) http.Arrow {
+	return func(cat *http.Context) error {
+		var val T
+		if err := Body(&val)(cat); err != nil {
+			return err
+		}
+
+		promise.Fulfill(val)
+		return nil
+	}
+}
+
 // Recv is alias for Body, maintained only for compatibility
 func Recv[T any](out *T) http.Arrow {
 	return Body(out)
@@ -678,35 +945,103 @@ func Expect[T any](expect T) http.Arrow {
 
 // Bytes receive raw binary from HTTP response
 func Bytes(w io.Writer) http.Arrow {
-	return func(cat *http.Context) (err error) {
-		var n int
-		buf := make([]byte, 64*1024) // 64KB is size of chunk to be processed once
-		for {
-			n, err = cat.Response.Body.Read(buf)
-			if err == io.EOF {
-				err = nil
-				// There may be one last chunk to receive before breaking the loop.
-				if n <= 0 {
-					break
-				}
-			}
-			if err != nil {
-				break
-			}
+	return func(cat *http.Context) error {
+		return copyBody(cat, cat.Response.Body, w)
+	}
+}
 
-			_, err = w.Write(buf[:n])
-			if err != nil {
+// Text receive text from HTTP response, the payload is transcoded into
+// UTF-8 if Content-Type declares other charset.
+func Text(w io.Writer) http.Arrow {
+	return func(cat *http.Context) error {
+		body := http.CharsetReader(cat.Response.Header.Get("Content-Type"), cat.Response.Body)
+		return copyBody(cat, body, w)
+	}
+}
+
+func copyBody(cat *http.Context, body io.Reader, w io.Writer) (err error) {
+	var n int
+	buf := make([]byte, 64*1024) // 64KB is size of chunk to be processed once
+	for {
+		n, err = body.Read(buf)
+		if err == io.EOF {
+			err = nil
+			// There may be one last chunk to receive before breaking the loop.
+			if n <= 0 {
 				break
 			}
 		}
+		if err != nil {
+			break
+		}
+
+		_, err = w.Write(buf[:n])
+		if err != nil {
+			break
+		}
+	}
 
+	cat.Response.Body.Close()
+	cat.Response = nil
+	return
+}
+
+// Stream hands the raw response stream to the callback. The payload is
+// consumed incrementally, it is not buffered in memory.
+func Stream(f func(io.Reader) error) http.Arrow {
+	return func(cat *http.Context) error {
+		err := f(cat.Response.Body)
 		cat.Response.Body.Close()
 		cat.Response = nil
-		return
+		return err
 	}
 }
 
-// Match received payload to defined pattern
+// StreamLines hands the response stream to the callback line-by-line
+// (e.g. NDJSON feeds). The payload is not buffered in memory.
+func StreamLines(f func(string) error) http.Arrow {
+	return Stream(func(r io.Reader) error {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			if err := f(scanner.Text()); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	})
+}
+
+// TrimPrefix strips the prefix (e.g. custom XSSI guard) from the response
+// payload before it is decoded or matched by sub-sequent arrows. The UTF-8
+// BOM is always stripped.
+//
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		ƒ.Status.OK,
+//		ƒ.TrimPrefix("throw 1; < don't be evil' >"),
+//		ƒ.Body(&data),
+//	)
+func TrimPrefix(prefix ...string) http.Arrow {
+	return func(cat *http.Context) error {
+		cat.Response.Body = struct {
+			io.Reader
+			io.Closer
+		}{
+			Reader: http.PrefixReader(cat.Response.Body, prefix...),
+			Closer: cat.Response.Body,
+		}
+		return nil
+	}
+}
+
+// Match received payload to defined pattern. The pattern is any JSON value,
+// including arrays and scalars at root. The "_" matches any value, the "..."
+// element of array matches any remaining items.
+//
+//	ƒ.Match(`{"id": "_", "tags": ["a", "..."]}`)
+//	ƒ.Match(`[{"id": 1}, "..."]`)
+//	ƒ.Match(`"text"`)
 func Match(val string) http.Arrow {
 	var pat any
 	if err := json.Unmarshal([]byte(val), &pat); err != nil {
@@ -766,15 +1101,7 @@ func equivVal(pat, val any) bool {
 		if !ok {
 			return false
 		}
-		if len(pp) != len(vv) {
-			return false
-		}
-		for i, vvx := range vv {
-			if !equivVal(pp[i], vvx) {
-				return false
-			}
-		}
-		return true
+		return equivSeq(pp, vv)
 	case map[string]any:
 		pp, ok := pat.(map[string]any)
 		if !ok {
@@ -786,6 +1113,21 @@ func equivVal(pat, val any) bool {
 	return false
 }
 
+// matches sequence, the "..." element matches any remaining items
+func equivSeq(pat, val []any) bool {
+	for i, p := range pat {
+		if pp, ok := p.(string); ok && pp == "..." {
+			return true
+		}
+
+		if i >= len(val) || !equivVal(p, val[i]) {
+			return false
+		}
+	}
+
+	return len(pat) == len(val)
+}
+
 func equivMap(pat, val map[string]any) bool {
 	for k, p := range pat {
 		v, has := val[k]
@@ -13,20 +13,26 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"image"
 	_ "image/png"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/fogfish/gurl/v2"
 	µ "github.com/fogfish/gurl/v2/http"
 	iomock "github.com/fogfish/gurl/v2/http/mock"
 	ƒ "github.com/fogfish/gurl/v2/http/recv"
 	ø "github.com/fogfish/gurl/v2/http/send"
 	"github.com/fogfish/it/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func TestCodeOk(t *testing.T) {
@@ -69,6 +75,59 @@ func TestCodeNoMatch(t *testing.T) {
 	}
 }
 
+func TestStatusCodeClass(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	for code, check := range map[int][]µ.Arrow{
+		200: {ƒ.Status.Success, ƒ.Code(µ.StatusClass2xx)},
+		204: {ƒ.Status.Success},
+		301: {ƒ.Status.Redirection, ƒ.Code(µ.StatusClass3xx)},
+		404: {ƒ.Status.ClientError, ƒ.Code(µ.StatusClass4xx)},
+		429: {ƒ.Status.ClientError, ƒ.Code(µ.StatusOK, µ.StatusClass4xx)},
+		503: {ƒ.Status.ServerError, ƒ.Code(µ.StatusClass5xx)},
+	} {
+		for _, arrow := range check {
+			err := µ.New().IO(context.Background(),
+				µ.GET(
+					ø.URI("%s/code/%d", ø.Authority(ts.URL), code),
+					arrow,
+				),
+			)
+			it.Then(t).Should(it.Nil(err))
+		}
+	}
+
+	err := µ.New().IO(context.Background(),
+		µ.GET(
+			ø.URI("%s/code/%d", ø.Authority(ts.URL), 500),
+			ƒ.Status.Success,
+		),
+	)
+	it.Then(t).Should(
+		it.Equal(err.Error(), "+ Status Code: 500\n- Status Code: 2xx"),
+	)
+}
+
+func TestStatusCodeTo(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	for _, expect := range []int{200, 404, 503} {
+		var code int
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/code/%d", ø.Authority(ts.URL), expect),
+				ƒ.Status.To(&code),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(code, expect),
+		)
+	}
+}
+
 func TestStatusCodes(t *testing.T) {
 	ts := mock()
 	defer ts.Close()
@@ -195,6 +254,69 @@ func TestHeaderVal(t *testing.T) {
 	)
 }
 
+func TestHeaderDuration(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Keep-Alive", "timeout=5, max=100")
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.Header().Set("Retry-After", "120")
+			w.Header().Set("X-Timeout", "1m30s")
+			w.Header().Set("X-Delay", "1.5s")
+			w.Header().Set("X-Backoff", "1.5")
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer ts.Close()
+
+	var (
+		keepAlive time.Duration
+		maxAge    time.Duration
+		retry     time.Duration
+		timeout   time.Duration
+		delay     time.Duration
+	)
+	err := µ.New().IO(context.Background(),
+		µ.GET(
+			ø.URI(ts.URL),
+			ƒ.Status.OK,
+			ƒ.KeepAlive.To(&keepAlive),
+			ƒ.AccessControlMaxAge.To(&maxAge),
+			ƒ.HeaderOf[time.Duration]("Retry-After").To(&retry),
+			ƒ.Header("X-Timeout", &timeout),
+			ƒ.Header("X-Delay", &delay),
+			ƒ.KeepAlive.Is(5*time.Second),
+			ƒ.Header("X-Delay", 1500*time.Millisecond),
+			ƒ.Header("Retry-After", 2*time.Minute),
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(keepAlive, 5*time.Second),
+		it.Equal(maxAge, 10*time.Minute),
+		it.Equal(retry, 2*time.Minute),
+		it.Equal(timeout, 90*time.Second),
+		it.Equal(delay, 1500*time.Millisecond),
+	)
+
+	err = µ.New().IO(context.Background(),
+		µ.GET(
+			ø.URI(ts.URL),
+			ƒ.Status.OK,
+			ƒ.HeaderOf[time.Duration]("X-Backoff").To(&delay),
+		),
+	)
+	it.Then(t).ShouldNot(it.Nil(err))
+
+	err = µ.New().IO(context.Background(),
+		µ.GET(
+			ø.URI(ts.URL),
+			ƒ.Status.OK,
+			ƒ.AccessControlMaxAge.Is(time.Minute),
+		),
+	)
+	it.Then(t).ShouldNot(it.Nil(err))
+}
+
 func TestHeaderMismatch(t *testing.T) {
 	ts := mock()
 	defer ts.Close()
@@ -261,6 +383,84 @@ func TestHeaderUndefinedWithVal(t *testing.T) {
 	)
 }
 
+func TestHeaderMatchCheck(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	limit := func(n int) error {
+		if n > 1000 {
+			return fmt.Errorf("expected at most 1000")
+		}
+		return nil
+	}
+
+	for expect, header := range map[bool]µ.Arrow{
+		true:  ƒ.HeaderOf[string]("X-Value").Match(regexp.MustCompile(`^[0-9]+$`)),
+		false: ƒ.HeaderOf[string]("X-Value").Match(regexp.MustCompile(`^[a-z]+$`)),
+	} {
+		err := µ.New().IO(context.Background(),
+			µ.GET(ø.URI("%s/json", ø.Authority(ts.URL)), ƒ.Status.OK, header),
+		)
+		it.Then(t).Should(it.Equal(err == nil, expect))
+	}
+
+	err := µ.New().IO(context.Background(),
+		µ.GET(
+			ø.URI("%s/json", ø.Authority(ts.URL)),
+			ƒ.Status.OK,
+			ƒ.HeaderOf[int]("X-Value").Check(limit),
+		),
+	)
+
+	var e *gurl.NoMatch
+	it.Then(t).Should(
+		it.True(errors.As(err, &e)),
+		it.Equal(e.Expect.(string), "expected at most 1000"),
+		it.Equal(e.Actual.(int), 1024),
+	)
+}
+
+func TestHeaderToSeq(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Link", `</a>; rel="next"`)
+			w.Header().Add("Link", `</b>; rel="last"`)
+			w.Header().Add("X-Seq", "1")
+			w.Header().Add("X-Seq", "2")
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer ts.Close()
+
+	var (
+		links []string
+		seq   []int
+		none  []string
+	)
+	err := µ.New().IO(context.Background(),
+		µ.GET(
+			ø.URI(ts.URL),
+			ƒ.Status.OK,
+			ƒ.HeaderOf[string]("Link").ToSeq(&links),
+			ƒ.HeaderOf[int]("X-Seq").ToSeq(&seq),
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Seq(links).Equal(`</a>; rel="next"`, `</b>; rel="last"`),
+		it.Seq(seq).Equal(1, 2),
+	)
+
+	err = µ.New().IO(context.Background(),
+		µ.GET(
+			ø.URI(ts.URL),
+			ƒ.Status.OK,
+			ƒ.HeaderOf[string]("X-None").ToSeq(&none),
+		),
+	)
+	it.Then(t).ShouldNot(it.Nil(err))
+}
+
 func TestBodyJSON(t *testing.T) {
 	type Site struct {
 		Site string `json:"site"`
@@ -316,6 +516,30 @@ func TestBodyForm(t *testing.T) {
 	)
 }
 
+func TestBodyXML(t *testing.T) {
+	type Site struct {
+		Site string `xml:"name"`
+	}
+
+	ts := mock()
+	defer ts.Close()
+
+	var site Site
+	req := µ.GET(
+		ø.URI("%s/xml", ø.Authority(ts.URL)),
+		ƒ.Status.OK,
+		ƒ.ContentType.XML,
+		ƒ.Body(&site),
+	)
+	cat := µ.New()
+	err := cat.IO(context.Background(), req)
+
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(site.Site, "example.com"),
+	)
+}
+
 func TestBodyImage(t *testing.T) {
 	ts := mock()
 	defer ts.Close()
@@ -381,6 +605,192 @@ func TestExpectJSONFailed(t *testing.T) {
 	)
 }
 
+func TestExpectSubset(t *testing.T) {
+	type Site struct {
+		Site string `json:"site"`
+		Host string `json:"host"`
+	}
+
+	type Match struct {
+		A string   `json:"a"`
+		B int      `json:"b"`
+		D []string `json:"d"`
+	}
+
+	ts := mock()
+	defer ts.Close()
+
+	for _, arrow := range []µ.Arrow{
+		ƒ.ExpectSubset(Site{Site: "example.com"}),
+		ƒ.ExpectSubset(map[string]any{"site": "example.com"}),
+		ƒ.ExpectSubset(map[string]any{}),
+	} {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				arrow,
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+	}
+
+	for _, arrow := range []µ.Arrow{
+		ƒ.ExpectSubset(Match{A: "a", D: []string{"a", "b", "c"}}),
+		ƒ.ExpectSubset(map[string]any{"b": 101.0, "e": map[string]any{}}),
+		ƒ.ExpectSubset(map[string]any{"b": 101}),
+		ƒ.ExpectSubset(map[string]any{"b": int64(101)}),
+	} {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/match", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				arrow,
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+	}
+
+	for _, arrow := range []µ.Arrow{
+		ƒ.ExpectSubset(Site{Site: "some.com"}),
+		ƒ.ExpectSubset(Site{Host: "example.com"}),
+		ƒ.ExpectSubset(map[string]any{"host": "example.com"}),
+		ƒ.ExpectSubset(map[string]any{"site": 1.0}),
+		ƒ.ExpectSubset(map[string]any{"site": 1}),
+	} {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				arrow,
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.As(err, new(*gurl.NoMatch))),
+		)
+	}
+}
+
+type Pair struct{ Key, Val string }
+
+func (p *Pair) DecodeBody(content string, r io.Reader) error {
+	if content != "text/plain" {
+		return errors.New("unsupported content")
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	p.Key, p.Val, _ = strings.Cut(string(b), "=")
+	return nil
+}
+
+func TestRecvDecodable(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	var pair Pair
+	err := µ.New().IO(context.Background(),
+		µ.GET(
+			ø.URI("%s/text", ø.Authority(ts.URL)),
+			ƒ.Status.OK,
+			ƒ.Body(&pair),
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(pair.Key, "site"),
+		it.Equal(pair.Val, "example.com"),
+	)
+
+	err = µ.New().IO(context.Background(),
+		µ.GET(
+			ø.URI("%s/json", ø.Authority(ts.URL)),
+			ƒ.Status.OK,
+			ƒ.Body(&pair),
+		),
+	)
+	it.Then(t).ShouldNot(it.Nil(err))
+}
+
+func TestRecvProtobuf(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	var val wrapperspb.StringValue
+	err := µ.New().IO(context.Background(),
+		µ.GET(
+			ø.URI("%s/protobuf", ø.Authority(ts.URL)),
+			ƒ.Status.OK,
+			ƒ.ContentType.Is("application/x-protobuf"),
+			ƒ.Body(&val),
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(val.Value, "example.com"),
+	)
+
+	var site struct {
+		Site string `json:"site"`
+	}
+	err = µ.New().IO(context.Background(),
+		µ.GET(
+			ø.URI("%s/protobuf", ø.Authority(ts.URL)),
+			ƒ.Status.OK,
+			ƒ.Body(&site),
+		),
+	)
+	it.Then(t).ShouldNot(it.Nil(err))
+}
+
+func TestRecvDecodeError(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	t.Run("Syntax", func(t *testing.T) {
+		var site struct {
+			Site string `json:"site"`
+		}
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/malformed", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Body(&site),
+			),
+		)
+
+		var e *gurl.DecodeError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Equal(e.ContentType, "application/json"),
+			it.Equal(e.Offset, 10),
+			it.Equal(string(e.Snapshot), `{"site": example.com}`),
+		)
+	})
+
+	t.Run("Type", func(t *testing.T) {
+		var site struct {
+			Site int `json:"site"`
+		}
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Body(&site),
+			),
+		)
+
+		var e *gurl.DecodeError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.True(e.Offset > 0),
+		)
+	})
+}
+
 func TestRecvBytes(t *testing.T) {
 	opts := iomock.Preset(
 		iomock.Status(http.StatusOK),
@@ -509,6 +919,56 @@ func TestMatch(t *testing.T) {
 			)
 		}
 	})
+
+	t.Run("Root", func(t *testing.T) {
+		for path, pat := range map[string][]string{
+			"array": {
+				`[{"a":1}, {"a":2}, {"a":3}]`,
+				`["_", "_", "_"]`,
+				`[{"a":1}, "..."]`,
+				`["..."]`,
+				`[{"a":1}, {"a":2}, {"a":3}, "..."]`,
+				`"_"`,
+			},
+			"text":   {`"text"`, `"_"`},
+			"number": {`100`, `"_"`},
+		} {
+			for _, p := range pat {
+				err := µ.New().IO(context.Background(),
+					µ.GET(
+						ø.URI("%s/root/%s", ø.Authority(ts.URL), ø.Path(path)),
+						ƒ.Status.OK,
+						ƒ.Match(p),
+					),
+				)
+				it.Then(t).Should(it.Nil(err))
+			}
+		}
+	})
+
+	t.Run("RootNoMatch", func(t *testing.T) {
+		for path, pat := range map[string][]string{
+			"array": {
+				`[{"a":1}, {"a":2}]`,
+				`[{"a":2}, "..."]`,
+				`["_", "_", "_", "_", "..."]`,
+				`{"a":1}`,
+			},
+			"text":   {`"txt"`, `100`, `["..."]`},
+			"number": {`101`, `"100"`},
+		} {
+			for _, p := range pat {
+				err := µ.New().IO(context.Background(),
+					µ.GET(
+						ø.URI("%s/root/%s", ø.Authority(ts.URL), ø.Path(path)),
+						ƒ.Status.OK,
+						ƒ.Match(p),
+					),
+				)
+				it.Then(t).ShouldNot(it.Nil(err))
+			}
+		}
+	})
 }
 
 func mock() *httptest.Server {
@@ -523,6 +983,9 @@ func mock() *httptest.Server {
 			case strings.HasPrefix(r.URL.Path, "/form"):
 				w.Header().Add("Content-Type", "application/x-www-form-urlencoded")
 				w.Write([]byte("site=example.com"))
+			case strings.HasPrefix(r.URL.Path, "/xml"):
+				w.Header().Add("Content-Type", "application/xml")
+				w.Write([]byte("<site><name>example.com</name></site>"))
 			case strings.HasPrefix(r.URL.Path, "/image"):
 				w.Header().Add("Content-Type", "image/png")
 				dst, err := base64.StdEncoding.DecodeString("iVBORw0KGgoAAAANSUhEUgAAAAEAAAABAQMAAAAl21bKAAAAA1BMVEUAAACnej3aAAAAAXRSTlMAQObYZgAAAApJREFUCNdjYAAAAAIAAeIhvDMAAAAASUVORK5CYII=")
@@ -545,6 +1008,22 @@ func mock() *httptest.Server {
 			case r.URL.Path == "/code/303":
 				w.Header().Add("Location", "http://127.1")
 				w.WriteHeader(303)
+			case r.URL.Path == "/root/array":
+				w.Header().Add("Content-Type", "application/json")
+				w.Write([]byte(`[{"a":1}, {"a":2}, {"a":3}]`))
+			case r.URL.Path == "/root/text":
+				w.Header().Add("Content-Type", "application/json")
+				w.Write([]byte(`"text"`))
+			case r.URL.Path == "/protobuf":
+				bin, _ := proto.Marshal(wrapperspb.String("example.com"))
+				w.Header().Add("Content-Type", "application/x-protobuf")
+				w.Write(bin)
+			case r.URL.Path == "/malformed":
+				w.Header().Add("Content-Type", "application/json")
+				w.Write([]byte(`{"site": example.com}`))
+			case r.URL.Path == "/root/number":
+				w.Header().Add("Content-Type", "application/json")
+				w.Write([]byte(`100`))
 			case strings.HasPrefix(r.URL.Path, "/match"):
 				w.Header().Add("Content-Type", "application/json")
 				w.Write([]byte(`{"a":"a", "b":101, "c":1.1, "d":["a", "b", "c"], "e": {"a":"a", "b":101, "c":1.1}, "f": true}`))
@@ -587,3 +1066,65 @@ func TestTry(t *testing.T) {
 		)
 	}
 }
+
+func TestStream(t *testing.T) {
+	opts := iomock.Preset(
+		iomock.Status(http.StatusOK),
+		iomock.Header("Content-Type", "application/x-ndjson"),
+		iomock.Body([]byte("{\"a\":1}\n{\"a\":2}\n{\"a\":3}")),
+	)
+
+	t.Run("Stream", func(t *testing.T) {
+		data := &bytes.Buffer{}
+		req := µ.GET(
+			ø.URI("http://example.com/test"),
+			ƒ.Status.OK,
+			ƒ.Stream(func(r io.Reader) error {
+				_, err := io.Copy(data, r)
+				return err
+			}),
+		)
+		cat := µ.New(iomock.New(opts))
+		err := cat.IO(context.Background(), req)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(data.String(), "{\"a\":1}\n{\"a\":2}\n{\"a\":3}"),
+		)
+	})
+
+	t.Run("StreamLines", func(t *testing.T) {
+		seq := []string{}
+		req := µ.GET(
+			ø.URI("http://example.com/test"),
+			ƒ.Status.OK,
+			ƒ.StreamLines(func(s string) error {
+				seq = append(seq, s)
+				return nil
+			}),
+		)
+		cat := µ.New(iomock.New(opts))
+		err := cat.IO(context.Background(), req)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal(`{"a":1}`, `{"a":2}`, `{"a":3}`),
+		)
+	})
+
+	t.Run("StreamFail", func(t *testing.T) {
+		req := µ.GET(
+			ø.URI("http://example.com/test"),
+			ƒ.Status.OK,
+			ƒ.StreamLines(func(s string) error {
+				return errors.New("fail")
+			}),
+		)
+		cat := µ.New(iomock.New(opts))
+		err := cat.IO(context.Background(), req)
+
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+	})
+}
@@ -0,0 +1,99 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+)
+
+//
+// The file implements assertions of response compression
+//
+
+// Compressed matches Content-Encoding of response with one of encodings.
+// Declare accepted encodings explicitly (e.g. ø.AcceptEncodings.Gzip), the
+// transport of Golang strips the header of implicitly negotiated gzip.
+//
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		ø.AcceptEncoding.Set("gzip, br"),
+//		ƒ.Status.OK,
+//		ƒ.Compressed("gzip", "br"),
+//	)
+func Compressed(encoding ...string) http.Arrow {
+	return func(cat *http.Context) error {
+		actual := strings.ToLower(strings.TrimSpace(cat.Response.Header.Get("Content-Encoding")))
+		for _, e := range encoding {
+			if strings.ToLower(e) == actual {
+				return nil
+			}
+		}
+
+		return &gurl.NoMatch{
+			ID:       "http.Compressed",
+			Diff:     fmt.Sprintf("+ Content-Encoding: %s\n- Content-Encoding: %s", actual, strings.Join(encoding, " | ")),
+			Protocol: "Content-Encoding",
+			Expect:   encoding,
+			Actual:   actual,
+		}
+	}
+}
+
+// CompressionRatioAbove matches ratio of decoded payload size to the
+// Content-Length of compressed response, the stack shall decode payloads
+// (see http.WithCompression). The payload is buffered so that it remains
+// available for sub-sequent arrows.
+//
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		ø.AcceptEncodings.Gzip,
+//		ƒ.Status.OK,
+//		ƒ.CompressionRatioAbove(3.0),
+//	)
+func CompressionRatioAbove(ratio float64) http.Arrow {
+	return func(cat *http.Context) error {
+		length, err := strconv.ParseInt(cat.Response.Header.Get("Content-Length"), 10, 64)
+		if err != nil || length <= 0 {
+			return &gurl.NoMatch{
+				ID:       "http.CompressionRatio",
+				Diff:     "- Content-Length: *",
+				Protocol: "Content-Length",
+				Expect:   "*",
+			}
+		}
+
+		payload, err := io.ReadAll(cat.Response.Body)
+		cat.Response.Body.Close()
+		if err != nil {
+			cat.Response = nil
+			return err
+		}
+		cat.Response.Body = io.NopCloser(bytes.NewReader(payload))
+
+		actual := float64(len(payload)) / float64(length)
+		if actual <= ratio {
+			return &gurl.NoMatch{
+				ID:       "http.CompressionRatio",
+				Diff:     fmt.Sprintf("+ ratio: %.2f (%d of %d bytes)\n- ratio: > %.2f", actual, length, len(payload), ratio),
+				Protocol: "body",
+				Expect:   ratio,
+				Actual:   actual,
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,108 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestCompressed(t *testing.T) {
+	payload := `{"text": "` + strings.Repeat("a", 1000) + `"}`
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.Header.Get("Accept-Encoding") != "gzip" {
+				w.Write([]byte(payload))
+				return
+			}
+
+			var buf bytes.Buffer
+			enc := gzip.NewWriter(&buf)
+			enc.Write([]byte(payload))
+			enc.Close()
+
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+			w.Write(buf.Bytes())
+		}),
+	)
+	defer ts.Close()
+
+	t.Run("Compressed", func(t *testing.T) {
+		err := µ.New(µ.WithCompression()).IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ø.AcceptEncodings.Gzip,
+				ƒ.Status.OK,
+				ƒ.Compressed("br", "gzip"),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+		)
+	})
+
+	t.Run("NotCompressed", func(t *testing.T) {
+		err := µ.New(µ.WithCompression()).IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ø.AcceptEncodings.Identity,
+				ƒ.Status.OK,
+				ƒ.Compressed("gzip"),
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.As(err, new(*gurl.NoMatch))),
+		)
+	})
+
+	t.Run("RatioAbove", func(t *testing.T) {
+		err := µ.New(µ.WithCompression()).IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ø.AcceptEncodings.Gzip,
+				ƒ.Status.OK,
+				ƒ.CompressionRatioAbove(10.0),
+				ƒ.Match(`{"text": "_"}`),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+		)
+	})
+
+	t.Run("RatioBelow", func(t *testing.T) {
+		err := µ.New(µ.WithCompression()).IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ø.AcceptEncodings.Identity,
+				ƒ.Status.OK,
+				ƒ.CompressionRatioAbove(1.5),
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.As(err, new(*gurl.NoMatch))),
+		)
+	})
+}
@@ -0,0 +1,115 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+)
+
+//
+// The file implements conditional receivers
+//
+
+// StatusSwitch is a conditional receiver, which evaluates arrows of branch
+// matching the status code of response.
+type StatusSwitch struct {
+	branches []statusBranch
+}
+
+type statusBranch struct {
+	code   http.StatusCode
+	arrows []http.Arrow
+}
+
+// IfStatus declares arrows evaluated when response has given status code
+// (or class of codes). Chain it with ElseIf and Else branches.
+//
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		ƒ.IfStatus(http.StatusOK, ƒ.Body(&entity)).
+//			ElseIf(http.StatusClass4xx, ƒ.Problem(&problem)).
+//			Else(),
+//	)
+func IfStatus(code http.StatusCode, arrows ...http.Arrow) *StatusSwitch {
+	return &StatusSwitch{
+		branches: []statusBranch{{code: code, arrows: arrows}},
+	}
+}
+
+// ElseIf declares arrows evaluated when response has given status code
+// and none of previous branches matched.
+func (s *StatusSwitch) ElseIf(code http.StatusCode, arrows ...http.Arrow) *StatusSwitch {
+	return &StatusSwitch{
+		branches: append(append([]statusBranch{}, s.branches...), statusBranch{code: code, arrows: arrows}),
+	}
+}
+
+// Else declares arrows evaluated when none of branches matched.
+func (s *StatusSwitch) Else(arrows ...http.Arrow) http.Arrow {
+	return func(cat *http.Context) error {
+		branch, err := s.branch(cat)
+		if err != nil {
+			return err
+		}
+
+		if branch == nil {
+			return http.Join(arrows...)(cat)
+		}
+
+		return http.Join(branch.arrows...)(cat)
+	}
+}
+
+// Match is the conditional receiver without else branch, it fails if none
+// of branches matched.
+//
+//	ƒ.IfStatus(http.StatusOK, ƒ.Body(&entity)).
+//		ElseIf(http.StatusNotFound).
+//		Match
+func (s *StatusSwitch) Match(cat *http.Context) error {
+	branch, err := s.branch(cat)
+	if err != nil {
+		return err
+	}
+
+	if branch == nil {
+		seq := make([]string, len(s.branches))
+		for i, b := range s.branches {
+			seq[i] = b.code.Text()
+		}
+
+		return &gurl.NoMatch{
+			ID:       "http.Code",
+			Diff:     fmt.Sprintf("+ Status Code: %d\n- Status Code: %s", cat.Response.StatusCode, strings.Join(seq, " | ")),
+			Protocol: "StatusCode",
+			Expect:   seq,
+			Actual:   cat.Response.StatusCode,
+		}
+	}
+
+	return http.Join(branch.arrows...)(cat)
+}
+
+func (s *StatusSwitch) branch(cat *http.Context) (*statusBranch, error) {
+	if err := cat.Unsafe(); err != nil {
+		return nil, err
+	}
+
+	for i := range s.branches {
+		if s.branches[i].code.Match(cat.Response.StatusCode) {
+			return &s.branches[i], nil
+		}
+	}
+
+	return nil, nil
+}
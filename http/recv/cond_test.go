@@ -0,0 +1,84 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestIfStatus(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	branch := func(id string, seq *[]string) µ.Arrow {
+		return func(*µ.Context) error {
+			*seq = append(*seq, id)
+			return nil
+		}
+	}
+
+	for code, expect := range map[int]string{
+		200: "ok",
+		404: "not-found",
+		409: "client",
+		503: "else",
+	} {
+		var seq []string
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/code/%d", ø.Authority(ts.URL), code),
+				ƒ.IfStatus(µ.StatusOK, branch("ok", &seq)).
+					ElseIf(µ.StatusNotFound, branch("not-found", &seq)).
+					ElseIf(µ.StatusClass4xx, branch("client", &seq)).
+					Else(branch("else", &seq)),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal(expect),
+		)
+	}
+
+	t.Run("Decode", func(t *testing.T) {
+		var site struct {
+			Site string `json:"site"`
+		}
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.IfStatus(µ.StatusOK, ƒ.ContentType.JSON, ƒ.Body(&site)).Match,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(site.Site, "example.com"),
+		)
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/code/%d", ø.Authority(ts.URL), 503),
+				ƒ.IfStatus(µ.StatusOK).ElseIf(µ.StatusClass4xx).Match,
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.As(err, new(*gurl.NoMatch))),
+			it.Equal(err.Error(), "+ Status Code: 503\n- Status Code: 200 | 4xx"),
+		)
+	})
+}
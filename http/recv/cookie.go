@@ -0,0 +1,141 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+)
+
+//
+// The file implements lenses over Set-Cookie headers
+//
+
+// Cookie is a lens over cookie set by the response, it parses Set-Cookie
+// headers into typed http.Cookie.
+//
+//	ƒ.Cookie("session").Is("x"),
+//	ƒ.Cookie("session").Secure,
+//	ƒ.Cookie("session").To(&cookie),
+type Cookie string
+
+func (c Cookie) lookup(ctx *µ.Context) (*http.Cookie, error) {
+	for _, cookie := range ctx.Response.Cookies() {
+		if cookie.Name == string(c) {
+			return cookie, nil
+		}
+	}
+
+	return nil, &gurl.NoMatch{
+		ID:       "http.Cookie",
+		Diff:     fmt.Sprintf("- Set-Cookie: %s=*", string(c)),
+		Protocol: "Set-Cookie",
+		Expect:   string(c),
+		Actual:   nil,
+	}
+}
+
+func (c Cookie) noMatch(attr string, expect, actual any) error {
+	return &gurl.NoMatch{
+		ID:       "http.Cookie",
+		Diff:     fmt.Sprintf("+ %s %s: %v\n- %s %s: %v", string(c), attr, actual, string(c), attr, expect),
+		Protocol: "Set-Cookie",
+		Expect:   expect,
+		Actual:   actual,
+	}
+}
+
+// Matches cookie to any value
+func (c Cookie) Any(ctx *µ.Context) error {
+	_, err := c.lookup(ctx)
+	return err
+}
+
+// Matches value of the cookie
+func (c Cookie) Is(value string) µ.Arrow {
+	return func(ctx *µ.Context) error {
+		cookie, err := c.lookup(ctx)
+		if err != nil {
+			return err
+		}
+
+		if cookie.Value != value {
+			return c.noMatch("value", value, cookie.Value)
+		}
+
+		return nil
+	}
+}
+
+// Matches cookie with Secure attribute
+func (c Cookie) Secure(ctx *µ.Context) error {
+	cookie, err := c.lookup(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !cookie.Secure {
+		return c.noMatch("Secure", true, false)
+	}
+
+	return nil
+}
+
+// Matches cookie with HttpOnly attribute
+func (c Cookie) HttpOnly(ctx *µ.Context) error {
+	cookie, err := c.lookup(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !cookie.HttpOnly {
+		return c.noMatch("HttpOnly", true, false)
+	}
+
+	return nil
+}
+
+// Matches cookie that expires after the given time. Max-Age attribute
+// takes precedence over Expires.
+func (c Cookie) ExpiresAfter(t time.Time) µ.Arrow {
+	return func(ctx *µ.Context) error {
+		cookie, err := c.lookup(ctx)
+		if err != nil {
+			return err
+		}
+
+		expires := cookie.Expires
+		if cookie.MaxAge > 0 {
+			expires = ctx.Clock().Now().Add(time.Duration(cookie.MaxAge) * time.Second)
+		}
+
+		if !expires.After(t) {
+			return c.noMatch("Expires", t.UTC().Format(time.RFC1123), expires.UTC().Format(time.RFC1123))
+		}
+
+		return nil
+	}
+}
+
+// Lifts the cookie to variable. It fails if cookie do not exists
+func (c Cookie) To(value *http.Cookie) µ.Arrow {
+	return func(ctx *µ.Context) error {
+		cookie, err := c.lookup(ctx)
+		if err != nil {
+			return err
+		}
+
+		*value = *cookie
+		return nil
+	}
+}
@@ -0,0 +1,75 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestCookie(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "x", Secure: true, HttpOnly: true, MaxAge: 3600})
+			http.SetCookie(w, &http.Cookie{Name: "theme", Value: "dark"})
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer ts.Close()
+
+	cat := µ.New()
+	req := func(arrows ...µ.Arrow) error {
+		return cat.IO(context.Background(),
+			µ.GET(append([]µ.Arrow{ø.URI(ts.URL), ƒ.Status.OK}, arrows...)...),
+		)
+	}
+
+	t.Run("Match", func(t *testing.T) {
+		err := req(
+			ƒ.Cookie("session").Any,
+			ƒ.Cookie("session").Is("x"),
+			ƒ.Cookie("session").Secure,
+			ƒ.Cookie("session").HttpOnly,
+			ƒ.Cookie("session").ExpiresAfter(time.Now().Add(time.Minute)),
+			ƒ.Cookie("theme").Is("dark"),
+		)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("To", func(t *testing.T) {
+		var cookie http.Cookie
+		err := req(ƒ.Cookie("session").To(&cookie))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cookie.Value, "x"),
+			it.Equal(cookie.MaxAge, 3600),
+		)
+	})
+
+	for name, arrow := range map[string]µ.Arrow{
+		"NotFound":     ƒ.Cookie("unknown").Any,
+		"Value":        ƒ.Cookie("theme").Is("light"),
+		"Secure":       ƒ.Cookie("theme").Secure,
+		"HttpOnly":     ƒ.Cookie("theme").HttpOnly,
+		"ExpiresAfter": ƒ.Cookie("theme").ExpiresAfter(time.Now()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := req(arrow)
+			it.Then(t).ShouldNot(it.Nil(err))
+		})
+	}
+}
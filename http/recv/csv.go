@@ -0,0 +1,167 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/fogfish/gurl/v2/http"
+)
+
+//
+// The file implements decoding of CSV responses into slices of structs
+//
+
+// CSVFormat configures the CSV decoder
+type CSVFormat struct {
+	// Delimiter of columns, default is ','
+	Comma rune
+	// Comment character, lines beginning with it are ignored
+	Comment rune
+	// The first record is data, columns are mapped to fields by position
+	NoHeader bool
+}
+
+// CSV decodes CSV response into slice of structs. Columns are mapped to
+// fields using the header record and struct tag `csv`, the name of field is
+// used if tag is not defined. Columns are mapped to fields by position if
+// format declares NoHeader. Fields of type string, bool, integers, floats,
+// time.Time (RFC 3339) and time.Duration are supported.
+//
+//	var rows []struct {
+//		ID   string `csv:"id"`
+//		Size int    `csv:"size"`
+//	}
+//
+//	http.GET(
+//		ø.URI("https://example.com/export"),
+//		ƒ.Status.OK,
+//		ƒ.CSV(&rows, ƒ.CSVFormat{Comma: ';'}),
+//	)
+func CSV[T any](out *[]T, format ...CSVFormat) http.Arrow {
+	var spec CSVFormat
+	if len(format) > 0 {
+		spec = format[0]
+	}
+
+	return func(cat *http.Context) error {
+		defer func() {
+			cat.Response.Body.Close()
+			cat.Response = nil
+		}()
+
+		reader := csv.NewReader(cat.Response.Body)
+		if spec.Comma != 0 {
+			reader.Comma = spec.Comma
+		}
+		reader.Comment = spec.Comment
+		reader.FieldsPerRecord = -1
+
+		kind := reflect.TypeOf((*T)(nil)).Elem()
+		if kind.Kind() != reflect.Struct {
+			return fmt.Errorf("CSV requires slice of structs, %T given", out)
+		}
+
+		columns, err := csvColumns(kind, reader, spec.NoHeader)
+		if err != nil {
+			return err
+		}
+
+		seq := make([]T, 0)
+		for {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+
+			var row T
+			val := reflect.ValueOf(&row).Elem()
+			for i, col := range record {
+				if i >= len(columns) || columns[i] == -1 {
+					continue
+				}
+
+				if err := liftColumn(val.Field(columns[i]), col, cat.Clock().Now()); err != nil {
+					line, _ := reader.FieldPos(i)
+					return fmt.Errorf("csv line %d, column %d: %w", line, i+1, err)
+				}
+			}
+			seq = append(seq, row)
+		}
+
+		*out = seq
+		return nil
+	}
+}
+
+// csvColumns maps columns to index of struct fields, -1 is unmapped column
+func csvColumns(kind reflect.Type, reader *csv.Reader, noHeader bool) ([]int, error) {
+	names := map[string]int{}
+	position := []int{}
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+		name, has := field.Tag.Lookup("csv")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if !has {
+			name = field.Name
+		}
+		names[name] = i
+		position = append(position, i)
+	}
+
+	if noHeader {
+		return position, nil
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	columns := make([]int, len(header))
+	for i, name := range header {
+		if at, has := names[name]; has {
+			columns[i] = at
+		} else {
+			columns[i] = -1
+		}
+	}
+
+	return columns, nil
+}
+
+func liftColumn(field reflect.Value, val string, now time.Time) error {
+	if field.Type() == typeTime {
+		t, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if field.Kind() == reflect.Slice {
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return liftField(field, []string{val}, now)
+}
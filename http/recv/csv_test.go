@@ -0,0 +1,99 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestCSV(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/csv")
+			switch r.URL.Path {
+			case "/header":
+				w.Write([]byte("size,id,unknown,created\n10,a,x,2023-02-01T10:20:30Z\n20,b,y,2023-02-02T10:20:30Z\n"))
+			case "/semicolon":
+				w.Write([]byte("# export\na;10\nb;20\n"))
+			case "/malformed":
+				w.Write([]byte("id,size\na,ten\n"))
+			}
+		}),
+	)
+	defer ts.Close()
+
+	type Row struct {
+		ID      string    `csv:"id"`
+		Size    int       `csv:"size"`
+		Created time.Time `csv:"created"`
+		Skip    string    `csv:"-"`
+	}
+
+	t.Run("Header", func(t *testing.T) {
+		var rows []Row
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/header", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.CSV(&rows),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(rows), 2),
+			it.Equal(rows[0].ID, "a"),
+			it.Equal(rows[0].Size, 10),
+			it.Equal(rows[0].Created, time.Date(2023, 2, 1, 10, 20, 30, 0, time.UTC)),
+			it.Equal(rows[1].ID, "b"),
+			it.Equal(rows[1].Size, 20),
+		)
+	})
+
+	t.Run("NoHeader", func(t *testing.T) {
+		var rows []Row
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/semicolon", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.CSV(&rows, ƒ.CSVFormat{Comma: ';', Comment: '#', NoHeader: true}),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(rows), 2),
+			it.Equal(rows[0].ID, "a"),
+			it.Equal(rows[0].Size, 10),
+			it.Equal(rows[1].ID, "b"),
+			it.Equal(rows[1].Size, 20),
+		)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		var rows []Row
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/malformed", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.CSV(&rows),
+			),
+		)
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+	})
+}
@@ -0,0 +1,171 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+)
+
+//
+// The file implements generator of happy-path assertions from response
+// samples, accelerating the suite authoring.
+//
+
+// Generate writes Go code of assertions (status code, content type and
+// payload) matching the current response. Volatile fields of JSON payload
+// (identities, timestamps, tokens) are replaced by wildcards.
+//
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		ƒ.Generate(os.Stdout),
+//	)
+func Generate(w io.Writer) µ.Arrow {
+	return func(cat *µ.Context) error {
+		if err := cat.Unsafe(); err != nil {
+			return err
+		}
+
+		payload, err := io.ReadAll(cat.Response.Body)
+		cat.Response.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		code, err := GenerateFrom(cat.Response.StatusCode, cat.Response.Header, payload)
+		cat.Response = nil
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(w, code)
+		return err
+	}
+}
+
+// GenerateFrom writes Go code of assertions for the recorded response
+// (e.g. Memento payload).
+func GenerateFrom(status int, header http.Header, payload []byte) (string, error) {
+	buf := &strings.Builder{}
+	buf.WriteString(generateStatus(status))
+	buf.WriteString(",\n")
+
+	content := header.Get("Content-Type")
+	if content != "" {
+		fmt.Fprintf(buf, "ƒ.ContentType.Is(%q),\n", content)
+	}
+
+	if strings.Contains(content, "json") && len(bytes.TrimSpace(payload)) > 0 {
+		match, err := GenerateMatch(payload)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(match)
+		buf.WriteString(",\n")
+	}
+
+	return buf.String(), nil
+}
+
+// GenerateMatch writes Go code of ƒ.Match assertion for JSON payload.
+// Volatile fields are replaced by "_" wildcard.
+func GenerateMatch(payload []byte) (string, error) {
+	var value any
+	codec := json.NewDecoder(bytes.NewReader(payload))
+	codec.UseNumber()
+	if err := codec.Decode(&value); err != nil {
+		return "", err
+	}
+
+	pattern, err := json.MarshalIndent(wildcard("", value), "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	if bytes.ContainsRune(pattern, '`') {
+		return fmt.Sprintf("ƒ.Match(%q)", pattern), nil
+	}
+
+	return "ƒ.Match(`" + string(pattern) + "`)", nil
+}
+
+func generateStatus(code int) string {
+	name := strings.NewReplacer(" ", "", "-", "").Replace(http.StatusText(code))
+	if name != "" {
+		if _, has := reflect.TypeOf(Status).FieldByName(name); has {
+			return "ƒ.Status." + name
+		}
+	}
+
+	return fmt.Sprintf("ƒ.Code(%d)", code)
+}
+
+var (
+	volatileKey = regexp.MustCompile(`(?i)(^id$|_id$|[a-z]Id$|^uuid$|^guid$|token|nonce|secret|signature|etag|timestamp|_at$|[a-z]At$|^created|^updated|^modified|^expires|^date$|^time$)`)
+	volatileVal = []*regexp.Regexp{
+		// UUID
+		regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`),
+		// ULID
+		regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`),
+		// JWT
+		regexp.MustCompile(`^eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*$`),
+		// hex digest
+		regexp.MustCompile(`^[0-9a-fA-F]{32,}$`),
+	}
+)
+
+func wildcard(key string, value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, x := range v {
+			v[k] = wildcard(k, x)
+		}
+		return v
+	case []any:
+		for i, x := range v {
+			v[i] = wildcard(key, x)
+		}
+		return v
+	case nil:
+		return nil
+	case string:
+		if isVolatileString(v) {
+			return "_"
+		}
+	}
+
+	if key != "" && volatileKey.MatchString(key) {
+		return "_"
+	}
+
+	return value
+}
+
+func isVolatileString(v string) bool {
+	for _, re := range volatileVal {
+		if re.MatchString(v) {
+			return true
+		}
+	}
+
+	if _, err := time.Parse(time.RFC3339, v); err == nil {
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,92 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+const sample = `{
+	"id": 1024,
+	"name": "joe",
+	"site": "www.example.com",
+	"ref": "0b5fa0e1-6e4c-4b53-9f18-6a3c7ed3f2fa",
+	"createdAt": 1675246830,
+	"seen": "2023-02-01T10:20:30Z",
+	"tags": ["a", "b"],
+	"owner": {"user_id": "u1", "role": "admin"}
+}`
+
+func TestGenerateMatch(t *testing.T) {
+	code, err := ƒ.GenerateMatch([]byte(sample))
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(code, "ƒ.Match(`{\n"+
+			"  \"createdAt\": \"_\",\n"+
+			"  \"id\": \"_\",\n"+
+			"  \"name\": \"joe\",\n"+
+			"  \"owner\": {\n"+
+			"    \"role\": \"admin\",\n"+
+			"    \"user_id\": \"_\"\n"+
+			"  },\n"+
+			"  \"ref\": \"_\",\n"+
+			"  \"seen\": \"_\",\n"+
+			"  \"site\": \"www.example.com\",\n"+
+			"  \"tags\": [\n"+
+			"    \"a\",\n"+
+			"    \"b\"\n"+
+			"  ]\n"+
+			"}`)",
+		),
+	)
+
+	_, err = ƒ.GenerateMatch([]byte("{"))
+	it.Then(t).ShouldNot(it.Nil(err))
+}
+
+func TestGenerate(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": "a", "tags": ["x"]}`))
+		}),
+	)
+	defer ts.Close()
+
+	buf := &bytes.Buffer{}
+	err := µ.New().IO(context.Background(),
+		µ.POST(
+			ø.URI(ts.URL),
+			ƒ.Generate(buf),
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(buf.String(), "ƒ.Status.Created,\n"+
+			"ƒ.ContentType.Is(\"application/json\"),\n"+
+			"ƒ.Match(`{\n  \"id\": \"_\",\n  \"tags\": [\n    \"x\"\n  ]\n}`),\n",
+		),
+	)
+
+	code, err := ƒ.GenerateFrom(418, http.Header{}, nil)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(code, "ƒ.Code(418),\n"),
+	)
+}
@@ -0,0 +1,140 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+)
+
+//
+// The file implements lifting of response headers into struct
+//
+
+var (
+	typeTime     = reflect.TypeOf(time.Time{})
+	typeDuration = reflect.TypeOf(time.Duration(0))
+)
+
+// HeadersTo lifts response headers into struct fields annotated with tag
+// `header`. Fields of type string, []string, bool, integers, floats,
+// time.Time and time.Duration are supported. Absent headers leave fields
+// intact unless the tag has "required" flag.
+//
+//	var meta struct {
+//		ETag string        `header:"ETag,required"`
+//		Len  int           `header:"Content-Length"`
+//		Age  time.Duration `header:"Age"`
+//	}
+//
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		ƒ.Status.OK,
+//		ƒ.HeadersTo(&meta),
+//	)
+func HeadersTo[T any](out *T) http.Arrow {
+	return func(ctx *http.Context) error {
+		val := reflect.ValueOf(out).Elem()
+		if val.Kind() != reflect.Struct {
+			return fmt.Errorf("HeadersTo requires pointer to struct, %T given", out)
+		}
+
+		for i := 0; i < val.NumField(); i++ {
+			field := val.Type().Field(i)
+			tag, has := field.Tag.Lookup("header")
+			if !has || tag == "-" || !field.IsExported() {
+				continue
+			}
+
+			name, flags, _ := strings.Cut(tag, ",")
+			values := ctx.Response.Header.Values(name)
+			if len(values) == 0 {
+				if flags == "required" {
+					return &gurl.NoMatch{
+						ID:       "http.Header",
+						Diff:     fmt.Sprintf("- %s: *", name),
+						Protocol: name,
+					}
+				}
+				continue
+			}
+
+			if err := liftField(val.Field(i), values, ctx.Clock().Now()); err != nil {
+				return fmt.Errorf("header %s: %w", name, err)
+			}
+		}
+
+		return nil
+	}
+}
+
+func liftField(field reflect.Value, values []string, now time.Time) error {
+	val := values[0]
+
+	switch field.Type() {
+	case typeTime:
+		t, err := time.Parse(time.RFC1123, val)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	case typeDuration:
+		d, err := parseDuration(val, now)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+		field.Set(reflect.ValueOf(append([]string{}, values...)))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestHeadersTo(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"abc"`)
+			w.Header().Set("Last-Modified", "Wed, 01 Feb 2023 10:20:30 UTC")
+			w.Header().Set("Age", "60")
+			w.Header().Set("X-Ratio", "0.5")
+			w.Header().Set("X-Cached", "true")
+			w.Header().Add("Vary", "Accept")
+			w.Header().Add("Vary", "Accept-Encoding")
+			w.Write([]byte("hello"))
+		}),
+	)
+	defer ts.Close()
+
+	t.Run("Lift", func(t *testing.T) {
+		var meta struct {
+			ETag     string        `header:"ETag,required"`
+			Len      int           `header:"Content-Length"`
+			Modified time.Time     `header:"Last-Modified"`
+			Age      time.Duration `header:"Age"`
+			Ratio    float64       `header:"X-Ratio"`
+			Cached   bool          `header:"X-Cached"`
+			Vary     []string      `header:"Vary"`
+			Missing  string        `header:"X-Missing"`
+			Ignored  string
+		}
+
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.HeadersTo(&meta),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(meta.ETag, `"abc"`),
+			it.Equal(meta.Len, 5),
+			it.Equal(meta.Modified.Format(time.RFC1123), "Wed, 01 Feb 2023 10:20:30 UTC"),
+			it.Equal(meta.Age, time.Minute),
+			it.Equal(meta.Ratio, 0.5),
+			it.Equal(meta.Cached, true),
+			it.Seq(meta.Vary).Equal("Accept", "Accept-Encoding"),
+			it.Equal(meta.Missing, ""),
+		)
+	})
+
+	t.Run("Required", func(t *testing.T) {
+		var meta struct {
+			Missing string `header:"X-Missing,required"`
+		}
+
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.HeadersTo(&meta),
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.As(err, new(*gurl.NoMatch))),
+		)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		var meta struct {
+			ETag int `header:"ETag"`
+		}
+
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.HeadersTo(&meta),
+			),
+		)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+}
@@ -0,0 +1,194 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+	"github.com/google/go-cmp/cmp"
+)
+
+// JSONPath matches or lifts fragment of JSON payload focused by the path.
+// The value is lifted if pointer is supplied, otherwise it is matched.
+//
+//	var id string
+//	http.GET(
+//		...
+//		ƒ.JSONPath("$.items[0].id", &id),
+//		ƒ.JSONPath("$.items[0].name", "example"),
+//	)
+func JSONPath[T any](path string, value T) http.Arrow {
+	if t := reflect.TypeOf(value); t != nil && t.Kind() == reflect.Pointer {
+		return jsonPathTo(path, value)
+	}
+
+	return JSONPathOf[T](path).Is(value)
+}
+
+// JSONPathOf is a typed lens focused on fragment of JSON payload.
+// The payload is not consumed by the lens, multiple lenses are composable
+// over same response.
+//
+//	http.GET(
+//		...
+//		ƒ.JSONPathOf[int]("$.items[0].id").Is(1),
+//		ƒ.JSONPathOf[string]("$.items[0].name").To(&name),
+//	)
+type JSONPathOf[T any] string
+
+// Matches presence of the fragment
+func (path JSONPathOf[T]) Any(ctx *http.Context) error {
+	_, err := jsonPathLookup(ctx, string(path))
+	return err
+}
+
+// Matches fragment to the value
+func (path JSONPathOf[T]) Is(expect T) http.Arrow {
+	return func(ctx *http.Context) error {
+		var actual T
+		if err := jsonPathTo(string(path), &actual)(ctx); err != nil {
+			return err
+		}
+
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			return &gurl.NoMatch{
+				ID:       "http.JSONPath",
+				Diff:     fmt.Sprintf("%s\n%s", string(path), diff),
+				Protocol: "body",
+				Expect:   expect,
+				Actual:   actual,
+			}
+		}
+
+		return nil
+	}
+}
+
+// Lifts fragment to the variable
+func (path JSONPathOf[T]) To(value *T) http.Arrow {
+	return jsonPathTo(string(path), value)
+}
+
+func jsonPathTo(path string, value any) http.Arrow {
+	return func(ctx *http.Context) error {
+		val, err := jsonPathLookup(ctx, path)
+		if err != nil {
+			return err
+		}
+
+		bin, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+
+		return json.Unmarshal(bin, value)
+	}
+}
+
+func jsonPathLookup(ctx *http.Context, path string) (any, error) {
+	seq, err := jsonPathParse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := io.ReadAll(ctx.Response.Body)
+	ctx.Response.Body.Close()
+	ctx.Response.Body = io.NopCloser(bytes.NewBuffer(buf))
+	if err != nil {
+		return nil, err
+	}
+
+	var doc any
+	if err := json.Unmarshal(buf, &doc); err != nil {
+		return nil, err
+	}
+
+	for _, key := range seq {
+		switch k := key.(type) {
+		case string:
+			obj, ok := doc.(map[string]any)
+			if !ok {
+				return nil, jsonPathNoMatch(path)
+			}
+			if doc, ok = obj[k]; !ok {
+				return nil, jsonPathNoMatch(path)
+			}
+		case int:
+			arr, ok := doc.([]any)
+			if !ok || k >= len(arr) {
+				return nil, jsonPathNoMatch(path)
+			}
+			doc = arr[k]
+		}
+	}
+
+	return doc, nil
+}
+
+func jsonPathNoMatch(path string) error {
+	return &gurl.NoMatch{
+		ID:       "http.JSONPath",
+		Diff:     fmt.Sprintf("- %s: *", path),
+		Protocol: "body",
+		Expect:   path,
+	}
+}
+
+// parses path `$.a.b[1]["c"]` into sequence of keys (string) and indexes (int)
+func jsonPathParse(path string) ([]any, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid JSON path %s", path)
+	}
+
+	seq := []any{}
+	s := path[1:]
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			n := strings.IndexAny(s[1:], ".[")
+			if n == -1 {
+				n = len(s) - 1
+			}
+			if n == 0 {
+				return nil, fmt.Errorf("invalid JSON path %s", path)
+			}
+			seq = append(seq, s[1:n+1])
+			s = s[n+1:]
+		case '[':
+			n := strings.IndexByte(s, ']')
+			if n == -1 {
+				return nil, fmt.Errorf("invalid JSON path %s", path)
+			}
+			key := s[1:n]
+			switch {
+			case len(key) >= 2 && (key[0] == '"' || key[0] == '\'') && key[len(key)-1] == key[0]:
+				seq = append(seq, key[1:len(key)-1])
+			default:
+				i, err := strconv.Atoi(key)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("invalid JSON path %s", path)
+				}
+				seq = append(seq, i)
+			}
+			s = s[n+1:]
+		default:
+			return nil, fmt.Errorf("invalid JSON path %s", path)
+		}
+	}
+
+	return seq, nil
+}
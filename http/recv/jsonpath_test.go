@@ -0,0 +1,85 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"context"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestJSONPath(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	type E struct {
+		B int `json:"b"`
+	}
+
+	t.Run("Match", func(t *testing.T) {
+		var (
+			a string
+			d []string
+			e E
+		)
+
+		req := µ.GET(
+			ø.URI("%s/match", ø.Authority(ts.URL)),
+			ƒ.Status.OK,
+			ƒ.JSONPath("$.a", "a"),
+			ƒ.JSONPath("$.b", 101),
+			ƒ.JSONPath("$.d[1]", "b"),
+			ƒ.JSONPath("$['e'].c", 1.1),
+			ƒ.JSONPath("$.a", &a),
+			ƒ.JSONPath("$.d", &d),
+			ƒ.JSONPathOf[E]("$.e").To(&e),
+			ƒ.JSONPathOf[bool]("$.f").Is(true),
+			ƒ.JSONPathOf[any]("$.e.a").Any,
+		)
+		cat := µ.New()
+		err := cat.IO(context.Background(), req)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(a, "a"),
+			it.Seq(d).Equal("a", "b", "c"),
+			it.Equal(e.B, 101),
+		)
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		for _, arrow := range []µ.Arrow{
+			ƒ.JSONPath("$.a", "b"),
+			ƒ.JSONPath("$.b", "101"),
+			ƒ.JSONPath("$.d[5]", "b"),
+			ƒ.JSONPath("$.g", "a"),
+			ƒ.JSONPath("$.a.b", "a"),
+			ƒ.JSONPath("$.d.a", "a"),
+			ƒ.JSONPath("a.b", "a"),
+			ƒ.JSONPath("$.d[x]", "a"),
+			ƒ.JSONPathOf[any]("$.e.g").Any,
+		} {
+			req := µ.GET(
+				ø.URI("%s/match", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				arrow,
+			)
+			cat := µ.New()
+			err := cat.IO(context.Background(), req)
+
+			it.Then(t).ShouldNot(
+				it.Nil(err),
+			)
+		}
+	})
+}
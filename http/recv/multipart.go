@@ -0,0 +1,91 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+)
+
+//
+// The file implements streaming of multipart responses
+//
+
+// Parts hands each part of multipart response (e.g. multipart/x-mixed-replace
+// of MJPEG camera streams, multipart/mixed) to the callback as it arrives.
+// The part is valid until the callback returns. The stream is terminated
+// when context of I/O is cancelled.
+//
+//	http.GET(
+//		ø.URI("https://example.com/camera"),
+//		ƒ.Status.OK,
+//		ƒ.Parts(func(part *multipart.Part) error { /* ... */ }),
+//	)
+func Parts(f func(*multipart.Part) error) http.Arrow {
+	return func(cat *http.Context) error {
+		content := cat.Response.Header.Get("Content-Type")
+		media, params, err := mime.ParseMediaType(content)
+		if err != nil || !strings.HasPrefix(media, "multipart/") || params["boundary"] == "" {
+			cat.Response.Body.Close()
+			cat.Response = nil
+			return &gurl.NoMatch{
+				ID:       "http.Parts",
+				Diff:     fmt.Sprintf("+ Content-Type: %s\n- Content-Type: multipart/*; boundary=...", content),
+				Protocol: "Content-Type",
+				Expect:   "multipart/*",
+				Actual:   content,
+			}
+		}
+
+		body := cat.Response.Body
+		cat.Response = nil
+		defer body.Close()
+
+		// Note: reading of body is blocked until the next part arrives,
+		//       closing the body on cancellation unblocks the reader.
+		done := make(chan struct{})
+		defer close(done)
+		if cat.Context != nil {
+			go func() {
+				select {
+				case <-cat.Done():
+					body.Close()
+				case <-done:
+				}
+			}()
+		}
+
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				if cat.Context != nil && cat.Err() != nil {
+					return cat.Err()
+				}
+				return err
+			}
+
+			err = f(part)
+			part.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
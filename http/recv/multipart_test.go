@@ -0,0 +1,125 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestParts(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/text" {
+				w.Header().Set("Content-Type", "text/plain")
+				w.Write([]byte("frame"))
+				return
+			}
+
+			mw := multipart.NewWriter(w)
+			w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary="+mw.Boundary())
+			for _, frame := range []string{"a", "b", "c"} {
+				part, _ := mw.CreatePart(map[string][]string{"Content-Type": {"image/jpeg"}})
+				part.Write([]byte(frame))
+				w.(http.Flusher).Flush()
+			}
+
+			if r.URL.Path == "/infinite" {
+				<-r.Context().Done()
+				return
+			}
+			mw.Close()
+		}),
+	)
+	defer ts.Close()
+
+	t.Run("Parts", func(t *testing.T) {
+		var seq []string
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.Parts(func(part *multipart.Part) error {
+					b, err := io.ReadAll(part)
+					seq = append(seq, part.Header.Get("Content-Type")+":"+string(b))
+					return err
+				}),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal("image/jpeg:a", "image/jpeg:b", "image/jpeg:c"),
+		)
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		abort := errors.New("abort")
+		n := 0
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/infinite", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Parts(func(part *multipart.Part) error {
+					n++
+					return abort
+				}),
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, abort)),
+			it.Equal(n, 1),
+		)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		n := 0
+		err := µ.New().IO(ctx,
+			µ.GET(
+				ø.URI("%s/infinite", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Parts(func(part *multipart.Part) error {
+					n++
+					return nil
+				}),
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, context.DeadlineExceeded)),
+			it.Equal(n, 3),
+		)
+	})
+
+	t.Run("NotMultipart", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/text", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Parts(func(part *multipart.Part) error { return nil }),
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.As(err, new(*gurl.NoMatch))),
+		)
+	})
+}
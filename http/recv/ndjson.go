@@ -0,0 +1,67 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fogfish/gurl/v2/http"
+)
+
+//
+// The file implements streaming decoder of NDJSON (JSON Lines) responses
+//
+
+// NDJSON decodes `application/x-ndjson` (JSON Lines) response record-by-record
+// and hands each record to the callback. The payload is not buffered in
+// memory, the evaluation stops on the first error returned by callback.
+//
+//	http.GET(
+//		ø.URI("https://example.com/feed"),
+//		ƒ.Status.OK,
+//		ƒ.NDJSON(func(e Event) error { /* ... */ }),
+//	)
+func NDJSON[T any](f func(T) error) http.Arrow {
+	return Stream(func(r io.Reader) error {
+		codec := json.NewDecoder(r)
+
+		for seq := 1; ; seq++ {
+			var val T
+			if err := codec.Decode(&val); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return fmt.Errorf("ndjson record %d: %w", seq, err)
+			}
+
+			if err := f(val); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// NDJSONTo decodes `application/x-ndjson` (JSON Lines) response and fills
+// the channel with records. The channel is not closed by the arrow. The
+// evaluation is cancelled together with context of I/O.
+func NDJSONTo[T any](ch chan<- T) http.Arrow {
+	return func(cat *http.Context) error {
+		return NDJSON(func(val T) error {
+			select {
+			case ch <- val:
+				return nil
+			case <-cat.Done():
+				return cat.Err()
+			}
+		})(cat)
+	}
+}
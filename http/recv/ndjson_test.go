@@ -0,0 +1,141 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestNDJSON(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			switch r.URL.Path {
+			case "/malformed":
+				w.Write([]byte("{\"id\": 1}\n{\"id\": \n"))
+			default:
+				w.Write([]byte("{\"id\": 1}\n{\"id\": 2}\n\n{\"id\": 3}\n"))
+			}
+		}),
+	)
+	defer ts.Close()
+
+	type Event struct {
+		ID int `json:"id"`
+	}
+
+	t.Run("Callback", func(t *testing.T) {
+		var seq []int
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.NDJSON(func(e Event) error {
+					seq = append(seq, e.ID)
+					return nil
+				}),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal(1, 2, 3),
+		)
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		abort := errors.New("abort")
+		var seq []int
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.NDJSON(func(e Event) error {
+					seq = append(seq, e.ID)
+					return abort
+				}),
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, abort)),
+			it.Seq(seq).Equal(1),
+		)
+	})
+
+	t.Run("Channel", func(t *testing.T) {
+		ch := make(chan Event, 3)
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.NDJSONTo(ch),
+			),
+		)
+		close(ch)
+
+		var seq []int
+		for e := range ch {
+			seq = append(seq, e.ID)
+		}
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal(1, 2, 3),
+		)
+	})
+
+	t.Run("Body", func(t *testing.T) {
+		var seq []Event
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.Body(&seq),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 3),
+			it.Equal(seq[2].ID, 3),
+		)
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.Match(`[{"id": 1}, "..."]`),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+		)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/malformed", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.NDJSON(func(e Event) error { return nil }),
+			),
+		)
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+	})
+}
@@ -0,0 +1,103 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+)
+
+// Negotiated matches the content type of response against Accept header
+// of the request. The most specific media range of the header defines the
+// quality, the type is not acceptable if it is not listed or its quality is 0.
+//
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		ø.Accept.With("application/json", 1.0).With("text/xml", 0.5).Set,
+//		ƒ.Status.OK,
+//		ƒ.ContentType.Negotiated,
+//	)
+func (h HeaderEnumContent) Negotiated(ctx *http.Context) error {
+	val := ctx.Response.Header.Get(string(h))
+	if val == "" {
+		return &gurl.NoMatch{
+			ID:       "http.Header",
+			Diff:     fmt.Sprintf("- %s: *", string(h)),
+			Protocol: string(h),
+		}
+	}
+
+	accept := ""
+	if ctx.Request != nil {
+		accept = ctx.Request.Header.Get("Accept")
+	}
+	if accept == "" {
+		return nil
+	}
+
+	media, _, err := mime.ParseMediaType(val)
+	if err != nil {
+		media = strings.ToLower(strings.TrimSpace(val))
+	}
+
+	if qualityOf(accept, media) > 0 {
+		return nil
+	}
+
+	return &gurl.NoMatch{
+		ID:       "http.Header",
+		Diff:     fmt.Sprintf("+ %s: %s\n- Accept: %s", string(h), val, accept),
+		Protocol: string(h),
+		Expect:   accept,
+		Actual:   val,
+	}
+}
+
+// qualityOf media type defined by the most specific range of Accept header
+func qualityOf(accept, media string) float64 {
+	major, _, _ := strings.Cut(media, "/")
+
+	quality, specificity := 0.0, -1
+	for _, item := range strings.Split(accept, ",") {
+		rng, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		rng = strings.ToLower(strings.TrimSpace(rng))
+
+		level := -1
+		switch {
+		case rng == media:
+			level = 2
+		case rng == major+"/*":
+			level = 1
+		case rng == "*/*":
+			level = 0
+		}
+
+		if level <= specificity {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if k, v, has := strings.Cut(strings.TrimSpace(param), "="); has && strings.EqualFold(k, "q") {
+				if x, err := strconv.ParseFloat(v, 64); err == nil {
+					q = x
+				}
+			}
+		}
+
+		quality, specificity = q, level
+	}
+
+	return quality
+}
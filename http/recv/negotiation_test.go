@@ -0,0 +1,43 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"context"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestNegotiated(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	for accept, expect := range map[string]bool{
+		"application/json":                    true,
+		"text/xml, application/*;q=0.5":       true,
+		"text/xml, */*;q=0.1":                 true,
+		"text/xml":                            false,
+		"application/json;q=0, */*":           false,
+		"application/*, application/json;q=0": false,
+	} {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ø.Accept.Set(accept),
+				ƒ.Status.OK,
+				ƒ.ContentType.Negotiated,
+			),
+		)
+		it.Then(t).Should(it.Equal(err == nil, expect))
+	}
+}
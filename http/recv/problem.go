@@ -0,0 +1,139 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Problem decodes RFC 7807 `application/problem+json` payload.
+// The lens fails if the response carries other content type.
+//
+//	var p http.Problem
+//
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		ƒ.Status.NotFound,
+//		ƒ.Problem(&p),
+//	)
+func Problem(out *http.Problem) http.Arrow {
+	return func(cat *http.Context) error {
+		p, err := decodeProblem(cat)
+		if err != nil {
+			return err
+		}
+
+		*out = p
+		return nil
+	}
+}
+
+// Guard guards the response against failures. Non-2xx responses fail
+// with http.ProblemError wrapping the StatusCode error, RFC 7807
+// `application/problem+json` payload is decoded into the error and the
+// variable. Successful responses are intact for sub-sequent receivers.
+// The arrow sends the request unless the response is already received.
+//
+//	var p http.Problem
+//
+//	err := stack.IO(context.Background(),
+//		http.GET(
+//			ø.URI("https://example.com"),
+//			ƒ.Guard(&p),
+//			ƒ.Body(&entity),
+//		),
+//	)
+//
+//	if errors.Is(err, http.StatusNotFound) { /* ... */ }
+func Guard(out *http.Problem) http.Arrow {
+	return func(cat *http.Context) error {
+		if cat.Response == nil {
+			if err := cat.Unsafe(); err != nil {
+				return err
+			}
+		}
+
+		code := cat.Response.StatusCode
+		if code >= 200 && code < 300 {
+			return nil
+		}
+
+		status := http.NewStatusCode(code)
+		if !strings.Contains(cat.Response.Header.Get("Content-Type"), "problem+json") {
+			return status
+		}
+
+		p, err := decodeProblem(cat)
+		if err != nil {
+			return err
+		}
+
+		*out = p
+		return &http.ProblemError{Problem: p, StatusCode: status}
+	}
+}
+
+// ExpectProblem matches RFC 7807 `application/problem+json` payload against
+// expected one. Only non-zero fields of expected value are matched, usually
+// it is type and title of the problem.
+//
+//	ƒ.ExpectProblem(http.Problem{Type: "https://example.com/not-found"})
+func ExpectProblem(expect http.Problem) http.Arrow {
+	return func(cat *http.Context) error {
+		actual, err := decodeProblem(cat)
+		if err != nil {
+			return err
+		}
+
+		if !isSubset(reflect.ValueOf(expect), reflect.ValueOf(actual)) {
+			return &gurl.NoMatch{
+				ID:       "http.Problem",
+				Diff:     cmp.Diff(actual, expect),
+				Protocol: "body",
+				Expect:   expect,
+				Actual:   actual,
+			}
+		}
+
+		return nil
+	}
+}
+
+func decodeProblem(cat *http.Context) (http.Problem, error) {
+	var p http.Problem
+
+	content := cat.Response.Header.Get("Content-Type")
+	defer func() {
+		cat.Response.Body.Close()
+		cat.Response = nil
+	}()
+
+	if !strings.Contains(content, "problem+json") {
+		return p, &gurl.NoMatch{
+			ID:       "http.Problem",
+			Diff:     fmt.Sprintf("- Content-Type: application/problem+json\n+ Content-Type: %s", content),
+			Protocol: "codec",
+			Expect:   "application/problem+json",
+			Actual:   content,
+		}
+	}
+
+	if err := json.NewDecoder(cat.Response.Body).Decode(&p); err != nil {
+		return p, err
+	}
+
+	return p, nil
+}
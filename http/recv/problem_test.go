@@ -0,0 +1,155 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestProblem(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/problem":
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"type":"https://example.com/not-found","title":"Not Found","status":404,"detail":"user joe","instance":"/users/joe","trace":"abc"}`))
+			case "/ok":
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"site":"example.com"}`))
+			default:
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"type":"https://example.com/not-found"}`))
+			}
+		}),
+	)
+	defer ts.Close()
+
+	cat := µ.New()
+	req := func(path string, arrow µ.Arrow) error {
+		return cat.IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/%s", ø.Authority(ts.URL), ø.Path(path)),
+				ƒ.Status.NotFound,
+				arrow,
+			),
+		)
+	}
+
+	t.Run("Decode", func(t *testing.T) {
+		var p µ.Problem
+		err := req("problem", ƒ.Problem(&p))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(p.Type, "https://example.com/not-found"),
+			it.Equal(p.Title, "Not Found"),
+			it.Equal(p.Status, 404),
+			it.Equal(p.Detail, "user joe"),
+			it.Equal(p.Instance, "/users/joe"),
+			it.Equal(p.Extensions["trace"].(string), "abc"),
+		)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		var p µ.Problem
+		err := cat.IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/problem", ø.Authority(ts.URL)),
+				ƒ.Guard(&p),
+				ƒ.Status.OK,
+			),
+		)
+
+		var e *µ.ProblemError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.True(errors.Is(err, µ.StatusNotFound)),
+			it.Equal(e.Type, "https://example.com/not-found"),
+			it.Equal(e.Extensions["trace"].(string), "abc"),
+			it.Equal(p.Title, "Not Found"),
+			it.Equal(err.Error(), "HTTP 404 Not Found: Not Found: user joe"),
+		)
+	})
+
+	t.Run("ErrorNoProblem", func(t *testing.T) {
+		var p µ.Problem
+		err := cat.IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.Guard(&p),
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, µ.StatusNotFound)),
+			it.Equal(p.Type, ""),
+		)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		var (
+			p µ.Problem
+			s struct {
+				Site string `json:"site"`
+			}
+		)
+		err := cat.IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/ok", ø.Authority(ts.URL)),
+				ƒ.Guard(&p),
+				ƒ.Body(&s),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(s.Site, "example.com"),
+		)
+	})
+
+	t.Run("Expect", func(t *testing.T) {
+		err := req("problem",
+			ƒ.ExpectProblem(µ.Problem{Type: "https://example.com/not-found", Title: "Not Found"}),
+		)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("ExpectExtension", func(t *testing.T) {
+		err := req("problem",
+			ƒ.ExpectProblem(µ.Problem{Extensions: map[string]any{"trace": "abc"}}),
+		)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("ExpectNoMatch", func(t *testing.T) {
+		err := req("problem",
+			ƒ.ExpectProblem(µ.Problem{Type: "https://example.com/conflict"}),
+		)
+		it.Then(t).Should(
+			it.True(errors.As(err, new(*gurl.NoMatch))),
+		)
+	})
+
+	t.Run("ContentType", func(t *testing.T) {
+		var p µ.Problem
+		err := req("json", ƒ.Problem(&p))
+		it.Then(t).Should(
+			it.True(errors.As(err, new(*gurl.NoMatch))),
+		)
+	})
+}
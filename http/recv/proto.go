@@ -0,0 +1,60 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"fmt"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+)
+
+//
+// The file implements lenses over protocol version of response
+//
+
+// ProtoOf is a lens over negotiated protocol version of response
+//
+//	ƒ.Proto.Is("HTTP/2.0")
+type ProtoOf int
+
+const Proto = ProtoOf(0)
+
+// Matches protocol version
+func (ProtoOf) Is(proto string) http.Arrow {
+	return func(ctx *http.Context) error {
+		if ctx.Response.Proto != proto {
+			return &gurl.NoMatch{
+				ID:       "http.Proto",
+				Diff:     fmt.Sprintf("+ Proto: %s\n- Proto: %s", ctx.Response.Proto, proto),
+				Protocol: "Proto",
+				Expect:   proto,
+				Actual:   ctx.Response.Proto,
+			}
+		}
+		return nil
+	}
+}
+
+// Lifts protocol version into variable
+func (ProtoOf) To(proto *string) http.Arrow {
+	return func(ctx *http.Context) error {
+		*proto = ctx.Response.Proto
+		return nil
+	}
+}
+
+// Matches HTTP/1.1
+func (p ProtoOf) HTTP11(ctx *http.Context) error { return p.Is("HTTP/1.1")(ctx) }
+
+// Matches HTTP/2.0
+func (p ProtoOf) HTTP2(ctx *http.Context) error { return p.Is("HTTP/2.0")(ctx) }
+
+// Matches HTTP/3.0
+func (p ProtoOf) HTTP3(ctx *http.Context) error { return p.Is("HTTP/3.0")(ctx) }
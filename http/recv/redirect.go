@@ -0,0 +1,96 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"fmt"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+)
+
+//
+// The file implements lenses over redirects
+//
+
+// RedirectOf is a lens over redirect response
+//
+//	ƒ.Redirect.To(&location)
+type RedirectOf int
+
+const Redirect = RedirectOf(0)
+
+// location of redirect resolved against request URL
+func (RedirectOf) location(ctx *http.Context) (string, error) {
+	code := ctx.Response.StatusCode
+	if code < 300 || code >= 400 {
+		return "", &gurl.NoMatch{
+			ID:       "http.Redirect",
+			Diff:     fmt.Sprintf("+ Status Code: %d\n- Status Code: 3xx", code),
+			Protocol: "Location",
+			Expect:   "3xx",
+			Actual:   code,
+		}
+	}
+
+	uri, err := ctx.Response.Location()
+	if err != nil {
+		return "", &gurl.NoMatch{
+			ID:       "http.Redirect",
+			Diff:     "- Location: *",
+			Protocol: "Location",
+		}
+	}
+
+	return uri.String(), nil
+}
+
+// Matches destination of redirect
+func (r RedirectOf) Is(location string) http.Arrow {
+	return func(ctx *http.Context) error {
+		val, err := r.location(ctx)
+		if err != nil {
+			return err
+		}
+
+		if val != location {
+			return &gurl.NoMatch{
+				ID:       "http.Redirect",
+				Diff:     fmt.Sprintf("+ Location: %s\n- Location: %s", val, location),
+				Protocol: "Location",
+				Expect:   location,
+				Actual:   val,
+			}
+		}
+
+		return nil
+	}
+}
+
+// Lifts destination of redirect to variable. It fails if response is not 3xx.
+func (r RedirectOf) To(location *string) http.Arrow {
+	return func(ctx *http.Context) error {
+		val, err := r.location(ctx)
+		if err != nil {
+			return err
+		}
+
+		*location = val
+		return nil
+	}
+}
+
+// Lifts redirect chain to variable, the chain is recorded by stack
+// configured with http.WithFollowRedirects.
+func (RedirectOf) Chain(chain *[]http.Redirect) http.Arrow {
+	return func(ctx *http.Context) error {
+		*chain = append([]http.Redirect{}, ctx.Redirects...)
+		return nil
+	}
+}
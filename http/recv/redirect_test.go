@@ -0,0 +1,95 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestRedirect(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/a":
+				http.Redirect(w, r, "/b", http.StatusMovedPermanently)
+			case "/b":
+				http.Redirect(w, r, "/c", http.StatusFound)
+			case "/loop":
+				http.Redirect(w, r, "/loop", http.StatusFound)
+			default:
+				w.WriteHeader(http.StatusOK)
+			}
+		}),
+	)
+	defer ts.Close()
+
+	t.Run("To", func(t *testing.T) {
+		var location string
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL+"/a"),
+				ƒ.Status.MovedPermanently,
+				ƒ.Redirect.Is(ts.URL+"/b"),
+				ƒ.Redirect.To(&location),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(location, ts.URL+"/b"),
+		)
+	})
+
+	t.Run("NotRedirect", func(t *testing.T) {
+		var location string
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL+"/c"),
+				ƒ.Status.OK,
+				ƒ.Redirect.To(&location),
+			),
+		)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+
+	t.Run("Chain", func(t *testing.T) {
+		var chain []µ.Redirect
+		err := µ.New(µ.WithFollowRedirects(5)).IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL+"/a"),
+				ƒ.Status.OK,
+				ƒ.Redirect.Chain(&chain),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(chain).Equal(
+				µ.Redirect{StatusCode: http.StatusMovedPermanently, URL: ts.URL + "/a", Location: ts.URL + "/b"},
+				µ.Redirect{StatusCode: http.StatusFound, URL: ts.URL + "/b", Location: ts.URL + "/c"},
+			),
+		)
+	})
+
+	t.Run("MaxRedirects", func(t *testing.T) {
+		err := µ.New(µ.WithFollowRedirects(3)).IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL+"/loop"),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+}
@@ -0,0 +1,48 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	µ "github.com/fogfish/gurl/v2/http"
+)
+
+// Response hands a copy of response to the caller for advanced inspection.
+// The payload is fully read and buffered, the copy and sub-sequent arrows
+// read it independently.
+//
+//	var resp http.Response
+//	µ.GET(
+//		ø.URI("https://example.com"),
+//		ƒ.Status.OK,
+//		ƒ.Response(&resp),
+//		ƒ.Body(&data),
+//	)
+func Response(out *http.Response) µ.Arrow {
+	return func(cat *µ.Context) error {
+		buf, err := io.ReadAll(cat.Response.Body)
+		cat.Response.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		cat.Response.Body = io.NopCloser(bytes.NewReader(buf))
+
+		*out = *cat.Response
+		out.Header = cat.Response.Header.Clone()
+		out.Trailer = cat.Response.Trailer.Clone()
+		out.Body = io.NopCloser(bytes.NewReader(buf))
+		out.ContentLength = int64(len(buf))
+
+		return nil
+	}
+}
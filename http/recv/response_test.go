@@ -0,0 +1,55 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestResponse(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	var (
+		resp http.Response
+		site struct {
+			Site string `json:"site"`
+		}
+	)
+
+	err := µ.New().IO(context.Background(),
+		µ.GET(
+			ø.URI("%s/json", ø.Authority(ts.URL)),
+			ƒ.Status.OK,
+			ƒ.Response(&resp),
+			ƒ.Body(&site),
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(resp.StatusCode, 200),
+		it.Equal(resp.Header.Get("Content-Type"), "application/json"),
+		it.Equal(site.Site, "example.com"),
+	)
+
+	body, err := io.ReadAll(resp.Body)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(resp.ContentLength, int64(len(body))),
+		it.True(len(body) > 0),
+	)
+}
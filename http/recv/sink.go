@@ -0,0 +1,88 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fogfish/gurl/v2/http"
+)
+
+//
+// The file implements sinks of decoded values
+//
+
+// Sink receives decoded values, connecting the composition to downstream
+// processing stages.
+type Sink[T any] interface {
+	Put(context.Context, T) error
+}
+
+// ToFunc is the sink handing values to the callback
+func ToFunc[T any](f func(T) error) Sink[T] { return sinkFunc[T](f) }
+
+type sinkFunc[T any] func(T) error
+
+func (f sinkFunc[T]) Put(_ context.Context, val T) error { return f(val) }
+
+// ToSlice is the sink appending values to the slice
+func ToSlice[T any](seq *[]T) Sink[T] { return sinkSlice[T]{seq} }
+
+type sinkSlice[T any] struct{ seq *[]T }
+
+func (s sinkSlice[T]) Put(_ context.Context, val T) error {
+	*s.seq = append(*s.seq, val)
+	return nil
+}
+
+// ToChan is the sink sending values to the channel, sending is cancelled
+// together with context of I/O. The channel is not closed by the sink.
+func ToChan[T any](ch chan<- T) Sink[T] { return sinkChan[T](ch) }
+
+type sinkChan[T any] chan<- T
+
+func (ch sinkChan[T]) Put(ctx context.Context, val T) error {
+	select {
+	case ch <- val:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Into decodes the payload and puts value into the sink. The NDJSON (JSON
+// Lines) payload is streamed record-by-record.
+//
+//	ch := make(chan Event)
+//	http.GET(
+//		ø.URI("https://example.com/feed"),
+//		ƒ.Status.OK,
+//		ƒ.Into(ƒ.ToChan(ch)),
+//	)
+func Into[T any](sink Sink[T]) http.Arrow {
+	return func(cat *http.Context) error {
+		ctx := cat.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		content := cat.Response.Header.Get("Content-Type")
+		if strings.Contains(content, "ndjson") || strings.Contains(content, "jsonl") {
+			return NDJSON(func(val T) error { return sink.Put(ctx, val) })(cat)
+		}
+
+		var val T
+		if err := Body(&val)(cat); err != nil {
+			return err
+		}
+
+		return sink.Put(ctx, val)
+	}
+}
@@ -0,0 +1,101 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestInto(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/feed":
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				w.Write([]byte("{\"id\": 1}\n{\"id\": 2}\n{\"id\": 3}\n"))
+			default:
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id": 10}`))
+			}
+		}),
+	)
+	defer ts.Close()
+
+	type Event struct {
+		ID int `json:"id"`
+	}
+
+	get := func(path string, sink ƒ.Sink[Event]) error {
+		return µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s%s", ø.Authority(ts.URL), ø.Path(path)),
+				ƒ.Status.OK,
+				ƒ.Into(sink),
+			),
+		)
+	}
+
+	t.Run("Slice", func(t *testing.T) {
+		var seq []Event
+		err := get("/feed", ƒ.ToSlice(&seq))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal(Event{1}, Event{2}, Event{3}),
+		)
+	})
+
+	t.Run("Value", func(t *testing.T) {
+		var seq []Event
+		err := get("/json", ƒ.ToSlice(&seq))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal(Event{10}),
+		)
+	})
+
+	t.Run("Chan", func(t *testing.T) {
+		ch := make(chan Event, 3)
+		err := get("/feed", ƒ.ToChan(ch))
+		close(ch)
+
+		var seq []int
+		for e := range ch {
+			seq = append(seq, e.ID)
+		}
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal(1, 2, 3),
+		)
+	})
+
+	t.Run("Func", func(t *testing.T) {
+		abort := errors.New("abort")
+		var seq []int
+		err := get("/feed", ƒ.ToFunc(func(e Event) error {
+			seq = append(seq, e.ID)
+			if e.ID == 2 {
+				return abort
+			}
+			return nil
+		}))
+		it.Then(t).Should(
+			it.True(errors.Is(err, abort)),
+			it.Seq(seq).Equal(1, 2),
+		)
+	})
+}
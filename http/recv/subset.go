@@ -0,0 +1,136 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"reflect"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+	"github.com/google/go-cmp/cmp"
+)
+
+// ExpectSubset matches received payload against expected value, the payload
+// might be a superset of expected one. Zero-valued fields of expected value
+// are ignored, extra keys in received maps are ignored as well. It makes
+// contract tests stable when the server adds new fields to the payload.
+// Numbers are compared by value regardless of the type (e.g. 101 == 101.0).
+//
+//	ƒ.ExpectSubset(User{ID: "joe"})
+//	ƒ.ExpectSubset(map[string]any{"id": "joe"})
+func ExpectSubset[T any](expect T) http.Arrow {
+	return func(cat *http.Context) error {
+		var actual T
+		err := http.HintedContentCodec(
+			cat.Response.Header.Get("Content-Type"),
+			cat.Response.Body,
+			&actual,
+		)
+		cat.Response.Body.Close()
+		cat.Response = nil
+
+		if err != nil {
+			return err
+		}
+
+		if !isSubset(reflect.ValueOf(expect), reflect.ValueOf(actual)) {
+			return &gurl.NoMatch{
+				ID:       "http.Recv",
+				Diff:     cmp.Diff(actual, expect),
+				Protocol: "body",
+				Expect:   expect,
+				Actual:   actual,
+			}
+		}
+
+		return nil
+	}
+}
+
+// isSubset checks that every non-zero value of expect is presented in actual.
+func isSubset(expect, actual reflect.Value) bool {
+	if !expect.IsValid() || expect.IsZero() {
+		return true
+	}
+
+	if !actual.IsValid() {
+		return false
+	}
+
+	for expect.Kind() == reflect.Interface || expect.Kind() == reflect.Pointer {
+		expect = expect.Elem()
+	}
+
+	for actual.Kind() == reflect.Interface || actual.Kind() == reflect.Pointer {
+		if actual.IsNil() {
+			return false
+		}
+		actual = actual.Elem()
+	}
+
+	// Note: numbers are decoded from JSON into float64 for dynamic types
+	//       (e.g. map[string]any), the expectation might use any number.
+	if x, ok := numberOf(expect); ok {
+		y, ok := numberOf(actual)
+		return ok && x == y
+	}
+
+	if expect.Kind() != actual.Kind() {
+		return false
+	}
+
+	switch expect.Kind() {
+	case reflect.Struct:
+		if expect.Type() != actual.Type() {
+			return false
+		}
+		for i := 0; i < expect.NumField(); i++ {
+			if !expect.Type().Field(i).IsExported() {
+				continue
+			}
+			if !isSubset(expect.Field(i), actual.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		for _, key := range expect.MapKeys() {
+			val := actual.MapIndex(key)
+			if !val.IsValid() || !isSubset(expect.MapIndex(key), val) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		if expect.Len() != actual.Len() {
+			return false
+		}
+		for i := 0; i < expect.Len(); i++ {
+			if !isSubset(expect.Index(i), actual.Index(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return expect.Equal(actual)
+	}
+}
+
+func numberOf(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,181 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+)
+
+// TLSCertificate is a lens focused on certificate chain presented by server.
+//
+//	http.GET(
+//		...
+//		ƒ.Certificate.Issuer("R3"),
+//		ƒ.Certificate.DNSName("example.com"),
+//		ƒ.Certificate.ChainLength(3),
+//		ƒ.Certificate.OCSPStapled,
+//	)
+type TLSCertificate int
+
+// Certificate is collection of matchers for server certificate chain
+const Certificate = TLSCertificate(0)
+
+func (TLSCertificate) state(ctx *http.Context) (*tls.ConnectionState, error) {
+	if ctx.Response == nil || ctx.Response.TLS == nil || len(ctx.Response.TLS.PeerCertificates) == 0 {
+		return nil, &gurl.NoMatch{
+			ID:       "http.Certificate",
+			Diff:     "- TLS: certificate\n+ TLS: none",
+			Protocol: "TLS",
+		}
+	}
+
+	return ctx.Response.TLS, nil
+}
+
+func (c TLSCertificate) leaf(ctx *http.Context, f func(*x509.Certificate) error) error {
+	state, err := c.state(ctx)
+	if err != nil {
+		return err
+	}
+
+	return f(state.PeerCertificates[0])
+}
+
+// Issuer matches common name or distinguished name of certificate issuer
+func (c TLSCertificate) Issuer(name string) http.Arrow {
+	return func(ctx *http.Context) error {
+		return c.leaf(ctx, func(cert *x509.Certificate) error {
+			if cert.Issuer.CommonName == name || cert.Issuer.String() == name {
+				return nil
+			}
+
+			return &gurl.NoMatch{
+				ID:       "http.Certificate",
+				Diff:     fmt.Sprintf("+ Issuer: %s\n- Issuer: %s", cert.Issuer.String(), name),
+				Protocol: "TLS",
+				Expect:   name,
+				Actual:   cert.Issuer.String(),
+			}
+		})
+	}
+}
+
+// Subject matches common name or distinguished name of certificate subject
+func (c TLSCertificate) Subject(name string) http.Arrow {
+	return func(ctx *http.Context) error {
+		return c.leaf(ctx, func(cert *x509.Certificate) error {
+			if cert.Subject.CommonName == name || cert.Subject.String() == name {
+				return nil
+			}
+
+			return &gurl.NoMatch{
+				ID:       "http.Certificate",
+				Diff:     fmt.Sprintf("+ Subject: %s\n- Subject: %s", cert.Subject.String(), name),
+				Protocol: "TLS",
+				Expect:   name,
+				Actual:   cert.Subject.String(),
+			}
+		})
+	}
+}
+
+// DNSName matches subject alternative names of the certificate. Wildcard
+// names are respected.
+func (c TLSCertificate) DNSName(name string) http.Arrow {
+	return func(ctx *http.Context) error {
+		return c.leaf(ctx, func(cert *x509.Certificate) error {
+			if err := cert.VerifyHostname(name); err != nil {
+				return &gurl.NoMatch{
+					ID:       "http.Certificate",
+					Diff:     fmt.Sprintf("+ SAN: %v\n- SAN: %s", cert.DNSNames, name),
+					Protocol: "TLS",
+					Expect:   name,
+					Actual:   cert.DNSNames,
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// ValidFor matches that certificate is not expired within the given period.
+func (c TLSCertificate) ValidFor(period time.Duration) http.Arrow {
+	return func(ctx *http.Context) error {
+		return c.leaf(ctx, func(cert *x509.Certificate) error {
+			deadline := ctx.Clock().Now().Add(period)
+			if deadline.After(cert.NotAfter) {
+				return &gurl.NoMatch{
+					ID:       "http.Certificate",
+					Diff:     fmt.Sprintf("+ NotAfter: %s\n- NotAfter: > %s", cert.NotAfter, deadline),
+					Protocol: "TLS",
+					Expect:   period,
+					Actual:   cert.NotAfter,
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// ChainLength matches number of certificates presented by server
+func (c TLSCertificate) ChainLength(n int) http.Arrow {
+	return func(ctx *http.Context) error {
+		state, err := c.state(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(state.PeerCertificates) != n {
+			return &gurl.NoMatch{
+				ID:       "http.Certificate",
+				Diff:     fmt.Sprintf("+ Chain Length: %d\n- Chain Length: %d", len(state.PeerCertificates), n),
+				Protocol: "TLS",
+				Expect:   n,
+				Actual:   len(state.PeerCertificates),
+			}
+		}
+		return nil
+	}
+}
+
+// OCSPStapled matches presence of stapled OCSP response
+func (c TLSCertificate) OCSPStapled(ctx *http.Context) error {
+	state, err := c.state(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(state.OCSPResponse) == 0 {
+		return &gurl.NoMatch{
+			ID:       "http.Certificate",
+			Diff:     "- OCSP: stapled\n+ OCSP: none",
+			Protocol: "TLS",
+		}
+	}
+	return nil
+}
+
+// To lifts certificate chain to variable
+func (c TLSCertificate) To(chain *[]*x509.Certificate) http.Arrow {
+	return func(ctx *http.Context) error {
+		state, err := c.state(ctx)
+		if err != nil {
+			return err
+		}
+
+		*chain = state.PeerCertificates
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestCertificate(t *testing.T) {
+	ts := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer ts.Close()
+
+	cat := µ.New(µ.WithInsecureTLS())
+
+	t.Run("Match", func(t *testing.T) {
+		var chain []*x509.Certificate
+		err := cat.IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.Certificate.Issuer("O=Acme Co"),
+				ƒ.Certificate.Subject("O=Acme Co"),
+				ƒ.Certificate.DNSName("example.com"),
+				ƒ.Certificate.ChainLength(1),
+				ƒ.Certificate.ValidFor(24*time.Hour),
+				ƒ.Certificate.To(&chain),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(chain), 1),
+		)
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		for _, arrow := range []µ.Arrow{
+			ƒ.Certificate.Issuer("R3"),
+			ƒ.Certificate.Subject("R3"),
+			ƒ.Certificate.DNSName("example.org"),
+			ƒ.Certificate.ChainLength(3),
+			ƒ.Certificate.ValidFor(200 * 365 * 24 * time.Hour),
+			ƒ.Certificate.OCSPStapled,
+		} {
+			err := cat.IO(context.Background(),
+				µ.GET(
+					ø.URI(ts.URL),
+					ƒ.Status.OK,
+					arrow,
+				),
+			)
+			it.Then(t).ShouldNot(it.Nil(err))
+		}
+	})
+
+	t.Run("NoTLS", func(t *testing.T) {
+		ts := mock()
+		defer ts.Close()
+
+		err := cat.IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Certificate.ChainLength(1),
+			),
+		)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+}
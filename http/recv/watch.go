@@ -0,0 +1,174 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/fogfish/gurl/v2/http"
+)
+
+//
+// The file implements decoder of Kubernetes-style watch streams, chunked
+// `application/json` payload of {"type": ..., "object": ...} envelopes.
+//
+
+// Types of watch events
+const (
+	WatchAdded    = "ADDED"
+	WatchModified = "MODIFIED"
+	WatchDeleted  = "DELETED"
+	WatchBookmark = "BOOKMARK"
+	WatchError    = "ERROR"
+)
+
+// WatchEvent is the envelope of watch stream
+type WatchEvent[T any] struct {
+	Type   string `json:"type"`
+	Object T      `json:"object"`
+}
+
+// WatchCursor tracks the resourceVersion of the watch stream, it is used
+// to resume the stream after reconnect.
+//
+//	var cursor ƒ.WatchCursor
+//
+//	http.GET(
+//		ø.URI("https://example.com/api/v1/pods"),
+//		ø.Param("watch", true),
+//		ø.Param("allowWatchBookmarks", true),
+//		ø.Param("resourceVersion", cursor.Version),
+//		ƒ.Status.OK,
+//		ƒ.Watch(&cursor, func(e ƒ.WatchEvent[Pod]) error { /* ... */ }),
+//	)
+type WatchCursor struct {
+	mu      sync.Mutex
+	version string
+}
+
+// ResourceVersion of the last observed event
+func (c *WatchCursor) ResourceVersion() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+// Version is lazy value of resourceVersion, use it with ø.Param
+func (c *WatchCursor) Version() string { return c.ResourceVersion() }
+
+// Reset the cursor, the stream continues from the most recent state
+func (c *WatchCursor) Reset() { c.set("") }
+
+func (c *WatchCursor) set(version string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version = version
+}
+
+// WatchStatusError is the failure reported by ERROR event of watch stream.
+// The expired resourceVersion is reported as 410 Gone, the cursor is reset.
+type WatchStatusError struct {
+	Reason     string
+	Message    string
+	StatusCode http.StatusCode
+}
+
+func (e *WatchStatusError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.StatusCode.Error(), e.Reason, e.Message)
+}
+
+func (e *WatchStatusError) Unwrap() error { return e.StatusCode }
+
+type watchEnvelope struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+type watchMetadata struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+}
+
+type watchStatus struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// Watch decodes Kubernetes-style watch stream event-by-event and hands
+// each event to the callback. The resourceVersion of events is recorded
+// into the cursor (optional) once the callback succeeds, bookmarks update
+// the cursor only and they are not passed to the callback. The ERROR event terminates the stream
+// with WatchStatusError.
+func Watch[T any](cursor *WatchCursor, f func(WatchEvent[T]) error) http.Arrow {
+	if cursor == nil {
+		cursor = &WatchCursor{}
+	}
+
+	return Stream(func(r io.Reader) error {
+		codec := json.NewDecoder(r)
+
+		for seq := 1; ; seq++ {
+			var env watchEnvelope
+			if err := codec.Decode(&env); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return fmt.Errorf("watch event %d: %w", seq, err)
+			}
+
+			if env.Type == WatchError {
+				var status watchStatus
+				if err := json.Unmarshal(env.Object, &status); err != nil {
+					return fmt.Errorf("watch event %d: %w", seq, err)
+				}
+				if status.Code == http.StatusGone.StatusCode() {
+					cursor.Reset()
+				}
+				return &WatchStatusError{
+					Reason:     status.Reason,
+					Message:    status.Message,
+					StatusCode: http.NewStatusCode(status.Code),
+				}
+			}
+
+			var meta watchMetadata
+			if err := json.Unmarshal(env.Object, &meta); err != nil {
+				return fmt.Errorf("watch event %d: %w", seq, err)
+			}
+
+			if env.Type == WatchBookmark {
+				if meta.Metadata.ResourceVersion != "" {
+					cursor.set(meta.Metadata.ResourceVersion)
+				}
+				continue
+			}
+
+			evt := WatchEvent[T]{Type: env.Type}
+			if err := json.Unmarshal(env.Object, &evt.Object); err != nil {
+				return fmt.Errorf("watch event %d: %w", seq, err)
+			}
+
+			if err := f(evt); err != nil {
+				return err
+			}
+
+			// Note: the cursor advances only if the event is handled, so that
+			//       the stream resumes from failed event.
+			if meta.Metadata.ResourceVersion != "" {
+				cursor.set(meta.Metadata.ResourceVersion)
+			}
+		}
+	})
+}
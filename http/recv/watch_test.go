@@ -0,0 +1,110 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestWatch(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Query().Get("resourceVersion") {
+			case "":
+				for _, chunk := range []string{
+					`{"type":"ADDED","object":{"metadata":{"name":"a","resourceVersion":"1"}}}`,
+					`{"type":"MODIFIED","object":{"metadata":{"name":"a","resourceVersion":"2"}}}`,
+					`{"type":"BOOKMARK","object":{"metadata":{"resourceVersion":"5"}}}`,
+				} {
+					w.Write([]byte(chunk + "\n"))
+					w.(http.Flusher).Flush()
+				}
+			case "5":
+				w.Write([]byte(`{"type":"DELETED","object":{"metadata":{"name":"a","resourceVersion":"6"}}}`))
+				w.Write([]byte(`{"type":"ERROR","object":{"kind":"Status","reason":"Expired","message":"too old","code":410}}`))
+			}
+		}),
+	)
+	defer ts.Close()
+
+	type Pod struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+
+	var cursor ƒ.WatchCursor
+	var seq []string
+	watch := µ.GET(
+		ø.URI(ts.URL),
+		ø.Param("watch", true),
+		ø.Param("resourceVersion", cursor.Version),
+		ƒ.Status.OK,
+		ƒ.Watch(&cursor, func(e ƒ.WatchEvent[Pod]) error {
+			seq = append(seq, e.Type+":"+e.Object.Metadata.Name)
+			return nil
+		}),
+	)
+
+	t.Run("Stream", func(t *testing.T) {
+		err := µ.New().IO(context.Background(), watch)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal("ADDED:a", "MODIFIED:a"),
+			it.Equal(cursor.ResourceVersion(), "5"),
+		)
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		seq = nil
+		err := µ.New().IO(context.Background(), watch)
+
+		var e *ƒ.WatchStatusError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.True(errors.Is(err, µ.StatusGone)),
+			it.Equal(e.Reason, "Expired"),
+			it.Seq(seq).Equal("DELETED:a"),
+			it.Equal(cursor.ResourceVersion(), ""),
+		)
+	})
+
+	t.Run("CallbackFailure", func(t *testing.T) {
+		failure := errors.New("failed")
+
+		var cursor ƒ.WatchCursor
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ø.Param("watch", true),
+				ƒ.Status.OK,
+				ƒ.Watch(&cursor, func(e ƒ.WatchEvent[Pod]) error {
+					if e.Type == ƒ.WatchModified {
+						return failure
+					}
+					return nil
+				}),
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, failure)),
+			it.Equal(cursor.ResourceVersion(), "1"),
+		)
+	})
+}
@@ -0,0 +1,60 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+//
+// The file implements capture of redirect chain
+//
+
+// Redirect is a hop of the redirect chain
+type Redirect struct {
+	StatusCode int
+	URL        string
+	Location   string
+}
+
+type redirectTraceKey struct{}
+
+func withRedirectTrace(ctx *Context, eg *http.Request) *http.Request {
+	return eg.WithContext(context.WithValue(eg.Context(), redirectTraceKey{}, ctx))
+}
+
+func withFollowRedirects(cat *Protocol, max int) error {
+	cli, err := clientOf(cat)
+	if err != nil {
+		return err
+	}
+
+	cli.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if ctx, ok := req.Context().Value(redirectTraceKey{}).(*Context); ok && req.Response != nil {
+			ctx.Redirects = append(ctx.Redirects,
+				Redirect{
+					StatusCode: req.Response.StatusCode,
+					URL:        via[len(via)-1].URL.String(),
+					Location:   req.URL.String(),
+				},
+			)
+		}
+
+		if len(via) > max {
+			return fmt.Errorf("stopped after %d redirects", max)
+		}
+
+		return nil
+	}
+
+	cat.redirects = true
+	return nil
+}
@@ -0,0 +1,114 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"sort"
+	"sync"
+)
+
+//
+// The file implements registry of suites, the single source of truth
+// for runners, filters and documentation generators.
+//
+
+// Suite is the registered specification with its metadata
+type Suite struct {
+	Name   string       `json:"name"`
+	Tags   []string     `json:"tags,omitempty"`
+	Source string       `json:"source,omitempty"`
+	Spec   func() Arrow `json:"-"`
+}
+
+// HasTag returns true if suite is labelled with any of tags
+func (s Suite) HasTag(tags ...string) bool {
+	for _, tag := range tags {
+		if slices.Contains(s.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+var registry = struct {
+	sync.Mutex
+	suites map[string]Suite
+}{suites: map[string]Suite{}}
+
+// Register the suite within the global registry. The registration is
+// expected from package init, it panics if name is registered twice.
+//
+//	func init() {
+//		http.Register("user can register", []string{"smoke"}, TestRegister)
+//	}
+func Register(name string, tags []string, f func() Arrow) {
+	if f == nil {
+		panic("http: Register suite is nil " + name)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, has := registry.suites[name]; has {
+		panic("http: Register called twice for suite " + name)
+	}
+
+	suite := Suite{
+		Name: name,
+		Tags: append([]string{}, tags...),
+		Spec: f,
+	}
+	if _, file, line, ok := runtime.Caller(1); ok {
+		suite.Source = fmt.Sprintf("%s:%d", file, line)
+	}
+
+	registry.suites[name] = suite
+}
+
+// Lookup the registered suite by name
+func Lookup(name string) (Suite, bool) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	suite, has := registry.suites[name]
+	return suite, has
+}
+
+// Suites enumerates registered suites ordered by name. Only suites labelled
+// with any of given tags are returned if tags are defined.
+func Suites(tags ...string) []Suite {
+	registry.Lock()
+	defer registry.Unlock()
+
+	seq := make([]Suite, 0, len(registry.suites))
+	for _, suite := range registry.suites {
+		if len(tags) == 0 || suite.HasTag(tags...) {
+			seq = append(seq, suite)
+		}
+	}
+
+	sort.Slice(seq, func(i, j int) bool { return seq[i].Name < seq[j].Name })
+	return seq
+}
+
+// WriteManifest outputs machine-readable (JSON) manifest of suites
+func WriteManifest(w io.Writer, suites []Suite) error {
+	bytes, err := json.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(bytes)
+	return err
+}
@@ -0,0 +1,104 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func registryOk() http.Arrow {
+	return http.GET(ø.URI("/json"), ƒ.Status.OK)
+}
+
+func registryFail() http.Arrow {
+	return http.GET(ø.URI("/json"), ƒ.Status.Created)
+}
+
+func init() {
+	http.Register("registry/b", []string{"smoke"}, registryOk)
+	http.Register("registry/a", []string{"smoke", "users"}, registryFail)
+	http.Register("registry/c", nil, registryOk)
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("Lookup", func(t *testing.T) {
+		suite, has := http.Lookup("registry/a")
+		_, none := http.Lookup("registry/none")
+		it.Then(t).Should(
+			it.True(has),
+			it.True(!none),
+			it.Equal(suite.Name, "registry/a"),
+			it.Seq(suite.Tags).Equal("smoke", "users"),
+			it.True(strings.Contains(suite.Source, "registry_test.go")),
+		)
+	})
+
+	t.Run("Suites", func(t *testing.T) {
+		names := func(seq []http.Suite) []string {
+			out := []string{}
+			for _, s := range seq {
+				if strings.HasPrefix(s.Name, "registry/") {
+					out = append(out, s.Name)
+				}
+			}
+			return out
+		}
+
+		it.Then(t).Should(
+			it.Seq(names(http.Suites())).Equal("registry/a", "registry/b", "registry/c"),
+			it.Seq(names(http.Suites("smoke"))).Equal("registry/a", "registry/b"),
+			it.Seq(names(http.Suites("users"))).Equal("registry/a"),
+		)
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		defer func() {
+			it.Then(t).ShouldNot(it.Nil(recover()))
+		}()
+
+		http.Register("registry/a", nil, registryOk)
+	})
+
+	t.Run("Manifest", func(t *testing.T) {
+		buf := bytes.Buffer{}
+		err := http.WriteManifest(&buf, http.Suites("users"))
+		it.Then(t).Should(it.Nil(err))
+
+		var seq []map[string]any
+		err = json.Unmarshal(buf.Bytes(), &seq)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(seq), 1),
+			it.Equal(seq[0]["name"].(string), "registry/a"),
+		)
+	})
+
+	t.Run("OnceSuites", func(t *testing.T) {
+		ts := mock()
+		defer ts.Close()
+
+		hts := http.New(http.WithHost(ts.URL))
+		seq := http.OnceSuites(hts, http.Suites("smoke")...)
+		it.Then(t).Should(
+			it.Equal(len(seq), 2),
+			it.Equal(seq[0].ID, "registry/a"),
+			it.Equal(seq[0].Status, "nomatch"),
+			it.Equal(seq[1].ID, "registry/b"),
+			it.Equal(seq[1].Status, "success"),
+		)
+	})
+}
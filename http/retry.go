@@ -0,0 +1,152 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//
+// The file implements retry combinator and history of attempts
+//
+
+// Attempt is a record about failed evaluation of the arrow
+type Attempt struct {
+	Time       time.Time     `json:"time"`
+	Reason     string        `json:"reason"`
+	StatusCode int           `json:"status,omitempty"`
+	Backoff    time.Duration `json:"backoff,omitempty"`
+	err        error
+}
+
+// Error returns the failure of attempt
+func (a Attempt) Error() error { return a.err }
+
+// RetryError is returned when all attempts are exhausted. It carries
+// the history of attempts and wraps the last failure.
+type RetryError struct {
+	Attempts []Attempt
+	Tags     map[string]string
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("failed after %d attempts: %s", len(e.Attempts), e.Err)
+}
+
+func (e *RetryError) Unwrap() error { return e.Err }
+
+// Retry evaluates arrows until success, up to given number of attempts.
+// The backoff between attempts doubles after each failure. The Retry-After
+// header of 429 Too Many Requests and 503 Service Unavailable responses
+// overrides the backoff. Each failed attempt is recorded at Context, the
+// RetryError carries attempts of this combinator only. It panics if number
+// of attempts is less than one.
+//
+//	http.Retry(3, 100*time.Millisecond,
+//		http.GET(
+//			ø.URI("https://example.com"),
+//			ƒ.Status.OK,
+//		),
+//	)
+func Retry(attempts int, backoff time.Duration, arrows ...Arrow) Arrow {
+	if attempts < 1 {
+		panic("http.Retry requires at least one attempt")
+	}
+
+	return func(ctx *Context) error {
+		var err error
+		delay := backoff
+		history := make([]Attempt, 0, attempts)
+
+		for i := 0; i < attempts; i++ {
+			if err = Join(arrows...)(ctx); err == nil {
+				return nil
+			}
+
+			attempt := Attempt{
+				Time:   ctx.Clock().Now(),
+				Reason: err.Error(),
+				err:    err,
+			}
+			wait := delay
+			if ctx.Response != nil {
+				attempt.StatusCode = ctx.Response.StatusCode
+				if after, ok := retryAfter(ctx.Response, ctx.Clock().Now()); ok {
+					wait = after
+				}
+			}
+
+			if derr := ctx.discardBody(); derr != nil {
+				return derr
+			}
+
+			if i == attempts-1 {
+				history = append(history, attempt)
+				ctx.Attempts = append(ctx.Attempts, attempt)
+				break
+			}
+
+			attempt.Backoff = wait
+			history = append(history, attempt)
+			ctx.Attempts = append(ctx.Attempts, attempt)
+
+			if serr := ctx.sleep(wait); serr != nil {
+				return &RetryError{Attempts: history, Tags: maps.Clone(ctx.Tags), Err: serr}
+			}
+			delay = delay * 2
+		}
+
+		return &RetryError{Attempts: history, Tags: maps.Clone(ctx.Tags), Err: err}
+	}
+}
+
+// retryAfter parses Retry-After header, either delay in seconds or http date
+func retryAfter(in *http.Response, now time.Time) (time.Duration, bool) {
+	if in.StatusCode != http.StatusTooManyRequests && in.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	val := in.Header.Get("Retry-After")
+	if val == "" {
+		return 0, false
+	}
+
+	if sec, err := strconv.Atoi(val); err == nil && sec >= 0 {
+		return time.Duration(sec) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(val); err == nil {
+		d := at.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+func (ctx *Context) sleep(d time.Duration) error {
+	if ctx.Context == nil {
+		<-ctx.Clock().After(d)
+		return nil
+	}
+
+	select {
+	case <-ctx.Clock().After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
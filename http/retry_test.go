@@ -0,0 +1,193 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func flaky(n int) *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if n > 0 {
+				n--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+}
+
+func TestRetry(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ts := flaky(2)
+		defer ts.Close()
+
+		ctx := µ.New().WithContext(context.Background())
+		err := ctx.IO(
+			µ.Retry(3, time.Millisecond,
+				µ.GET(
+					ø.URI(ts.URL),
+					ƒ.Status.OK,
+				),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(ctx.Attempts), 2),
+			it.Equal(ctx.Attempts[0].StatusCode, http.StatusServiceUnavailable),
+			it.Equal(ctx.Attempts[0].Backoff, time.Millisecond),
+			it.Equal(ctx.Attempts[1].Backoff, 2*time.Millisecond),
+		)
+	})
+
+	t.Run("RetryAfter", func(t *testing.T) {
+		n := 1
+		ts := httptest.NewServer(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if n > 0 {
+					n--
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}),
+		)
+		defer ts.Close()
+
+		ctx := µ.New().WithContext(context.Background())
+		err := ctx.IO(
+			µ.Retry(3, time.Hour,
+				µ.GET(
+					ø.URI(ts.URL),
+					ƒ.Status.OK,
+				),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(ctx.Attempts), 1),
+			it.Equal(ctx.Attempts[0].StatusCode, http.StatusTooManyRequests),
+			it.Equal(ctx.Attempts[0].Backoff, 0),
+		)
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		ts := flaky(5)
+		defer ts.Close()
+
+		ctx := µ.New().WithContext(context.Background())
+		err := ctx.IO(
+			µ.Retry(3, time.Millisecond,
+				µ.GET(
+					ø.URI(ts.URL),
+					ƒ.Status.OK,
+				),
+			),
+		)
+
+		var rerr *µ.RetryError
+		it.Then(t).Should(
+			it.True(errors.As(err, &rerr)),
+			it.Equal(len(rerr.Attempts), 3),
+			it.Equal(rerr.Attempts[2].Backoff, 0),
+		)
+	})
+
+	t.Run("Sequential", func(t *testing.T) {
+		ts := flaky(1)
+		defer ts.Close()
+
+		down := flaky(5)
+		defer down.Close()
+
+		ctx := µ.New().WithContext(context.Background())
+		err := ctx.IO(
+			µ.Retry(3, time.Millisecond, µ.GET(ø.URI(ts.URL), ƒ.Status.OK)),
+			µ.Retry(2, time.Millisecond, µ.GET(ø.URI(down.URL), ƒ.Status.OK)),
+		)
+
+		var rerr *µ.RetryError
+		it.Then(t).Should(
+			it.True(errors.As(err, &rerr)),
+			it.Equal(len(rerr.Attempts), 2),
+			it.Equal(len(ctx.Attempts), 3),
+		)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		defer func() {
+			it.Then(t).ShouldNot(it.Nil(recover()))
+		}()
+
+		µ.Retry(0, time.Millisecond, µ.GET(ø.URI("https://example.com")))
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ts := flaky(5)
+		defer ts.Close()
+
+		c, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		ctx := µ.New().WithContext(c)
+		err := ctx.IO(
+			µ.Retry(3, time.Second,
+				µ.GET(
+					ø.URI(ts.URL),
+					ƒ.Status.OK,
+				),
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, context.DeadlineExceeded)),
+		)
+	})
+
+	t.Run("WriteOnce", func(t *testing.T) {
+		ts := flaky(1)
+		defer ts.Close()
+
+		unittest := func() µ.Arrow {
+			return µ.Retry(3, time.Millisecond,
+				µ.GET(
+					ø.URI(ts.URL),
+					ƒ.Status.OK,
+				),
+			)
+		}
+
+		buf := bytes.Buffer{}
+		err := µ.WriteOnce(&buf, µ.New(), unittest)
+		it.Then(t).Should(it.Nil(err))
+
+		var seq []µ.Status
+		err = json.Unmarshal(buf.Bytes(), &seq)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(seq[0].Status, "success"),
+			it.Equal(len(seq[0].Attempts), 1),
+			it.Equal(seq[0].Attempts[0].StatusCode, http.StatusServiceUnavailable),
+		)
+	})
+}
@@ -0,0 +1,67 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+//
+// The file implements rewriting of responses before arrows see them
+//
+
+// Rewrite of HTTP response, it modifies headers or body of the response.
+type Rewrite = func(*http.Response) error
+
+func withRewrite(cat *Protocol, f Rewrite) error {
+	cat.rewrite = append(cat.rewrite, f)
+	return nil
+}
+
+// UnwrapJSON strips vendor envelope off JSON response, replacing the body
+// with the value of the key. Responses of other content types and
+// responses without the key are intact.
+//
+//	// {"data": {"id": 1}} ⟼ {"id": 1}
+//	http.New(http.WithRewrite(http.UnwrapJSON("data")))
+func UnwrapJSON(key string) Rewrite {
+	return func(in *http.Response) error {
+		if !strings.Contains(in.Header.Get("Content-Type"), "json") {
+			return nil
+		}
+
+		buf, err := io.ReadAll(in.Body)
+		in.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		var envelope map[string]json.RawMessage
+		if err := json.Unmarshal(buf, &envelope); err != nil {
+			in.Body = io.NopCloser(bytes.NewReader(buf))
+			return nil
+		}
+
+		val, has := envelope[key]
+		if !has {
+			in.Body = io.NopCloser(bytes.NewReader(buf))
+			return nil
+		}
+
+		in.Body = io.NopCloser(bytes.NewReader(val))
+		in.ContentLength = int64(len(val))
+		in.Header.Set("Content-Length", fmt.Sprint(len(val)))
+		return nil
+	}
+}
@@ -0,0 +1,73 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestRewrite(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	t.Run("UnwrapJSON", func(t *testing.T) {
+		var site string
+		err := µ.New(µ.WithRewrite(µ.UnwrapJSON("site"))).IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Body(&site),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(site, "example.com"),
+		)
+	})
+
+	t.Run("UnwrapJSONNoKey", func(t *testing.T) {
+		var site struct {
+			Site string `json:"site"`
+		}
+		err := µ.New(µ.WithRewrite(µ.UnwrapJSON("data"))).IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.Body(&site),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(site.Site, "example.com"),
+		)
+	})
+
+	t.Run("Header", func(t *testing.T) {
+		vendor := func(in *http.Response) error {
+			in.Header.Set("Content-Type", "application/vnd.example+json")
+			return nil
+		}
+
+		err := µ.New(µ.WithRewrite(vendor)).IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+				ƒ.ContentType.Is("application/vnd.example+json"),
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+	})
+}
@@ -0,0 +1,87 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"fmt"
+)
+
+//
+// The file implements scenario layer, the Behaviour-as-Code
+//
+
+// ScenarioError is the failure of scenario, it labels the error with
+// the name of scenario and the stage (given, when, then).
+type ScenarioError struct {
+	Scenario string
+	Stage    string
+	Err      error
+}
+
+func (e *ScenarioError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", e.Scenario, e.Stage, e.Err)
+}
+
+func (e *ScenarioError) Unwrap() error { return e.Err }
+
+// ScenarioSpec is a sequence of labelled stages
+type ScenarioSpec struct {
+	name   string
+	stages []scenarioStage
+}
+
+type scenarioStage struct {
+	label  string
+	arrows []Arrow
+}
+
+// Scenario declares the behaviour using Given, When and Then stages.
+// Stages are joined in the order of declaration, the failure is reported
+// with labels of scenario and stage.
+//
+//	http.Scenario("user can register").
+//		Given(http.POST(ø.URI("/users"), ø.Send(user), ƒ.Status.Created)).
+//		When(http.GET(ø.URI("/users/joe"), ƒ.Status.OK, ƒ.Body(&profile))).
+//		Then(func(*http.Context) error { /* ... */ })
+func Scenario(name string) *ScenarioSpec {
+	return &ScenarioSpec{name: name}
+}
+
+func (s *ScenarioSpec) stage(label string, arrows []Arrow) *ScenarioSpec {
+	return &ScenarioSpec{
+		name:   s.name,
+		stages: append(append([]scenarioStage{}, s.stages...), scenarioStage{label: label, arrows: arrows}),
+	}
+}
+
+// Given declares preconditions of scenario
+func (s *ScenarioSpec) Given(arrows ...Arrow) *ScenarioSpec {
+	return s.stage("given", arrows)
+}
+
+// When declares the action of scenario
+func (s *ScenarioSpec) When(arrows ...Arrow) *ScenarioSpec {
+	return s.stage("when", arrows)
+}
+
+// Then declares outcome of scenario, it completes the scenario.
+func (s *ScenarioSpec) Then(arrows ...Arrow) Arrow {
+	spec := s.stage("then", arrows)
+
+	return func(ctx *Context) error {
+		for _, stage := range spec.stages {
+			for _, f := range stage.arrows {
+				if err := f(ctx); err != nil {
+					return &ScenarioError{Scenario: spec.name, Stage: stage.label, Err: err}
+				}
+			}
+		}
+		return nil
+	}
+}
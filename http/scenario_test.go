@@ -0,0 +1,84 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestScenario(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	scenario := func(code µ.StatusCode) µ.Arrow {
+		return µ.Scenario("site is available").
+			Given(
+				µ.GET(
+					ø.URI("/ok"),
+					ƒ.Status.OK,
+				),
+			).
+			When(
+				µ.GET(
+					ø.URI("/json"),
+					ƒ.Code(code),
+				),
+			).
+			Then()
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		err := µ.New(µ.WithHost(ts.URL)).IO(context.Background(), scenario(µ.StatusOK))
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		err := µ.New(µ.WithHost(ts.URL)).IO(context.Background(), scenario(µ.StatusNotFound))
+
+		var e *µ.ScenarioError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.True(errors.As(err, new(*gurl.NoMatch))),
+			it.Equal(e.Scenario, "site is available"),
+			it.Equal(e.Stage, "when"),
+			it.Equal(err.Error(), "site is available: when: + Status Code: 200\n- Status Code: 404"),
+		)
+	})
+
+	t.Run("Once", func(t *testing.T) {
+		status := µ.Once(µ.New(µ.WithHost(ts.URL)),
+			func() µ.Arrow { return scenario(µ.StatusNotFound) },
+		)
+		it.Then(t).Should(
+			it.Equal(status[0].Status, "nomatch"),
+			it.Equal(status[0].Scenario, "site is available"),
+			it.Equal(status[0].Stage, "when"),
+			it.Equal(status[0].Reason, "+ Status Code: 200\n- Status Code: 404"),
+		)
+	})
+	t.Run("Wrapped", func(t *testing.T) {
+		status := µ.Once(µ.New(µ.WithHost(ts.URL)),
+			func() µ.Arrow { return µ.Retry(1, time.Millisecond, scenario(µ.StatusNotFound)) },
+		)
+		it.Then(t).Should(
+			it.Equal(status[0].Status, "nomatch"),
+			it.Equal(status[0].Scenario, "site is available"),
+			it.Equal(status[0].Stage, "when"),
+		)
+	})
+}
@@ -0,0 +1,195 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//
+// The file implements inference of JSON schema from observed responses,
+// the living documentation of actual API behaviour.
+//
+
+// Schema is JSON schema inferred from observed payloads. Properties are
+// required if they are observed in every payload.
+type Schema struct {
+	Type       []string           `json:"type,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+}
+
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type schema Schema
+	if len(s.Type) != 1 {
+		return json.Marshal((*schema)(s))
+	}
+
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		*schema
+	}{Type: s.Type[0], schema: (*schema)(s)})
+}
+
+// SchemaInference catalogs schemas of JSON responses per endpoint
+// (method and path) observed across the suite run.
+type SchemaInference struct {
+	sync.Mutex
+	schemas map[string]*Schema
+}
+
+// Creates new instance of schema inference catalog
+func NewSchemaInference() *SchemaInference {
+	return &SchemaInference{schemas: map[string]*Schema{}}
+}
+
+// Schema of endpoint (e.g. "GET /users")
+func (si *SchemaInference) Schema(endpoint string) (*Schema, bool) {
+	si.Lock()
+	defer si.Unlock()
+
+	schema, has := si.schemas[endpoint]
+	return schema, has
+}
+
+// Schemas of all observed endpoints
+func (si *SchemaInference) Schemas() map[string]*Schema {
+	si.Lock()
+	defer si.Unlock()
+
+	schemas := make(map[string]*Schema, len(si.schemas))
+	for endpoint, schema := range si.schemas {
+		schemas[endpoint] = schema
+	}
+	return schemas
+}
+
+func (si *SchemaInference) observe(eg *http.Request, in *http.Response, payload []byte) {
+	if !strings.Contains(in.Header.Get("Content-Type"), "json") || len(payload) == 0 {
+		return
+	}
+
+	var value any
+	codec := json.NewDecoder(bytes.NewReader(payload))
+	codec.UseNumber()
+	if err := codec.Decode(&value); err != nil {
+		return
+	}
+
+	endpoint := endpointOf(eg)
+	schema := inferSchema(value)
+
+	si.Lock()
+	defer si.Unlock()
+	si.schemas[endpoint] = mergeSchema(si.schemas[endpoint], schema)
+}
+
+func endpointOf(eg *http.Request) string {
+	return eg.Method + " " + eg.URL.Path
+}
+
+func inferSchema(value any) *Schema {
+	switch v := value.(type) {
+	case nil:
+		return &Schema{Type: []string{"null"}}
+	case bool:
+		return &Schema{Type: []string{"boolean"}}
+	case string:
+		return &Schema{Type: []string{"string"}}
+	case json.Number:
+		if strings.ContainsAny(string(v), ".eE") {
+			return &Schema{Type: []string{"number"}}
+		}
+		return &Schema{Type: []string{"integer"}}
+	case []any:
+		schema := &Schema{Type: []string{"array"}}
+		for _, x := range v {
+			schema.Items = mergeSchema(schema.Items, inferSchema(x))
+		}
+		return schema
+	case map[string]any:
+		schema := &Schema{
+			Type:       []string{"object"},
+			Properties: make(map[string]*Schema, len(v)),
+			Required:   make([]string, 0, len(v)),
+		}
+		for key, x := range v {
+			schema.Properties[key] = inferSchema(x)
+			schema.Required = append(schema.Required, key)
+		}
+		sort.Strings(schema.Required)
+		return schema
+	default:
+		return &Schema{}
+	}
+}
+
+func mergeSchema(a, b *Schema) *Schema {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	schema := &Schema{Type: mergeTypes(a.Type, b.Type)}
+
+	if a.Properties != nil || b.Properties != nil {
+		schema.Properties = map[string]*Schema{}
+		for key, x := range a.Properties {
+			schema.Properties[key] = mergeSchema(x, b.Properties[key])
+		}
+		for key, x := range b.Properties {
+			if _, has := a.Properties[key]; !has {
+				schema.Properties[key] = x
+			}
+		}
+	}
+
+	// Note: objects of other type do not constraint properties
+	switch {
+	case !slices.Contains(a.Type, "object"):
+		schema.Required = b.Required
+	case !slices.Contains(b.Type, "object"):
+		schema.Required = a.Required
+	default:
+		for _, key := range a.Required {
+			if slices.Contains(b.Required, key) {
+				schema.Required = append(schema.Required, key)
+			}
+		}
+	}
+
+	schema.Items = mergeSchema(a.Items, b.Items)
+
+	return schema
+}
+
+func mergeTypes(a, b []string) []string {
+	seq := append(append([]string{}, a...), b...)
+	if slices.Contains(seq, "number") {
+		seq = slices.DeleteFunc(seq, func(t string) bool { return t == "integer" })
+	}
+
+	sort.Strings(seq)
+	return slices.Compact(seq)
+}
+
+func withSchemaInference(cat *Protocol, si *SchemaInference) error {
+	cat.Memento = true
+	cat.schemas = si
+	return nil
+}
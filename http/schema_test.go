@@ -0,0 +1,79 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestSchemaInference(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Query().Get("v") {
+			case "1":
+				w.Write([]byte(`{"id": "a", "age": 10, "tags": ["x"], "address": {"city": "Helsinki"}}`))
+			default:
+				w.Write([]byte(`{"id": "b", "age": 10.5, "tags": [], "email": null}`))
+			}
+		}),
+	)
+	defer ts.Close()
+
+	request := func(v string) func() µ.Arrow {
+		return func() µ.Arrow {
+			return µ.GET(
+				ø.URI("/users"),
+				ø.Param("v", v),
+				ƒ.Status.OK,
+			)
+		}
+	}
+
+	schemas := µ.NewSchemaInference()
+	stack := µ.New(µ.WithHost(ts.URL), µ.WithSchemaInference(schemas))
+
+	seq := µ.Once(stack, request("1"), request("2"))
+	schema, has := schemas.Schema("GET /users")
+
+	it.Then(t).Should(
+		it.True(has),
+		it.Equal(len(seq), 2),
+		it.Equal(seq[0].Endpoint, "GET /users"),
+		it.Equal(seq[0].Schema, schema),
+		it.Equal(seq[1].Schema, schema),
+		it.Seq(schema.Type).Equal("object"),
+		it.Seq(schema.Required).Equal("age", "id", "tags"),
+		it.Seq(schema.Properties["age"].Type).Equal("number"),
+		it.Seq(schema.Properties["id"].Type).Equal("string"),
+		it.Seq(schema.Properties["tags"].Items.Type).Equal("string"),
+		it.Seq(schema.Properties["email"].Type).Equal("null"),
+		it.Seq(schema.Properties["address"].Required).Equal("city"),
+	)
+
+	bytes, err := json.Marshal(schema.Properties["address"])
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(string(bytes), `{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`),
+	)
+
+	bytes, err = json.Marshal(&µ.Schema{Type: []string{"integer", "null"}})
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(string(bytes), `{"type":["integer","null"]}`),
+	)
+}
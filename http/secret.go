@@ -0,0 +1,71 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//
+// The file implements providers of secrets resolved at execution time
+//
+
+// SecretProvider resolves secrets (tokens, credentials) by name
+type SecretProvider interface {
+	Get(name string) (string, error)
+}
+
+// Secret is a promise of the secret value, resolved lazily at execution
+// time of the arrow. Suites never embed tokens, the rotation of secrets is
+// applied without recompilation.
+//
+//	env := http.EnvSecrets("APP_")
+//
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		ø.Authorization.From(http.Secret(env, "TOKEN").Map(bearer)),
+//	)
+func Secret(provider SecretProvider, name string) *Promise[string] {
+	return &Promise[string]{
+		source: func() (string, error) { return provider.Get(name) },
+	}
+}
+
+// EnvSecrets resolves secrets from environment variables with the prefix
+type EnvSecrets string
+
+// Get secret from environment variable
+func (prefix EnvSecrets) Get(name string) (string, error) {
+	val, has := os.LookupEnv(string(prefix) + name)
+	if !has {
+		return "", fmt.Errorf("secret %s%s is not defined", string(prefix), name)
+	}
+	return val, nil
+}
+
+// FileSecrets resolves secrets from files of the directory, the file name
+// is the name of secret (e.g. /run/secrets). Trailing new lines are trimmed.
+type FileSecrets string
+
+// Get secret from file
+func (dir FileSecrets) Get(name string) (string, error) {
+	if strings.ContainsAny(name, `/\`) || name == ".." {
+		return "", fmt.Errorf("invalid secret name %s", name)
+	}
+
+	val, err := os.ReadFile(filepath.Join(string(dir), name))
+	if err != nil {
+		return "", fmt.Errorf("secret %s is not defined: %w", name, err)
+	}
+
+	return strings.TrimRight(string(val), "\r\n"), nil
+}
@@ -0,0 +1,81 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestSecret(t *testing.T) {
+	bearer := func(s string) string { return "Bearer " + s }
+
+	t.Run("EnvSecrets", func(t *testing.T) {
+		t.Setenv("GURL_TOKEN", "env")
+
+		cat := µ.New().WithContext(context.Background())
+		err := cat.IO(
+			µ.GET(
+				ø.URI("https://example.com"),
+				ø.Authorization.From(µ.Secret(µ.EnvSecrets("GURL_"), "TOKEN").Map(bearer)),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cat.Request.Header.Get("Authorization"), "Bearer env"),
+		)
+	})
+
+	t.Run("FileSecrets", func(t *testing.T) {
+		dir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dir, "token"), []byte("file\n"), 0600)
+		it.Then(t).Should(it.Nil(err))
+
+		cat := µ.New().WithContext(context.Background())
+		err = cat.IO(
+			µ.GET(
+				ø.URI("https://example.com"),
+				ø.Authorization.From(µ.Secret(µ.FileSecrets(dir), "token")),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cat.Request.Header.Get("Authorization"), "file"),
+		)
+	})
+
+	t.Run("Rotation", func(t *testing.T) {
+		secret := µ.Secret(µ.EnvSecrets("GURL_"), "ROTATE")
+
+		t.Setenv("GURL_ROTATE", "a")
+		a, _ := secret.Value()
+
+		t.Setenv("GURL_ROTATE", "b")
+		b, _ := secret.Value()
+
+		it.Then(t).Should(
+			it.Equal(a, "a"),
+			it.Equal(b, "b"),
+		)
+	})
+
+	t.Run("Undefined", func(t *testing.T) {
+		_, err := µ.EnvSecrets("GURL_").Get("UNDEFINED")
+		it.Then(t).ShouldNot(it.Nil(err))
+
+		_, err = µ.FileSecrets(t.TempDir()).Get("../token")
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+}
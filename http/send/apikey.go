@@ -0,0 +1,70 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send
+
+import (
+	"fmt"
+	"net/http"
+
+	µ "github.com/fogfish/gurl/v2/http"
+)
+
+// KeyIn is the location of API key, mirrors OpenAPI security schemes
+type KeyIn string
+
+// Locations of API key
+const (
+	InHeader KeyIn = "header"
+	InQuery  KeyIn = "query"
+	InCookie KeyIn = "cookie"
+)
+
+// APIKey authenticates the request with API key passed in header, query
+// or cookie. The value is either string or resolved lazily at evaluation
+// time (http.Secret, promise or func() string). The arrow must follow
+// ø.URI for the query location.
+//
+//	env := http.EnvSecrets("APP_")
+//
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		ø.APIKey("X-API-Key", http.Secret(env, "API_KEY"), ø.InHeader),
+//	)
+func APIKey(name string, value any, in KeyIn) µ.Arrow {
+	switch in {
+	case InHeader, InQuery, InCookie:
+	default:
+		panic(fmt.Errorf("unsupported location of API key %q", in))
+	}
+
+	return func(ctx *µ.Context) error {
+		x, err := resolve(value)
+		if err != nil {
+			return err
+		}
+
+		key, ok := x.(string)
+		if !ok {
+			return fmt.Errorf("API key %s requires string, %T given", name, x)
+		}
+
+		switch in {
+		case InHeader:
+			ctx.Request.Header.Set(name, key)
+		case InQuery:
+			q := ctx.Request.URL.Query()
+			q.Set(name, key)
+			ctx.Request.URL.RawQuery = q.Encode()
+		case InCookie:
+			ctx.Request.AddCookie(&http.Cookie{Name: name, Value: key})
+		}
+
+		return nil
+	}
+}
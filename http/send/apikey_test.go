@@ -0,0 +1,52 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fogfish/gurl/v2/http"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestAPIKey(t *testing.T) {
+	t.Setenv("TEST_API_KEY", "secret")
+	env := http.EnvSecrets("TEST_")
+
+	cat := http.New().WithContext(context.Background())
+	err := cat.IO(
+		http.GET(
+			ø.URI("https://example.com/?a=1"),
+			ø.APIKey("X-API-Key", "key", ø.InHeader),
+			ø.APIKey("api_key", http.Secret(env, "API_KEY"), ø.InQuery),
+			ø.APIKey("session", func() string { return "abc" }, ø.InCookie),
+		),
+	)
+
+	cookie, _ := cat.Request.Cookie("session")
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(cat.Request.Header.Get("X-API-Key"), "key"),
+		it.Equal(cat.Request.URL.String(), "https://example.com/?a=1&api_key=secret"),
+		it.Equal(cookie.Value, "abc"),
+	)
+
+	t.Run("Unresolved", func(t *testing.T) {
+		cat := http.New().WithContext(context.Background())
+		err := cat.IO(
+			http.GET(
+				ø.URI("https://example.com/"),
+				ø.APIKey("X-API-Key", http.Secret(env, "UNDEFINED"), ø.InHeader),
+			),
+		)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+}
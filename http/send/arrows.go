@@ -12,8 +12,10 @@ package send
 import (
 	"bytes"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"io"
+	"maps"
 	"net/url"
 	"reflect"
 	"strconv"
@@ -22,6 +24,7 @@ import (
 
 	"github.com/fogfish/gurl/v2"
 	"github.com/fogfish/gurl/v2/http"
+	"google.golang.org/protobuf/proto"
 )
 
 // Method defines HTTP Method/Verb to the request
@@ -32,6 +35,57 @@ func Method(verb string) http.Arrow {
 	}
 }
 
+// Tag attaches key/value label to the context of I/O. Tags are carried by
+// logs, reports and the context of request (see http.TagsOf), so that
+// metrics are aggregated by logical operation rather than raw URL.
+//
+//	http.GET(
+//		ø.URI("https://example.com/users/%s", id),
+//		ø.Tag("operation", "get-user"),
+//	)
+func Tag(key, value string) http.Arrow {
+	return func(ctx *http.Context) error {
+		// Note: tags are copied on write, the enclosing scope restores
+		//       its own tags once the request is evaluated.
+		tags := maps.Clone(ctx.Tags)
+		if tags == nil {
+			tags = map[string]string{}
+		}
+		tags[key] = value
+		ctx.Tags = tags
+		return nil
+	}
+}
+
+// Timeout of the request, the timeout is scoped to the single request of
+// the composition, it covers reading of the response.
+//
+//	http.Join(
+//		http.GET(
+//			ø.URI("https://example.com/fast"),
+//			ø.Timeout(100*time.Millisecond),
+//		),
+//		http.GET(
+//			ø.URI("https://example.com/slow"),
+//			ø.Timeout(10*time.Second),
+//		),
+//	)
+func Timeout(d time.Duration) http.Arrow {
+	return func(ctx *http.Context) error {
+		ctx.WithDeadline(ctx.Clock().Now().Add(d))
+		return nil
+	}
+}
+
+// Deadline of the request, the deadline is scoped to the single request of
+// the composition, it covers reading of the response.
+func Deadline(t time.Time) http.Arrow {
+	return func(ctx *http.Context) error {
+		ctx.WithDeadline(t)
+		return nil
+	}
+}
+
 // Authority is part of URL, use the type to prevent escaping
 type Authority string
 
@@ -40,10 +94,57 @@ type Path string
 
 // URI defines destination URI
 // use Params arrow if you need to supply URL query params.
-func URI(url string, args ...any) http.Arrow {
-	return func(ctx *http.Context) error {
+// The arguments are resolved when arrow is evaluated, either promises,
+// pointers or functions (e.g. func() string) are used for values produced
+// by earlier arrows of the composition.
+// The ${var} are resolved from the profile of the stack, see http.WithProfile.
+// The {name} segments are bound by ø.Seg, the arrow panics if any of them
+// is not bound. The template is validated when arrow is constructed, the
+// arrow fails with gurl.InvalidURI if the template is malformed and the
+// stack is configured with http.WithStrictURI.
+func URI(uri string, args ...any) http.Arrow {
+	invalid := ValidateURI(uri, args...)
+
+	segs, args := splitSegments(args)
+	if len(segs) != 0 {
+		if err := validateSegments(uri, segs); err != nil {
+			panic(err)
+		}
+	}
+
+	return http.Annotated(func(ctx *http.Context) error {
+		if ctx.Annotate(http.Step{Kind: "send.URI", URI: uri}) {
+			return nil
+		}
+
+		if invalid != nil && ctx.StrictURI() {
+			return invalid
+		}
+
+		expand := ctx.Expand
 		if len(args) != 0 {
-			url = mkURI(url, args)
+			expand = ctx.ExpandFormat
+		}
+
+		url, err := expand(uri)
+		if err != nil {
+			return err
+		}
+
+		if len(args) != 0 {
+			val, err := mkURI(url, args)
+			if err != nil {
+				return err
+			}
+			url = val
+		}
+
+		if len(segs) != 0 {
+			val, err := bindSegments(url, segs)
+			if err != nil {
+				return err
+			}
+			url = val
 		}
 
 		if !strings.HasPrefix(url, "http") {
@@ -62,12 +163,17 @@ func URI(url string, args ...any) http.Arrow {
 		ctx.Request = req
 
 		return nil
-	}
+	})
 }
 
-func mkURI(uri string, args []any) string {
+func mkURI(uri string, args []any) (string, error) {
 	opts := []any{}
 	for _, x := range args {
+		x, err := resolve(x)
+		if err != nil {
+			return "", err
+		}
+
 		switch v := x.(type) {
 		case *url.URL:
 			v.Path = strings.TrimSuffix(v.Path, "/")
@@ -93,7 +199,7 @@ func mkURI(uri string, args []any) string {
 		}
 	}
 
-	return fmt.Sprintf(uri, opts...)
+	return fmt.Sprintf(uri, opts...), nil
 }
 
 func urlSegment(arg any) string {
@@ -134,16 +240,44 @@ func Params[T any](query T) http.Arrow {
 	}
 }
 
-// Param appends query params to request URL.
-func Param[T interface{ string | int }](key string, val T) http.Arrow {
+// ParamScalar is a type of query param value
+type ParamScalar interface {
+	string | int | int64 | uint | float64 | bool | time.Time | time.Duration
+}
+
+// ParamValue is a type of query param value, slices are repeated params.
+// Pointers and functions are resolved lazily when arrow is evaluated.
+type ParamValue interface {
+	ParamScalar |
+		[]string | []int | []int64 | []uint | []float64 | []bool | []time.Time | []time.Duration |
+		*string | *int | *float64 | *bool | *time.Time | *[]string |
+		func() string | func() int | func() float64 | func() bool | func() time.Time | func() []string
+}
+
+// Param appends query params to request URL. Slices are appended as
+// repeated params (?id=a&id=b), time.Time is formatted as RFC3339.
+// Pointers and functions are resolved when arrow is evaluated, allowing
+// values produced by earlier arrows of the composition.
+//
+//	ø.Param("id", []string{"a", "b"})
+//	ø.Param("cursor", func() string { return page.Cursor })
+func Param[T ParamValue](key string, val T) http.Arrow {
+	return param(key, reflect.ValueOf(val))
+}
+
+// ParamList appends repeated query params to request URL.
+//
+//	ø.ParamList("id", "a", "b", "c")
+func ParamList[T ParamScalar](key string, vals ...T) http.Arrow {
+	return param(key, reflect.ValueOf(vals))
+}
+
+func param(key string, val reflect.Value) http.Arrow {
 	return func(ctx *http.Context) error {
 		uri := ctx.Request.URL
 		q := uri.Query()
-		switch v := any(val).(type) {
-		case string:
-			q.Add(key, v)
-		case int:
-			q.Add(key, strconv.Itoa(v))
+		if err := encodeQueryField(q, queryField{name: key}, val); err != nil {
+			return err
 		}
 
 		uri.RawQuery = q.Encode()
@@ -166,29 +300,97 @@ func Header[T http.ReadableHeaderValues](header string, value T) http.Arrow {
 //	ø.Host.Set("example.com")
 type HeaderOf[T http.ReadableHeaderValues] string
 
-// Sets value of HTTP header
+// Sets value of HTTP header, it replaces existing values of the header.
+// The ${var} of string value are resolved from the profile of the stack.
 func (h HeaderOf[T]) Set(value T) http.Arrow {
+	literal := headerValueOf(value)
+
+	return func(cat *http.Context) error {
+		val, err := cat.Expand(literal)
+		if err != nil {
+			return err
+		}
+		cat.Request.Header.Set(string(h), val)
+		return nil
+	}
+}
+
+// Adds values to HTTP header, existing values of the header are kept.
+// Use it for multi-valued headers.
+//
+//	ø.HeaderOf[string]("Accept-Language").Add("en", "fi")
+func (h HeaderOf[T]) Add(values ...T) http.Arrow {
+	literals := make([]string, len(values))
+	for i, value := range values {
+		literals[i] = headerValueOf(value)
+	}
+
+	return func(cat *http.Context) error {
+		for _, literal := range literals {
+			val, err := cat.Expand(literal)
+			if err != nil {
+				return err
+			}
+			cat.Request.Header.Add(string(h), val)
+		}
+		return nil
+	}
+}
+
+// headerValueOf formats the value, it panics on unsupported type or value
+// so that arrows fail at composition time.
+func headerValueOf[T http.ReadableHeaderValues](value T) string {
+	val, err := formatHeader(value)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// formatHeader formats the value of HTTP header. Durations are written as
+// delta-seconds (Max-Age: 120), the format that recv parses back. Fractional
+// seconds are rejected, HTTP headers have no notation for them.
+func formatHeader[T http.ReadableHeaderValues](value T) (string, error) {
 	switch v := any(value).(type) {
 	case string:
-		return func(cat *http.Context) error {
-			cat.Request.Header.Add(string(h), v)
-			return nil
-		}
+		return v, nil
 	case int:
-		return func(cat *http.Context) error {
-			cat.Request.Header.Add(string(h), strconv.Itoa(v))
-			return nil
-		}
+		return strconv.Itoa(v), nil
 	case time.Time:
-		return func(cat *http.Context) error {
-			cat.Request.Header.Add(string(h), v.UTC().Format(time.RFC1123))
-			return nil
+		return v.UTC().Format(time.RFC1123), nil
+	case time.Duration:
+		if v%time.Second != 0 {
+			return "", fmt.Errorf("header duration %s is not whole seconds", v)
 		}
+		return strconv.Itoa(int(v / time.Second)), nil
 	default:
 		panic("invalid type")
 	}
 }
 
+// Sets value of HTTP header from the promise. It fails if promise is not
+// fulfilled at evaluation time. The value is used as-is, ${var} are not
+// resolved in runtime values.
+func (h HeaderOf[T]) From(value *http.Promise[T]) http.Arrow {
+	var zero T
+	headerValueOf(zero)
+
+	return func(cat *http.Context) error {
+		val, err := value.Value()
+		if err != nil {
+			return err
+		}
+
+		literal, err := formatHeader(val)
+		if err != nil {
+			return err
+		}
+
+		cat.Request.Header.Set(string(h), literal)
+		return nil
+	}
+}
+
 // Type of HTTP Header, Content-Type enumeration
 //
 //	const ContentType = HeaderEnumContent("Content-Type")
@@ -198,7 +400,7 @@ type HeaderEnumContent string
 // Sets value of HTTP header
 func (h HeaderEnumContent) Set(value string) http.Arrow {
 	return func(cat *http.Context) error {
-		cat.Request.Header.Add(string(h), value)
+		cat.Request.Header.Set(string(h), value)
 		return nil
 	}
 }
@@ -221,6 +423,36 @@ func (h HeaderEnumContent) Form(cat *http.Context) error {
 	return nil
 }
 
+// XML defined Header `???: application/xml`
+func (h HeaderEnumContent) XML(cat *http.Context) error {
+	cat.Request.Header.Add(string(h), "application/xml")
+	return nil
+}
+
+// TextXML defined Header `???: text/xml`
+func (h HeaderEnumContent) TextXML(cat *http.Context) error {
+	cat.Request.Header.Add(string(h), "text/xml")
+	return nil
+}
+
+// Protobuf defined Header `???: application/protobuf`
+func (h HeaderEnumContent) Protobuf(cat *http.Context) error {
+	cat.Request.Header.Add(string(h), "application/protobuf")
+	return nil
+}
+
+// MsgPack defined Header `???: application/msgpack`
+func (h HeaderEnumContent) MsgPack(cat *http.Context) error {
+	cat.Request.Header.Add(string(h), "application/msgpack")
+	return nil
+}
+
+// CBOR defined Header `???: application/cbor`
+func (h HeaderEnumContent) CBOR(cat *http.Context) error {
+	cat.Request.Header.Add(string(h), "application/cbor")
+	return nil
+}
+
 // TextPlain defined Header `???: text/plain`
 func (h HeaderEnumContent) TextPlain(cat *http.Context) error {
 	cat.Request.Header.Add(string(h), "text/plain")
@@ -254,7 +486,7 @@ type HeaderEnumConnection string
 // Sets value of HTTP header
 func (h HeaderEnumConnection) Set(value string) http.Arrow {
 	return func(cat *http.Context) error {
-		cat.Request.Header.Add(string(h), value)
+		cat.Request.Header.Set(string(h), value)
 		return nil
 	}
 }
@@ -299,6 +531,51 @@ func (h HeaderEnumTransferEncoding) Identity(cat *http.Context) error {
 	return nil
 }
 
+// Type of HTTP Header, Accept-Encoding enumeration
+//
+//	const AcceptEncodings = HeaderEnumAcceptEncoding("Accept-Encoding")
+//	ø.AcceptEncodings.Gzip
+type HeaderEnumAcceptEncoding string
+
+// Sets value of HTTP header
+func (h HeaderEnumAcceptEncoding) Set(value string) http.Arrow {
+	return func(cat *http.Context) error {
+		cat.Request.Header.Set(string(h), value)
+		return nil
+	}
+}
+
+// Gzip defines header `???: gzip`
+func (h HeaderEnumAcceptEncoding) Gzip(cat *http.Context) error {
+	cat.Request.Header.Add(string(h), "gzip")
+	return nil
+}
+
+// Deflate defines header `???: deflate`
+func (h HeaderEnumAcceptEncoding) Deflate(cat *http.Context) error {
+	cat.Request.Header.Add(string(h), "deflate")
+	return nil
+}
+
+// Brotli defines header `???: br`
+func (h HeaderEnumAcceptEncoding) Brotli(cat *http.Context) error {
+	cat.Request.Header.Add(string(h), "br")
+	return nil
+}
+
+// Identity defines header `???: identity`
+func (h HeaderEnumAcceptEncoding) Identity(cat *http.Context) error {
+	cat.Request.Header.Add(string(h), "identity")
+	return nil
+}
+
+// Any defines header `???: gzip, deflate, br`, all encodings supported
+// by the library.
+func (h HeaderEnumAcceptEncoding) Any(cat *http.Context) error {
+	cat.Request.Header.Add(string(h), "gzip, deflate, br")
+	return nil
+}
+
 // Header Content-Length
 //
 //	const ContentLength = HeaderEnumContentLength("Content-Length")
@@ -319,6 +596,7 @@ const (
 	Accept            = HeaderEnumContent("Accept")
 	AcceptCharset     = HeaderOf[string]("Accept-Charset")
 	AcceptEncoding    = HeaderOf[string]("Accept-Encoding")
+	AcceptEncodings   = HeaderEnumAcceptEncoding("Accept-Encoding")
 	AcceptLanguage    = HeaderOf[string]("Accept-Language")
 	Authorization     = HeaderOf[string]("Authorization")
 	CacheControl      = HeaderOf[string]("Cache-Control")
@@ -349,9 +627,18 @@ const (
 // if content type is not supported by the library.
 //
 // The function accept a "classical" data container such as string, []bytes or
-// io.Reader interfaces.
+// io.Reader interfaces. The promise (http.Future) is resolved at evaluation time.
 func Send(data any) http.Arrow {
 	return func(cat *http.Context) error {
+		data := data
+		if future, ok := data.(http.Future); ok {
+			val, err := future.Resolve()
+			if err != nil {
+				return err
+			}
+			data = val
+		}
+
 		chunked := cat.Request.Header.Get(string(TransferEncoding)) == "chunked"
 		content := cat.Request.Header.Get(string(ContentType))
 		if content == "" {
@@ -429,6 +716,12 @@ func Send(data any) http.Arrow {
 }
 
 func encode(content string, data interface{}) (buf *bytes.Buffer, err error) {
+	if codec, has := http.LookupCodec(content); has {
+		buf = &bytes.Buffer{}
+		err = codec.Encode(buf, data)
+		return
+	}
+
 	switch {
 	// "application/json" and other variants
 	case strings.Contains(content, "json"):
@@ -436,6 +729,12 @@ func encode(content string, data interface{}) (buf *bytes.Buffer, err error) {
 	// "application/x-www-form-urlencoded"
 	case strings.Contains(content, "www-form"):
 		buf, err = encodeForm(data)
+	// "application/xml", "text/xml" and other variants
+	case strings.Contains(content, "xml"):
+		buf, err = encodeXML(data)
+	// "application/protobuf", "application/x-protobuf"
+	case strings.Contains(content, "protobuf"):
+		buf, err = encodeProtobuf(data)
 	default:
 		err = fmt.Errorf("unsupported Content-Type %v", content)
 	}
@@ -448,6 +747,21 @@ func encodeJSON(data interface{}) (*bytes.Buffer, error) {
 	return bytes.NewBuffer(json), err
 }
 
+func encodeProtobuf(data interface{}) (*bytes.Buffer, error) {
+	msg, ok := data.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("encode application/protobuf: %T is not proto.Message", data)
+	}
+
+	bin, err := proto.Marshal(msg)
+	return bytes.NewBuffer(bin), err
+}
+
+func encodeXML(data interface{}) (*bytes.Buffer, error) {
+	xml, err := xml.Marshal(data)
+	return bytes.NewBuffer(xml), err
+}
+
 func encodeForm(data interface{}) (*bytes.Buffer, error) {
 	bin, err := json.Marshal(data)
 	if err != nil {
@@ -20,6 +20,8 @@ import (
 	"github.com/fogfish/gurl/v2/http"
 	ø "github.com/fogfish/gurl/v2/http/send"
 	"github.com/fogfish/it/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func TestSchema(t *testing.T) {
@@ -167,13 +169,22 @@ func TestHeaders(t *testing.T) {
 		{"accept", "application/json"}:                  ø.Accept.ApplicationJSON,
 		{"accept", "application/json"}:                  ø.Accept.JSON,
 		{"accept", "application/x-www-form-urlencoded"}: ø.Accept.Form,
+		{"accept", "application/xml"}:                   ø.Accept.XML,
+		{"accept", "text/xml"}:                          ø.Accept.TextXML,
 		{"accept", "text/plain"}:                        ø.Accept.Set("text/plain"),
+		{"accept-encoding", "gzip"}:                     ø.AcceptEncodings.Gzip,
+		{"accept-encoding", "deflate"}:                  ø.AcceptEncodings.Deflate,
+		{"accept-encoding", "br"}:                       ø.AcceptEncodings.Brotli,
+		{"accept-encoding", "identity"}:                 ø.AcceptEncodings.Identity,
+		{"accept-encoding", "gzip, deflate, br"}:        ø.AcceptEncodings.Any,
+		{"accept-encoding", "zstd"}:                     ø.AcceptEncoding.Set("zstd"),
 		{"connection", "keep-alive"}:                    ø.Connection.KeepAlive,
 		{"connection", "close"}:                         ø.Connection.Close,
 		{"connection", "close"}:                         ø.Connection.Set("close"),
 		{"authorization", "foo bar"}:                    ø.Authorization.Set("foo bar"),
 		{"x-value", "1024"}:                             ø.Header("x-value", 1024),
 		{"date", "Wed, 01 Feb 2023 10:20:30 UTC"}:       ø.Date.Set(time.Date(2023, 02, 01, 10, 20, 30, 0, time.UTC)),
+		{"x-timeout", "90"}:                             ø.Header("x-timeout", 90*time.Second),
 	} {
 		cat := cat.WithContext(context.Background())
 		err := cat.IO(
@@ -189,6 +200,56 @@ func TestHeaders(t *testing.T) {
 	}
 }
 
+func TestHeaderSetAdd(t *testing.T) {
+	cat := http.New().WithContext(context.Background())
+	err := cat.IO(
+		http.GET(
+			ø.URI("http://example.com"),
+			ø.Header("X-Value", "a"),
+			ø.Header("X-Value", "b"),
+			ø.HeaderOf[string]("Accept-Language").Add("en", "fi"),
+			ø.HeaderOf[int]("X-Seq").Add(1),
+			ø.HeaderOf[int]("X-Seq").Add(2),
+			ø.AcceptEncoding.Add("gzip"),
+			ø.AcceptEncodings.Brotli,
+		),
+	)
+
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Seq(cat.Request.Header.Values("X-Value")).Equal("b"),
+		it.Seq(cat.Request.Header.Values("Accept-Language")).Equal("en", "fi"),
+		it.Seq(cat.Request.Header.Values("X-Seq")).Equal("1", "2"),
+		it.Seq(cat.Request.Header.Values("Accept-Encoding")).Equal("gzip", "br"),
+	)
+}
+
+func TestHeaderDuration(t *testing.T) {
+	t.Run("Fractional", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Fail(func() {
+				ø.Header("X-Timeout", 1500*time.Millisecond)
+			}).Contain("1.5s is not whole seconds"),
+		)
+	})
+
+	t.Run("FractionalPromise", func(t *testing.T) {
+		var timeout http.Promise[time.Duration]
+		timeout.Fulfill(1500 * time.Millisecond)
+
+		cat := http.New().WithContext(context.Background())
+		err := cat.IO(
+			http.GET(
+				ø.URI("http://example.com"),
+				ø.HeaderOf[time.Duration]("X-Timeout").From(&timeout),
+			),
+		)
+		it.Then(t).Should(
+			it.String(err.Error()).Contain("1.5s is not whole seconds"),
+		)
+	})
+}
+
 func TestHeaderContentLength(t *testing.T) {
 	cat := http.New().WithContext(context.TODO())
 	err := cat.IO(
@@ -280,6 +341,38 @@ func TestParams(t *testing.T) {
 		)
 
 	})
+
+	t.Run("KeyValTypes", func(t *testing.T) {
+		cat := cat.WithContext(context.Background())
+		err := cat.IO(
+			http.GET(
+				ø.URI("https://example.com"),
+				ø.Param("a", true),
+				ø.Param("b", 1.5),
+				ø.Param("c", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+				ø.Param("d", []string{"x", "y"}),
+				ø.Param("e", []int{1, 2}),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cat.Request.URL.String(), "https://example.com?a=true&b=1.5&c=2024-01-02T03%3A04%3A05Z&d=x&d=y&e=1&e=2"),
+		)
+	})
+
+	t.Run("ParamList", func(t *testing.T) {
+		cat := cat.WithContext(context.Background())
+		err := cat.IO(
+			http.GET(
+				ø.URI("https://example.com"),
+				ø.ParamList("id", "a", "b", "c"),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cat.Request.URL.String(), "https://example.com?id=a&id=b&id=c"),
+		)
+	})
 }
 
 func TestSend(t *testing.T) {
@@ -322,6 +415,67 @@ func TestSend(t *testing.T) {
 		)
 	})
 
+	t.Run("XML", func(t *testing.T) {
+		type Site struct {
+			XMLName struct{} `xml:"site"`
+			Site    string   `xml:"name"`
+		}
+
+		for _, content := range []http.Arrow{
+			ø.ContentType.XML,
+			ø.ContentType.TextXML,
+		} {
+			cat := cat.WithContext(context.Background())
+			err := cat.IO(
+				http.GET(
+					ø.URI("https://example.com"),
+					content,
+					ø.Send(Site{Site: "host"}),
+				),
+			)
+			buf, _ := io.ReadAll(cat.Request.Body)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(string(buf), "<site><name>host</name></site>"),
+			)
+		}
+	})
+
+	t.Run("Protobuf", func(t *testing.T) {
+		for _, content := range []http.Arrow{
+			ø.ContentType.Protobuf,
+			ø.ContentType.Set("application/x-protobuf"),
+		} {
+			cat := cat.WithContext(context.Background())
+			err := cat.IO(
+				http.GET(
+					ø.URI("https://example.com"),
+					content,
+					ø.Send(wrapperspb.String("host")),
+				),
+			)
+			buf, _ := io.ReadAll(cat.Request.Body)
+			val := wrapperspb.StringValue{}
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Nil(proto.Unmarshal(buf, &val)),
+				it.Equal(val.Value, "host"),
+			)
+		}
+
+		cat := cat.WithContext(context.Background())
+		err := cat.IO(
+			http.GET(
+				ø.URI("https://example.com"),
+				ø.ContentType.Protobuf,
+				ø.Send(Site{"host", "site"}),
+			),
+		)
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+	})
+
 	t.Run("Unknown", func(t *testing.T) {
 		cat := cat.WithContext(context.Background())
 		err := cat.IO(
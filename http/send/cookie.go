@@ -0,0 +1,42 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send
+
+import (
+	"net/http"
+
+	µ "github.com/fogfish/gurl/v2/http"
+)
+
+// CookieJarFrom adds cookies of the jar, matching request URL, to the request.
+//
+//	jar, _ := cookiejar.New(nil)
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		ø.CookieJarFrom(jar),
+//	)
+func CookieJarFrom(jar http.CookieJar) µ.Arrow {
+	return func(ctx *µ.Context) error {
+		for _, cookie := range jar.Cookies(ctx.Request.URL) {
+			ctx.Request.AddCookie(cookie)
+		}
+		return nil
+	}
+}
+
+// Cookies adds cookies to the request, e.g. lifted by ƒ.Cookie from
+// earlier responses.
+func Cookies(cookies ...*http.Cookie) µ.Arrow {
+	return func(ctx *µ.Context) error {
+		for _, cookie := range cookies {
+			ctx.Request.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		}
+		return nil
+	}
+}
@@ -0,0 +1,55 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestCookies(t *testing.T) {
+	t.Run("CookieJarFrom", func(t *testing.T) {
+		jar, _ := cookiejar.New(nil)
+		uri, _ := url.Parse("https://example.com")
+		jar.SetCookies(uri, []*http.Cookie{{Name: "session", Value: "x"}})
+
+		cat := µ.New().WithContext(context.Background())
+		err := cat.IO(
+			µ.GET(
+				ø.URI("https://example.com"),
+				ø.CookieJarFrom(jar),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cat.Request.Header.Get("Cookie"), "session=x"),
+		)
+	})
+
+	t.Run("Cookies", func(t *testing.T) {
+		cat := µ.New().WithContext(context.Background())
+		err := cat.IO(
+			µ.GET(
+				ø.URI("https://example.com"),
+				ø.Cookies(&http.Cookie{Name: "a", Value: "1", Secure: true}, &http.Cookie{Name: "b", Value: "2"}),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cat.Request.Header.Get("Cookie"), "a=1; b=2"),
+		)
+	})
+}
@@ -0,0 +1,78 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/fogfish/gurl/v2/http"
+)
+
+//
+// The file implements integrity digest of egress payload
+//
+
+// ContentDigest computes digest of outgoing payload and attaches it to the
+// request as RFC 9530 Content-Digest header ("sha-256", "sha-512") or
+// as legacy Content-MD5 header ("md5"). The arrow must follow the one,
+// which defines the payload.
+//
+//	http.PUT(
+//		ø.URI("https://example.com"),
+//		ø.ContentType.JSON,
+//		ø.Send(data),
+//		ø.ContentDigest("sha-256"),
+//	)
+func ContentDigest(algorithm string) http.Arrow {
+	return func(cat *http.Context) error {
+		var h hash.Hash
+		switch algorithm {
+		case "sha-256":
+			h = sha256.New()
+		case "sha-512":
+			h = sha512.New()
+		case "md5":
+			h = md5.New()
+		default:
+			return fmt.Errorf("unsupported digest algorithm %v", algorithm)
+		}
+
+		if cat.Request.Body != nil {
+			pkt, err := io.ReadAll(cat.Request.Body)
+			cat.Request.Body.Close()
+			if err != nil {
+				return err
+			}
+
+			cat.Request.Body = io.NopCloser(bytes.NewBuffer(pkt))
+			cat.Request.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewBuffer(pkt)), nil
+			}
+			h.Write(pkt)
+		}
+
+		digest := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+		switch algorithm {
+		case "md5":
+			cat.Request.Header.Set("Content-MD5", digest)
+		default:
+			cat.Request.Header.Add("Content-Digest", algorithm+"=:"+digest+":")
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/fogfish/gurl/v2/http"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestContentDigest(t *testing.T) {
+	for algorithm, expect := range map[string]string{
+		"sha-256": "sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:",
+		"sha-512": "sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:",
+	} {
+		t.Run(algorithm, func(t *testing.T) {
+			cat := http.New().WithContext(context.Background())
+			err := cat.IO(
+				http.POST(
+					ø.URI("https://example.com"),
+					ø.ContentType.JSON,
+					ø.Send(`{"hello": "world"}`),
+					ø.ContentDigest(algorithm),
+				),
+			)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(cat.Request.Header.Get("Content-Digest"), expect),
+			)
+
+			pkt, err := io.ReadAll(cat.Request.Body)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(string(pkt), `{"hello": "world"}`),
+			)
+		})
+	}
+
+	t.Run("md5", func(t *testing.T) {
+		cat := http.New().WithContext(context.Background())
+		err := cat.IO(
+			http.POST(
+				ø.URI("https://example.com"),
+				ø.ContentType.Text,
+				ø.Send("hello"),
+				ø.ContentDigest("md5"),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cat.Request.Header.Get("Content-MD5"), "XUFAKrxLKna5cZ2REBfFkg=="),
+		)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		cat := http.New().WithContext(context.Background())
+		err := cat.IO(
+			http.POST(
+				ø.URI("https://example.com"),
+				ø.Send("hello"),
+				ø.ContentDigest("crc32"),
+			),
+		)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+}
@@ -0,0 +1,111 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/fogfish/gurl/v2/http"
+)
+
+//
+// The file implements compression of egress payload
+//
+
+// SendCompressed transmits the payload compressed with given encoding
+// ("gzip", "deflate" or "br"). The payload is encoded using Content-Type
+// as a hint, same as Send does. The arrow sets Content-Encoding header, the
+// compressed payload is streamed, its length is unknown (chunked transfer).
+//
+//	http.POST(
+//		ø.URI("https://example.com"),
+//		ø.ContentType.JSON,
+//		ø.SendCompressed(data, "gzip"),
+//	)
+func SendCompressed(data any, encoding string) http.Arrow {
+	return func(cat *http.Context) error {
+		if _, err := encoder(io.Discard, encoding); err != nil {
+			return err
+		}
+
+		length := cat.Request.ContentLength
+		cat.Request.ContentLength = 0
+
+		if err := Send(data)(cat); err != nil {
+			return err
+		}
+
+		source := cat.Request.GetBody
+		switch v := data.(type) {
+		case string:
+			source = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(v)), nil }
+		case []byte:
+			source = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(v)), nil }
+		}
+
+		cat.Request.Body = compress(cat.Request.Body, encoding)
+		cat.Request.GetBody = nil
+		if source != nil {
+			cat.Request.GetBody = func() (io.ReadCloser, error) {
+				body, err := source()
+				if err != nil {
+					return nil, err
+				}
+				return compress(body, encoding), nil
+			}
+		}
+		cat.Request.Header.Set(string(ContentEncoding), encoding)
+
+		switch {
+		case cat.Request.Header.Get(string(TransferEncoding)) == "chunked":
+			cat.Request.ContentLength = length
+		default:
+			cat.Request.ContentLength = -1
+		}
+
+		return nil
+	}
+}
+
+func encoder(w io.Writer, encoding string) (io.WriteCloser, error) {
+	switch encoding {
+	case "gzip":
+		return gzip.NewWriter(w), nil
+	case "deflate":
+		// Note: "deflate" is zlib data format (RFC 9110, Section 8.4.1.2)
+		return zlib.NewWriter(w), nil
+	case "br":
+		return brotli.NewWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding %v", encoding)
+	}
+}
+
+func compress(body io.ReadCloser, encoding string) io.ReadCloser {
+	return newPipe(func(pw io.Writer) error {
+		defer body.Close()
+
+		w, err := encoder(pw, encoding)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(w, body); err != nil {
+			return err
+		}
+
+		return w.Close()
+	})
+}
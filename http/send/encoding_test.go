@@ -0,0 +1,88 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/fogfish/gurl/v2/http"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestSendCompressed(t *testing.T) {
+	type Pair struct {
+		Key string `json:"key"`
+		Val int    `json:"val"`
+	}
+
+	decoder := map[string]func(io.Reader) (io.Reader, error){
+		"gzip":    func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"deflate": func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+		"br":      func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+	}
+
+	for encoding, decode := range decoder {
+		t.Run(encoding, func(t *testing.T) {
+			cat := http.New().WithContext(context.Background())
+			err := cat.IO(
+				http.POST(
+					ø.URI("https://example.com"),
+					ø.ContentType.JSON,
+					ø.SendCompressed(Pair{"a", 1}, encoding),
+				),
+			)
+			it.Then(t).Should(it.Nil(err))
+
+			pkt, err := io.ReadAll(cat.Request.Body)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(cat.Request.Header.Get("Content-Encoding"), encoding),
+				it.Equal(cat.Request.ContentLength, -1),
+			)
+
+			r, err := decode(bytes.NewReader(pkt))
+			it.Then(t).Should(it.Nil(err))
+
+			raw, err := io.ReadAll(r)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(string(raw), `{"key":"a","val":1}`),
+			)
+
+			// Note: GetBody re-reads compressed payload for retries
+			body, err := cat.Request.GetBody()
+			it.Then(t).Should(it.Nil(err))
+
+			again, err := io.ReadAll(body)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(string(again), string(pkt)),
+			)
+		})
+	}
+
+	t.Run("Unsupported", func(t *testing.T) {
+		cat := http.New().WithContext(context.Background())
+		err := cat.IO(
+			http.POST(
+				ø.URI("https://example.com"),
+				ø.ContentType.JSON,
+				ø.SendCompressed(Pair{"a", 1}, "zstd"),
+			),
+		)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+}
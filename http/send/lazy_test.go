@@ -0,0 +1,78 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fogfish/gurl/v2/http"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestLazy(t *testing.T) {
+	type User struct{ ID string }
+
+	cat := http.New()
+
+	t.Run("URI", func(t *testing.T) {
+		user := &User{ID: "a"}
+		req := http.GET(
+			ø.URI("https://example.com/users/%s/{repo}",
+				func() string { return user.ID },
+				ø.Seg("repo", func() string { return user.ID + "-repo" }),
+			),
+		)
+
+		// Note: the owning struct is re-allocated after arrow is constructed
+		user = &User{ID: "b"}
+
+		cat := cat.WithContext(context.Background())
+		err := cat.IO(req)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cat.Request.URL.String(), "https://example.com/users/b/b-repo"),
+		)
+	})
+
+	t.Run("Param", func(t *testing.T) {
+		var id int
+		cursor := ""
+		req := http.GET(
+			ø.URI("https://example.com"),
+			ø.Param("id", &id),
+			ø.Param("cursor", func() string { return cursor }),
+		)
+
+		id, cursor = 10, "xyz"
+
+		cat := cat.WithContext(context.Background())
+		err := cat.IO(req)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cat.Request.URL.String(), "https://example.com?cursor=xyz&id=10"),
+		)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		fail := errors.New("not ready")
+
+		cat := cat.WithContext(context.Background())
+		err := cat.IO(
+			http.GET(
+				ø.URI("https://example.com/%s", func() (string, error) { return "", fail }),
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, fail)),
+		)
+	})
+}
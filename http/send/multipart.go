@@ -0,0 +1,168 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"github.com/fogfish/gurl/v2/http"
+)
+
+// Part of multipart/form-data payload
+type Part struct {
+	Name        string
+	FileName    string
+	ContentType string
+	Body        io.Reader
+	open        func() io.Reader
+}
+
+// Field part of multipart/form-data payload
+func Field(name, value string) Part {
+	return Part{
+		Name: name,
+		Body: strings.NewReader(value),
+		open: func() io.Reader { return strings.NewReader(value) },
+	}
+}
+
+// File part of multipart/form-data payload. The content is streamed from
+// the reader, it is not buffered in memory. The reader is consumed once,
+// unless it implements io.Seeker (e.g. os.File), such readers are rewound
+// so that the payload is re-readable (e.g. within http.Retry).
+func File(name, filename string, r io.Reader) Part {
+	return Part{Name: name, FileName: filename, Body: r}
+}
+
+// FileOf is the re-readable file part of multipart/form-data payload.
+// The function opens the content each time the payload is sent.
+func FileOf(name, filename string, open func() io.Reader) Part {
+	return Part{Name: name, FileName: filename, open: open}
+}
+
+// Define content type of the part
+func (p Part) Type(contentType string) Part {
+	p.ContentType = contentType
+	return p
+}
+
+func (p Part) header() textproto.MIMEHeader {
+	h := make(textproto.MIMEHeader)
+
+	disposition := fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(p.Name))
+	if p.FileName != "" {
+		disposition += fmt.Sprintf(`; filename="%s"`, escapeQuotes(p.FileName))
+	}
+	h.Set("Content-Disposition", disposition)
+
+	switch {
+	case p.ContentType != "":
+		h.Set("Content-Type", p.ContentType)
+	case p.FileName != "":
+		h.Set("Content-Type", "application/octet-stream")
+	}
+
+	return h
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string { return quoteEscaper.Replace(s) }
+
+func (p Part) reader() (io.Reader, error) {
+	switch {
+	case p.open != nil:
+		return p.open(), nil
+	case p.Body == nil:
+		return nil, nil
+	}
+
+	if seeker, ok := p.Body.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return p.Body, nil
+}
+
+func (p Part) rereadable() bool {
+	if p.open != nil || p.Body == nil {
+		return true
+	}
+	_, ok := p.Body.(io.Seeker)
+	return ok
+}
+
+// SendMultipart encodes parts as multipart/form-data payload. The payload is
+// streamed to destination using chunked transfer encoding, the encoder starts
+// when the request is sent. The payload is re-readable (GetBody) if all parts
+// are re-readable.
+//
+//	http.POST(
+//		ø.URI("https://example.com/upload"),
+//		ø.SendMultipart(
+//			ø.Field("title", "example"),
+//			ø.File("file", "example.json", fd).Type("application/json"),
+//		),
+//	)
+func SendMultipart(parts ...Part) http.Arrow {
+	return func(cat *http.Context) error {
+		boundary := multipart.NewWriter(io.Discard).Boundary()
+
+		cat.Request.Header.Set(string(ContentType), "multipart/form-data; boundary="+boundary)
+		cat.Request.Body = encodeMultipart(boundary, parts)
+		cat.Request.ContentLength = -1
+		cat.Request.GetBody = nil
+
+		rereadable := true
+		for _, part := range parts {
+			rereadable = rereadable && part.rereadable()
+		}
+		if rereadable {
+			cat.Request.GetBody = func() (io.ReadCloser, error) {
+				return encodeMultipart(boundary, parts), nil
+			}
+		}
+
+		return nil
+	}
+}
+
+func encodeMultipart(boundary string, parts []Part) io.ReadCloser {
+	return newPipe(func(w io.Writer) error {
+		mw := multipart.NewWriter(w)
+		if err := mw.SetBoundary(boundary); err != nil {
+			return err
+		}
+
+		for _, part := range parts {
+			pw, err := mw.CreatePart(part.header())
+			if err != nil {
+				return err
+			}
+
+			r, err := part.reader()
+			if err != nil {
+				return err
+			}
+
+			if r != nil {
+				if _, err := io.Copy(pw, r); err != nil {
+					return err
+				}
+			}
+		}
+
+		return mw.Close()
+	})
+}
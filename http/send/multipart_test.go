@@ -0,0 +1,114 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send_test
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestSendMultipart(t *testing.T) {
+	cat := µ.New().WithContext(context.Background())
+	err := cat.IO(
+		µ.POST(
+			ø.URI("https://example.com"),
+			ø.SendMultipart(
+				ø.Field("title", "example"),
+				ø.File("file", "example.json", strings.NewReader(`{"a":1}`)).Type("application/json"),
+				ø.File("blob", "example.bin", strings.NewReader("blob")),
+			),
+		),
+	)
+	it.Then(t).Should(it.Nil(err))
+
+	media, params, err := mime.ParseMediaType(cat.Request.Header.Get("Content-Type"))
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(media, "multipart/form-data"),
+	)
+
+	type part struct{ name, file, mime, body string }
+	seq := []part{}
+	mr := multipart.NewReader(cat.Request.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		it.Then(t).Should(it.Nil(err))
+
+		b, _ := io.ReadAll(p)
+		seq = append(seq, part{p.FormName(), p.FileName(), p.Header.Get("Content-Type"), string(b)})
+	}
+
+	it.Then(t).Should(
+		it.Seq(seq).Equal(
+			part{"title", "", "", "example"},
+			part{"file", "example.json", "application/json", `{"a":1}`},
+			part{"blob", "example.bin", "application/octet-stream", "blob"},
+		),
+	)
+}
+
+func TestSendMultipartRetry(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mr, err := r.MultipartReader()
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			seq := []string{}
+			for {
+				p, err := mr.NextPart()
+				if err != nil {
+					break
+				}
+				b, _ := io.ReadAll(p)
+				seq = append(seq, string(b))
+			}
+			bodies = append(bodies, strings.Join(seq, ":"))
+			if len(bodies) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}),
+	)
+	defer ts.Close()
+
+	err := µ.New().IO(context.Background(),
+		µ.Retry(2, time.Millisecond,
+			µ.POST(
+				ø.URI(ts.URL),
+				ø.SendMultipart(
+					ø.Field("title", "example"),
+					ø.FileOf("file", "example.txt", func() io.Reader { return strings.NewReader("text") }),
+				),
+				ƒ.Status.OK,
+			),
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Seq(bodies).Equal("example:text", "example:text"),
+	)
+}
@@ -0,0 +1,79 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/fogfish/gurl/v2/http"
+)
+
+// Negotiation is the quality-weighted list of values for content
+// negotiation headers (e.g. Accept). Use the method Set as the arrow.
+//
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		ø.Accept.With("application/json", 1.0).With("text/xml", 0.5).Set,
+//	)
+type Negotiation struct {
+	header string
+	seq    []qvalue
+}
+
+type qvalue struct {
+	value string
+	q     float64
+}
+
+// With appends the value weighted by quality (0 .. 1) to negotiation
+func (h HeaderEnumContent) With(value string, q float64) Negotiation {
+	return Negotiation{header: string(h)}.With(value, q)
+}
+
+// With appends the value weighted by quality (0 .. 1) to negotiation
+func (h HeaderEnumAcceptEncoding) With(value string, q float64) Negotiation {
+	return Negotiation{header: string(h)}.With(value, q)
+}
+
+// With appends the value weighted by quality (0 .. 1) to negotiation
+func (n Negotiation) With(value string, q float64) Negotiation {
+	seq := make([]qvalue, len(n.seq), len(n.seq)+1)
+	copy(seq, n.seq)
+
+	return Negotiation{
+		header: n.header,
+		seq:    append(seq, qvalue{value: value, q: min(max(q, 0), 1)}),
+	}
+}
+
+// String formats the header value, quality 1 is implicit
+//
+//	application/json, text/xml;q=0.5
+func (n Negotiation) String() string {
+	seq := make([]string, len(n.seq))
+	for i, v := range n.seq {
+		if v.q == 1 {
+			seq[i] = v.value
+			continue
+		}
+
+		// Note: RFC 9110 allows at most three digits of quality
+		q := strconv.FormatFloat(math.Round(v.q*1000)/1000, 'f', -1, 64)
+		seq[i] = v.value + ";q=" + q
+	}
+	return strings.Join(seq, ", ")
+}
+
+// Set the negotiation header to the request
+func (n Negotiation) Set(cat *http.Context) error {
+	cat.Request.Header.Set(n.header, n.String())
+	return nil
+}
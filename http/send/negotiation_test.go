@@ -0,0 +1,39 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fogfish/gurl/v2/http"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestNegotiation(t *testing.T) {
+	json := ø.Accept.With("application/json", 1.0)
+	both := json.With("text/xml", 0.5).With("*/*", 0.12345)
+
+	cat := http.New().WithContext(context.Background())
+	err := cat.IO(
+		http.GET(
+			ø.URI("https://example.com"),
+			both.Set,
+			ø.AcceptEncodings.With("br", 1).With("gzip", 0.8).Set,
+		),
+	)
+
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(json.String(), "application/json"),
+		it.Equal(cat.Request.Header.Get("Accept"), "application/json, text/xml;q=0.5, */*;q=0.123"),
+		it.Equal(cat.Request.Header.Get("Accept-Encoding"), "br, gzip;q=0.8"),
+	)
+}
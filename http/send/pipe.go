@@ -0,0 +1,47 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send
+
+import (
+	"io"
+	"sync"
+)
+
+//
+// The file implements streaming of egress payload
+//
+
+// pipe is the reader of io.Pipe, the writer is spawned on the first read.
+// No goroutine is running if the request is never sent, closing the reader
+// aborts the writer.
+type pipe struct {
+	once  sync.Once
+	write func(io.Writer) error
+	r     *io.PipeReader
+	w     *io.PipeWriter
+}
+
+func newPipe(write func(io.Writer) error) *pipe {
+	r, w := io.Pipe()
+	return &pipe{write: write, r: r, w: w}
+}
+
+func (p *pipe) Read(b []byte) (int, error) {
+	p.once.Do(func() {
+		go func() {
+			p.w.CloseWithError(p.write(p.w))
+		}()
+	})
+	return p.r.Read(b)
+}
+
+func (p *pipe) Close() error {
+	p.once.Do(func() {})
+	return p.r.CloseWithError(io.ErrClosedPipe)
+}
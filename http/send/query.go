@@ -0,0 +1,235 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fogfish/gurl/v2/http"
+)
+
+// Query appends query params to request URL. The arrow takes a struct and
+// serializes its fields using `query` (or `url`) struct tags. The tag
+// declares the name of param and options:
+//   - omitempty skips zero values;
+//   - comma joins slices into single param, slices are repeated otherwise;
+//   - unix encodes time.Time as seconds since epoch.
+//
+// The `layout` tag defines format of time.Time (default RFC3339). Nested
+// structs are flattened as parent[child].
+//
+//	type Search struct {
+//		Text  string    `query:"q"`
+//		Tags  []string  `query:"tag,omitempty"`
+//		IDs   []int     `query:"ids,comma"`
+//		Since time.Time `query:"since" layout:"2006-01-02"`
+//	}
+//
+//	http.GET(
+//		ø.URI("https://example.com/search"),
+//		ø.Query(Search{Text: "gurl", Tags: []string{"a", "b"}}),
+//	)
+func Query(query any) http.Arrow {
+	return func(ctx *http.Context) error {
+		uri := ctx.Request.URL
+
+		q := uri.Query()
+		if err := encodeQuery(q, "", reflect.ValueOf(query)); err != nil {
+			return err
+		}
+
+		uri.RawQuery = q.Encode()
+		ctx.Request.URL = uri
+
+		return nil
+	}
+}
+
+var (
+	typeTime          = reflect.TypeOf(time.Time{})
+	typeTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type queryField struct {
+	name      string
+	omitempty bool
+	comma     bool
+	unix      bool
+	layout    string
+}
+
+func encodeQuery(q url.Values, prefix string, val reflect.Value) error {
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("query: %s is not a struct", val.Type())
+	}
+
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		field, skip := queryFieldOf(f)
+		if skip {
+			continue
+		}
+		if prefix != "" {
+			field.name = prefix + "[" + field.name + "]"
+		}
+
+		if err := encodeQueryField(q, field, val.Field(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func queryFieldOf(f reflect.StructField) (queryField, bool) {
+	tag, has := f.Tag.Lookup("query")
+	if !has {
+		tag = f.Tag.Get("url")
+	}
+	if tag == "-" {
+		return queryField{}, true
+	}
+
+	seq := strings.Split(tag, ",")
+	field := queryField{name: seq[0], layout: f.Tag.Get("layout")}
+	if field.name == "" {
+		field.name = f.Name
+	}
+
+	for _, opt := range seq[1:] {
+		switch opt {
+		case "omitempty":
+			field.omitempty = true
+		case "comma":
+			field.comma = true
+		case "unix":
+			field.unix = true
+		}
+	}
+
+	return field, false
+}
+
+func encodeQueryField(q url.Values, field queryField, val reflect.Value) error {
+	if val.Kind() == reflect.Func && !val.IsNil() {
+		x, err := resolve(val.Interface())
+		if err != nil {
+			return err
+		}
+		val = reflect.ValueOf(x)
+	}
+
+	if !val.IsValid() || (field.omitempty && val.IsZero()) {
+		return nil
+	}
+
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+
+	if (val.Kind() == reflect.Slice || val.Kind() == reflect.Array) && val.Type().Elem().Kind() != reflect.Uint8 {
+		seq := make([]string, 0, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			s, err := queryValueOf(field, val.Index(i))
+			if err != nil {
+				return err
+			}
+			seq = append(seq, s)
+		}
+
+		if field.comma {
+			q.Add(field.name, strings.Join(seq, ","))
+			return nil
+		}
+
+		for _, s := range seq {
+			q.Add(field.name, s)
+		}
+		return nil
+	}
+
+	if val.Kind() == reflect.Struct && val.Type() != typeTime && !val.Type().Implements(typeTextMarshaler) {
+		return encodeQuery(q, field.name, val)
+	}
+
+	s, err := queryValueOf(field, val)
+	if err != nil {
+		return err
+	}
+	q.Add(field.name, s)
+
+	return nil
+}
+
+func queryValueOf(field queryField, val reflect.Value) (string, error) {
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return "", nil
+		}
+		val = val.Elem()
+	}
+
+	if val.Type() == typeTime {
+		t := val.Interface().(time.Time)
+		switch {
+		case field.unix:
+			return strconv.FormatInt(t.Unix(), 10), nil
+		case field.layout != "":
+			return t.Format(field.layout), nil
+		default:
+			return t.Format(time.RFC3339), nil
+		}
+	}
+
+	switch v := val.Interface().(type) {
+	case encoding.TextMarshaler:
+		b, err := v.MarshalText()
+		return string(b), err
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+
+	switch val.Kind() {
+	case reflect.String:
+		return val.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(val.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'f', -1, val.Type().Bits()), nil
+	case reflect.Slice:
+		return string(val.Bytes()), nil
+	default:
+		return "", fmt.Errorf("query: unsupported type %s of %s", val.Type(), field.name)
+	}
+}
@@ -0,0 +1,113 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/fogfish/gurl/v2/http"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestQuery(t *testing.T) {
+	cat := http.New()
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	type Page struct {
+		Size   int    `query:"size"`
+		Cursor string `query:"cursor,omitempty"`
+	}
+
+	type Search struct {
+		Text    string        `query:"q"`
+		Tags    []string      `query:"tag,omitempty"`
+		IDs     []int         `url:"ids,comma"`
+		Since   time.Time     `query:"since" layout:"2006-01-02"`
+		Until   time.Time     `query:"until,unix"`
+		At      time.Time     `query:"at"`
+		Timeout time.Duration `query:"timeout"`
+		Score   *float64      `query:"score,omitempty"`
+		Exact   bool          `query:"exact"`
+		Page    Page          `query:"page"`
+		Secret  string        `query:"-"`
+		Limit   uint8
+	}
+
+	t.Run("Struct", func(t *testing.T) {
+		cat := cat.WithContext(context.Background())
+		err := cat.IO(
+			http.GET(
+				ø.URI("https://example.com?x=1"),
+				ø.Query(&Search{
+					Text:    "gurl",
+					Tags:    []string{"a", "b"},
+					IDs:     []int{1, 2, 3},
+					Since:   since,
+					Until:   since,
+					At:      since,
+					Timeout: time.Second,
+					Exact:   true,
+					Page:    Page{Size: 10},
+					Secret:  "secret",
+					Limit:   5,
+				}),
+			),
+		)
+
+		q, _ := url.ParseQuery(cat.Request.URL.RawQuery)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(q.Get("x"), "1"),
+			it.Equal(q.Get("q"), "gurl"),
+			it.Seq(q["tag"]).Equal("a", "b"),
+			it.Equal(q.Get("ids"), "1,2,3"),
+			it.Equal(q.Get("since"), "2024-01-02"),
+			it.Equal(q.Get("until"), "1704164645"),
+			it.Equal(q.Get("at"), "2024-01-02T03:04:05Z"),
+			it.Equal(q.Get("timeout"), "1s"),
+			it.Equal(q.Get("exact"), "true"),
+			it.Equal(q.Get("page[size]"), "10"),
+			it.Equal(q.Get("Limit"), "5"),
+			it.True(!q.Has("score")),
+			it.True(!q.Has("page[cursor]")),
+			it.True(!q.Has("Secret")),
+		)
+	})
+
+	t.Run("OmitEmpty", func(t *testing.T) {
+		cat := cat.WithContext(context.Background())
+		err := cat.IO(
+			http.GET(
+				ø.URI("https://example.com"),
+				ø.Query(Page{Size: 0}),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cat.Request.URL.String(), "https://example.com?size=0"),
+		)
+	})
+
+	t.Run("NotStruct", func(t *testing.T) {
+		cat := cat.WithContext(context.Background())
+		err := cat.IO(
+			http.GET(
+				ø.URI("https://example.com"),
+				ø.Query("q=1"),
+			),
+		)
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+	})
+}
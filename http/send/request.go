@@ -0,0 +1,52 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send
+
+import (
+	"context"
+	"net/http"
+
+	µ "github.com/fogfish/gurl/v2/http"
+)
+
+// FromRequest adopts externally built request into the context, it replaces
+// ø.URI. The request is cloned, sub-sequent arrows do not modify the original
+// one. The payload is re-read using GetBody if it is defined, so that arrow
+// is evaluable multiple times.
+//
+//	http.Join(
+//		ø.FromRequest(req),
+//		ø.Header("X-Trace", "abc"),
+//		ƒ.Status.OK,
+//	)
+func FromRequest(req *http.Request) µ.Arrow {
+	return func(ctx *µ.Context) error {
+		c := ctx.Context
+		if c == nil {
+			c = context.Background()
+		}
+
+		eg := req.Clone(c)
+		if eg.Header == nil {
+			eg.Header = http.Header{}
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return err
+			}
+			eg.Body = body
+		}
+
+		ctx.Method = eg.Method
+		ctx.Request = eg
+
+		return nil
+	}
+}
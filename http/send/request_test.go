@@ -0,0 +1,78 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestFromRequest(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(r.Method + " " + r.Header.Get("X-Trace") + " " + string(body)))
+		}),
+	)
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodPut, ts.URL, strings.NewReader("hello"))
+	it.Then(t).Should(it.Nil(err))
+
+	stack := µ.New()
+	for i := 0; i < 2; i++ {
+		var out bytes.Buffer
+		err := stack.IO(context.Background(),
+			µ.Join(
+				ø.FromRequest(req),
+				ø.Header("X-Trace", "abc"),
+				ƒ.Status.OK,
+				ƒ.ContentType.Text,
+				ƒ.Bytes(&out),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(out.String(), "PUT abc hello"),
+		)
+	}
+
+	it.Then(t).Should(
+		it.Equal(req.Header.Get("X-Trace"), ""),
+	)
+}
+
+func TestFromRequestNilHeader(t *testing.T) {
+	uri, err := url.Parse("https://example.com")
+	it.Then(t).Should(it.Nil(err))
+
+	cat := µ.New().WithContext(context.Background())
+	err = cat.IO(
+		µ.Join(
+			ø.FromRequest(&http.Request{Method: http.MethodGet, URL: uri}),
+			ø.Header("X-Trace", "abc"),
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(cat.Request.Header.Get("X-Trace"), "abc"),
+	)
+}
@@ -0,0 +1,168 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+)
+
+// Segment is a value of named segment of URI template, see ø.Seg
+type Segment struct {
+	Name  string
+	Value any
+}
+
+// Seg binds the value to named segment {name} of URI template. The value
+// is escaped using path rules of RFC 3986, use ø.Path type to disable
+// escaping. Promises are resolved when arrow is evaluated.
+//
+//	ø.URI("https://example.com/users/{user}/repos/{repo}",
+//		ø.Seg("user", "joe"),
+//		ø.Seg("repo", &repo),
+//	)
+func Seg(name string, value any) Segment {
+	return Segment{Name: name, Value: value}
+}
+
+var reSegment = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_.\-]*)\}`)
+
+// splitSegments separates named segments from positional arguments
+func splitSegments(args []any) ([]Segment, []any) {
+	var segs []Segment
+	var rest []any
+
+	for _, arg := range args {
+		if seg, ok := arg.(Segment); ok {
+			segs = append(segs, seg)
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+
+	return segs, rest
+}
+
+// placeholdersOf returns names of segments declared by the template,
+// profile variables ${var} are skipped.
+func placeholdersOf(uri string) []string {
+	var names []string
+	for _, at := range reSegment.FindAllStringSubmatchIndex(uri, -1) {
+		if at[0] > 0 && uri[at[0]-1] == '$' {
+			continue
+		}
+		names = append(names, uri[at[2]:at[3]])
+	}
+	return names
+}
+
+// validateSegments checks that all segments of template are bound
+func validateSegments(uri string, segs []Segment) error {
+	bound := map[string]bool{}
+	for _, seg := range segs {
+		if bound[seg.Name] {
+			return &gurl.InvalidURI{URI: uri, Reason: fmt.Sprintf("segment {%s} is bound twice", seg.Name)}
+		}
+		bound[seg.Name] = true
+	}
+
+	declared := map[string]bool{}
+	for _, name := range placeholdersOf(uri) {
+		if !bound[name] {
+			return &gurl.InvalidURI{URI: uri, Reason: fmt.Sprintf("segment {%s} is not bound", name)}
+		}
+		declared[name] = true
+	}
+
+	for _, seg := range segs {
+		if !declared[seg.Name] {
+			return &gurl.InvalidURI{URI: uri, Reason: fmt.Sprintf("segment {%s} is not declared", seg.Name)}
+		}
+	}
+
+	return nil
+}
+
+// bindSegments substitutes named segments of the template with values
+func bindSegments(uri string, segs []Segment) (string, error) {
+	for _, seg := range segs {
+		val, err := segmentValue(seg.Value)
+		if err != nil {
+			return "", err
+		}
+		uri = strings.ReplaceAll(uri, "{"+seg.Name+"}", val)
+	}
+
+	return uri, nil
+}
+
+var typeError = reflect.TypeOf((*error)(nil)).Elem()
+
+// resolve the lazy value at evaluation time: promise or function
+// func() T, func() (T, error).
+func resolve(x any) (any, error) {
+	if future, ok := x.(http.Future); ok {
+		return future.Resolve()
+	}
+
+	f := reflect.ValueOf(x)
+	if f.Kind() != reflect.Func || f.IsNil() || f.Type().NumIn() != 0 {
+		return x, nil
+	}
+
+	switch t := f.Type(); {
+	case t.NumOut() == 1:
+		return f.Call(nil)[0].Interface(), nil
+	case t.NumOut() == 2 && t.Out(1) == typeError:
+		out := f.Call(nil)
+		if err, _ := out[1].Interface().(error); err != nil {
+			return nil, err
+		}
+		return out[0].Interface(), nil
+	default:
+		return x, nil
+	}
+}
+
+func segmentValue(x any) (string, error) {
+	x, err := resolve(x)
+	if err != nil {
+		return "", err
+	}
+
+	switch v := x.(type) {
+	case *url.URL:
+		return strings.TrimSuffix(v.String(), "/"), nil
+	case *Path:
+		return string(*v), nil
+	case Path:
+		return string(v), nil
+	case *Authority:
+		return string(*v), nil
+	case Authority:
+		return string(v), nil
+	case string:
+		return url.PathEscape(v), nil
+	case *string:
+		return url.PathEscape(*v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case *int:
+		return strconv.Itoa(*v), nil
+	default:
+		return url.PathEscape(urlSegment(x)), nil
+	}
+}
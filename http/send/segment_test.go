@@ -0,0 +1,96 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestSegments(t *testing.T) {
+	cat := http.New()
+
+	t.Run("Bind", func(t *testing.T) {
+		var repo http.Promise[string]
+		repo.Fulfill("gurl")
+
+		cat := cat.WithContext(context.Background())
+		err := cat.IO(
+			http.GET(
+				ø.URI("https://example.com/users/{user}/repos/{repo}/{n}",
+					ø.Seg("user", "joe doe/x"),
+					ø.Seg("repo", &repo),
+					ø.Seg("n", 10),
+				),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cat.Request.URL.String(), "https://example.com/users/joe%20doe%2Fx/repos/gurl/10"),
+		)
+	})
+
+	t.Run("Mixed", func(t *testing.T) {
+		cat := cat.WithContext(context.Background())
+		err := cat.IO(
+			http.GET(
+				ø.URI("%s/users/{user}/%s",
+					ø.Authority("https://example.com"),
+					ø.Seg("user", ø.Path("a/b")),
+					"c",
+				),
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cat.Request.URL.String(), "https://example.com/users/a/b/c"),
+		)
+	})
+
+	t.Run("Unfulfilled", func(t *testing.T) {
+		var user http.Promise[string]
+
+		cat := cat.WithContext(context.Background())
+		err := cat.IO(
+			http.GET(
+				ø.URI("https://example.com/users/{user}", ø.Seg("user", &user)),
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.As(err, new(*gurl.Unfulfilled))),
+		)
+	})
+
+	t.Run("NotBound", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Fail(func() {
+				ø.URI("https://example.com/users/{user}/repos/{repo}", ø.Seg("user", "joe"))
+			}).Contain("{repo} is not bound"),
+			it.Fail(func() {
+				ø.URI("https://example.com/users/{user}", ø.Seg("user", "joe"), ø.Seg("repo", "gurl"))
+			}).Contain("{repo} is not declared"),
+			it.Fail(func() {
+				ø.URI("https://example.com/users/{user}", ø.Seg("user", "joe"), ø.Seg("user", "gurl"))
+			}).Contain("{user} is bound twice"),
+		)
+	})
+
+	t.Run("Profile", func(t *testing.T) {
+		err := ø.ValidateURI("${host}/users/{user}", ø.Seg("user", "joe"))
+		it.Then(t).Should(
+			it.Nil(err),
+		)
+	})
+}
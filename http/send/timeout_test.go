@@ -0,0 +1,87 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestTimeout(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				time.Sleep(100 * time.Millisecond)
+			}
+			w.Write([]byte("ok"))
+		}),
+	)
+	defer ts.Close()
+
+	t.Run("Timeout", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/slow", ø.Authority(ts.URL)),
+				ø.Timeout(10*time.Millisecond),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, context.DeadlineExceeded)),
+		)
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/slow", ø.Authority(ts.URL)),
+				ø.Deadline(time.Now().Add(10*time.Millisecond)),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, context.DeadlineExceeded)),
+		)
+	})
+
+	t.Run("Scoped", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/fast", ø.Authority(ts.URL)),
+				ø.Timeout(50*time.Millisecond),
+				ƒ.Status.OK,
+			),
+			µ.GET(
+				ø.URI("%s/slow", ø.Authority(ts.URL)),
+				ø.Timeout(time.Second),
+				ƒ.Status.OK,
+				ƒ.Bytes(buf),
+			),
+			µ.GET(
+				ø.URI("%s/slow", ø.Authority(ts.URL)),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(buf.String(), "ok"),
+		)
+	})
+}
@@ -0,0 +1,151 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+)
+
+// ValidateURI analyses the URI template and its arguments, it reports
+// mismatched number of verbs, unbound named segments, unsupported types
+// of arguments and embedded whitespaces.
+func ValidateURI(uri string, args ...any) error {
+	segs, args := splitSegments(args)
+	if err := validateSegments(uri, segs); err != nil {
+		return err
+	}
+
+	for _, r := range uri {
+		if unicode.IsSpace(r) {
+			return &gurl.InvalidURI{URI: uri, Reason: "embedded whitespace"}
+		}
+	}
+
+	verbs, indexed := verbsOf(uri)
+	if !indexed && len(verbs) != len(args) {
+		return &gurl.InvalidURI{
+			URI:    uri,
+			Reason: fmt.Sprintf("template has %d verbs, %d arguments given", len(verbs), len(args)),
+		}
+	}
+
+	for i, arg := range args {
+		verb := byte('v')
+		if !indexed {
+			verb = verbs[i]
+		}
+
+		if err := validateArg(verb, arg); err != "" {
+			return &gurl.InvalidURI{URI: uri, Reason: fmt.Sprintf("argument %d: %s", i+1, err)}
+		}
+	}
+
+	return nil
+}
+
+// verbsOf returns verbs of the format string, it reports if template uses
+// explicit argument indexes.
+func verbsOf(format string) ([]byte, bool) {
+	verbs := []byte{}
+	indexed := false
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+
+		// skip flags, width, precision and argument indexes
+		j := i + 1
+		for j < len(format) && strings.IndexByte("+-# 0123456789.[]*", format[j]) != -1 {
+			if format[j] == '[' {
+				indexed = true
+			}
+			j++
+		}
+		if j == len(format) {
+			verbs = append(verbs, '!')
+			break
+		}
+
+		if format[j] != '%' {
+			verbs = append(verbs, format[j])
+		}
+		i = j
+	}
+
+	return verbs, indexed
+}
+
+func validateArg(verb byte, arg any) string {
+	if arg == nil {
+		return "nil value"
+	}
+
+	// Note: type of lazy values is known at evaluation time only
+	if _, ok := arg.(http.Future); ok || reflect.ValueOf(arg).Kind() == reflect.Func {
+		return ""
+	}
+
+	switch v := arg.(type) {
+	case Path:
+		return segmentOf(verb, string(v))
+	case *Path:
+		return segmentOf(verb, string(*v))
+	case Authority:
+		return segmentOf(verb, string(v))
+	case *Authority:
+		return segmentOf(verb, string(*v))
+	case *url.URL, string, *string, fmt.Stringer:
+		return verbOf(verb, "s")
+	}
+
+	val := reflect.ValueOf(arg)
+	if val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return "nil value"
+		}
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return verbOf(verb, "dsx")
+	case reflect.Float32, reflect.Float64:
+		return verbOf(verb, "fges")
+	case reflect.Bool:
+		return verbOf(verb, "ts")
+	case reflect.String:
+		return verbOf(verb, "s")
+	default:
+		return fmt.Sprintf("unsupported type %T", arg)
+	}
+}
+
+func verbOf(verb byte, expect string) string {
+	if verb == 'v' || strings.IndexByte(expect, verb) != -1 {
+		return ""
+	}
+	return fmt.Sprintf("verb %%%c is not applicable", verb)
+}
+
+// segmentOf validates unescaped segment of URI
+func segmentOf(verb byte, s string) string {
+	if strings.IndexFunc(s, unicode.IsSpace) != -1 {
+		return "embedded whitespace"
+	}
+	return verbOf(verb, "s")
+}
@@ -0,0 +1,73 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestValidateURI(t *testing.T) {
+	var id http.Promise[int]
+	host, _ := url.Parse("https://example.com")
+
+	t.Run("Valid", func(t *testing.T) {
+		for uri, args := range map[string][]any{
+			"https://example.com":               nil,
+			"https://example.com/%s/%d":         {"a", 1},
+			"%s/users/%v":                       {ø.Authority("https://example.com"), &id},
+			"%s/%s":                             {host, ø.Path("a/b")},
+			"https://example.com/100%%/%s":      {"a"},
+			"https://example.com/%[1]s/%[1]s":   {"a"},
+			"${host}/users/%s?ok=%t&score=%.2f": {"a", true, 0.5},
+		} {
+			it.Then(t).Should(
+				it.Nil(ø.ValidateURI(uri, args...)),
+			)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, tc := range []struct {
+			uri  string
+			args []any
+		}{
+			{"https://example.com/%s/%s", []any{"a"}},
+			{"https://example.com/%s", []any{"a", "b"}},
+			{"https://example.com/a b", nil},
+			{"https://example.com/%d", []any{"a"}},
+			{"https://example.com/%s", []any{map[string]string{}}},
+			{"https://example.com/%s", []any{ø.Path("a b")}},
+			{"https://example.com/%s/%", []any{"a"}},
+			{"https://example.com/%s/\t%s", []any{"a", "b"}},
+		} {
+			err := ø.ValidateURI(tc.uri, tc.args...)
+			it.Then(t).Should(
+				it.True(errors.As(err, new(*gurl.InvalidURI))),
+			)
+		}
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		err := http.New(http.WithStrictURI()).IO(context.Background(),
+			http.GET(ø.URI("https://example.com/%s/%s", "a")),
+		)
+		it.Then(t).Should(
+			it.True(errors.As(err, new(*gurl.InvalidURI))),
+			it.String(err.Error()).Contain("2 verbs, 1 arguments"),
+		)
+	})
+}
@@ -0,0 +1,138 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+//
+// The file implements cookie based login session
+//
+
+// LoginSession evaluates login composition once per stack and reuses
+// the session for sub-sequent arrows. The session is re-authenticated
+// automatically when 401 Unauthorized or redirect to login is detected.
+//
+// The session relies on cookies, so the stack requires WithCookieJar option.
+// CSRF tokens are captured by login composition itself (e.g. using Promise).
+type LoginSession struct {
+	sync.Mutex
+	login  Arrow
+	stacks map[*Protocol]string
+}
+
+// Session creates new login session from login composition
+//
+//	session := http.Session(
+//		http.POST(
+//			ø.URI("https://example.com/login"),
+//			ø.ContentType.Form,
+//			ø.Send(credentials),
+//			ƒ.Status.OK,
+//		),
+//	)
+//
+//	stack.IO(context.Background(),
+//		session.Join(
+//			http.GET(
+//				ø.URI("https://example.com/profile"),
+//				ƒ.Status.OK,
+//			),
+//		),
+//	)
+func Session(login Arrow) *LoginSession {
+	return &LoginSession{
+		login:  login,
+		stacks: map[*Protocol]string{},
+	}
+}
+
+// Join composes arrows into high-order function evaluated within the session.
+func (s *LoginSession) Join(arrows ...Arrow) Arrow {
+	return func(ctx *Context) error {
+		if err := s.authenticate(ctx, false); err != nil {
+			return err
+		}
+
+		err := Join(arrows...)(ctx)
+		if err == nil || !s.expired(ctx) {
+			return err
+		}
+
+		if err := ctx.discardBody(); err != nil {
+			return err
+		}
+
+		if err := s.authenticate(ctx, true); err != nil {
+			return err
+		}
+
+		return Join(arrows...)(ctx)
+	}
+}
+
+// Reset the session, login is evaluated again by next arrow
+func (s *LoginSession) Reset() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.stacks = map[*Protocol]string{}
+}
+
+func (s *LoginSession) authenticate(ctx *Context, force bool) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, has := s.stacks[ctx.stack]; has && !force {
+		return nil
+	}
+
+	session := ctx.stack.WithContext(ctx.Context)
+	if err := session.IO(s.login); err != nil {
+		delete(s.stacks, ctx.stack)
+		return err
+	}
+
+	path := ""
+	if session.Request != nil {
+		path = session.Request.URL.Path
+	}
+	s.stacks[ctx.stack] = path
+
+	return nil
+}
+
+func (s *LoginSession) expired(ctx *Context) bool {
+	if ctx.Response == nil {
+		return false
+	}
+
+	code := ctx.Response.StatusCode
+	if code == http.StatusUnauthorized {
+		return true
+	}
+
+	if code >= 300 && code < 400 {
+		location, err := url.Parse(ctx.Response.Header.Get("Location"))
+		if err != nil {
+			return false
+		}
+
+		s.Lock()
+		path := s.stacks[ctx.stack]
+		s.Unlock()
+
+		return path != "" && location.Path == path
+	}
+
+	return false
+}
@@ -0,0 +1,109 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestSession(t *testing.T) {
+	logins := 0
+	session := "0"
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/login":
+				logins++
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: session})
+				w.WriteHeader(http.StatusOK)
+			case "/expire":
+				session = "1"
+				w.WriteHeader(http.StatusOK)
+			case "/redirect":
+				if c, err := r.Cookie("session"); err != nil || c.Value != session {
+					w.Header().Set("Location", "/login")
+					w.WriteHeader(http.StatusFound)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			default:
+				if c, err := r.Cookie("session"); err != nil || c.Value != session {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}
+		}),
+	)
+	defer ts.Close()
+
+	ses := µ.Session(
+		µ.POST(
+			ø.URI("%s/login", ø.Authority(ts.URL)),
+			ƒ.Status.OK,
+		),
+	)
+
+	request := func(path string) µ.Arrow {
+		return ses.Join(
+			µ.GET(
+				ø.URI("%s%s", ø.Authority(ts.URL), ø.Path(path)),
+				ƒ.Status.OK,
+			),
+		)
+	}
+
+	cat := µ.New(µ.WithCookieJar())
+
+	t.Run("LoginOnce", func(t *testing.T) {
+		err := cat.IO(context.Background(), request("/a"), request("/b"))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(logins, 1),
+		)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		err := cat.IO(context.Background(), request("/expire"), request("/a"))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(logins, 2),
+		)
+	})
+
+	t.Run("RedirectToLogin", func(t *testing.T) {
+		err := cat.IO(context.Background(), request("/expire"))
+		it.Then(t).Should(it.Nil(err))
+
+		session = "2"
+		err = cat.IO(context.Background(), request("/redirect"))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(logins, 3),
+		)
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		ses.Reset()
+		err := cat.IO(context.Background(), request("/a"))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(logins, 4),
+		)
+	})
+}
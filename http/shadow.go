@@ -0,0 +1,213 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+//
+// The file implements shadow traffic, mirroring of requests
+//
+
+// Shadow evaluates primary arrow and sends a copy of every its request
+// to the mirror host asynchronously. The copy carries method, path, headers
+// and payload of the primary request, only the scheme and authority are
+// replaced. The mirror is evaluated over own detached context, it outlives
+// the primary request. Its result is ignored.
+//
+//	http.Shadow(
+//		http.GET(ø.URI("https://example.com/api"), ƒ.Status.OK),
+//		"https://next.example.com",
+//	)
+func Shadow(primary Arrow, mirror string) Arrow {
+	return ShadowWith(primary, mirror, nil)
+}
+
+// ShadowWith is Shadow that reports the result of mirror to the observer.
+// The observer receives nil if the mirror responds identically to the
+// primary, *ShadowDiff if status, headers or payload are different and
+// the error of the transport otherwise.
+func ShadowWith(primary Arrow, mirror string, observe func(error)) Arrow {
+	target, err := url.Parse(mirror)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		panic(fmt.Sprintf("invalid shadow host %q", mirror))
+	}
+
+	s := &shadow{target: target, observe: observe}
+
+	return func(cat *Context) error {
+		parent := cat.shadow
+		cat.shadow = s
+		defer func() { cat.shadow = parent }()
+
+		return primary(cat)
+	}
+}
+
+// ShadowDiff is the difference of mirror response against primary one
+type ShadowDiff struct {
+	Method string
+	URL    string
+	Status [2]int
+	Header []string
+	Body   bool
+}
+
+func (e *ShadowDiff) Error() string {
+	diff := []string{}
+	if e.Status[0] != e.Status[1] {
+		diff = append(diff, fmt.Sprintf("status %d != %d", e.Status[0], e.Status[1]))
+	}
+	if len(e.Header) != 0 {
+		diff = append(diff, fmt.Sprintf("headers %s", strings.Join(e.Header, ", ")))
+	}
+	if e.Body {
+		diff = append(diff, "payload")
+	}
+
+	return fmt.Sprintf("shadow %s %s differs: %s", e.Method, e.URL, strings.Join(diff, "; "))
+}
+
+// Headers specific to the connection or the moment of response,
+// they are not compared.
+var shadowVolatile = map[string]bool{
+	"Age":               true,
+	"Connection":        true,
+	"Date":              true,
+	"Keep-Alive":        true,
+	"Transfer-Encoding": true,
+}
+
+type shadow struct {
+	target  *url.URL
+	observe func(error)
+}
+
+// mirror makes the copy of request before it is sent. The payload is
+// buffered if request is not re-readable. The returned function captures
+// the primary response and sends the copy to the mirror.
+func (s *shadow) mirror(stack *Protocol, eg *http.Request) (func(*http.Response) error, error) {
+	getBody := eg.GetBody
+	if getBody == nil && eg.Body != nil && eg.Body != http.NoBody {
+		buf, err := io.ReadAll(eg.Body)
+		eg.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		eg.Body = io.NopCloser(bytes.NewReader(buf))
+		getBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(buf)), nil
+		}
+	}
+
+	// Note: the copy is detached from context of primary request, which
+	//       is cancelled once the request is evaluated and carries traces
+	//       bound to the primary context.
+	req := eg.Clone(context.Background())
+	req.URL.Scheme = s.target.Scheme
+	req.URL.Host = s.target.Host
+	req.Host = ""
+	req.GetBody = getBody
+
+	if getBody != nil {
+		body, err := getBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+
+	return func(in *http.Response) error {
+		body, err := io.ReadAll(in.Body)
+		in.Body.Close()
+		if err != nil {
+			return err
+		}
+		in.Body = io.NopCloser(bytes.NewReader(body))
+
+		primary := &http.Response{StatusCode: in.StatusCode, Header: in.Header.Clone()}
+		go s.send(stack, req, primary, body)
+
+		return nil
+	}, nil
+}
+
+func (s *shadow) send(stack *Protocol, req *http.Request, primary *http.Response, body []byte) {
+	err := s.compare(stack, req, primary, body)
+	if s.observe != nil {
+		s.observe(err)
+	}
+}
+
+func (s *shadow) compare(stack *Protocol, req *http.Request, primary *http.Response, body []byte) error {
+	in, err := stack.do(req)
+	if err != nil {
+		return err
+	}
+	defer in.Body.Close()
+
+	if stack.Compression {
+		if err := decompress(in); err != nil {
+			return err
+		}
+	}
+
+	// Note: the primary response is compared after rewrite rules of the stack
+	for _, f := range stack.rewrite {
+		if err := f(in); err != nil {
+			return err
+		}
+	}
+
+	mirror, err := io.ReadAll(in.Body)
+	if err != nil {
+		return err
+	}
+
+	diff := &ShadowDiff{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Status: [2]int{primary.StatusCode, in.StatusCode},
+		Header: shadowHeaders(primary.Header, in.Header),
+		Body:   !bytes.Equal(body, mirror),
+	}
+
+	if diff.Status[0] == diff.Status[1] && len(diff.Header) == 0 && !diff.Body {
+		return nil
+	}
+
+	return diff
+}
+
+// shadowHeaders lists names of headers with different values
+func shadowHeaders(a, b http.Header) []string {
+	seq := []string{}
+	for key := range a {
+		if !shadowVolatile[key] && strings.Join(a.Values(key), ", ") != strings.Join(b.Values(key), ", ") {
+			seq = append(seq, key)
+		}
+	}
+	for key := range b {
+		if _, has := a[key]; !has && !shadowVolatile[key] {
+			seq = append(seq, key)
+		}
+	}
+	sort.Strings(seq)
+
+	return seq
+}
@@ -0,0 +1,131 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestShadow(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	requests := make(chan *http.Request, 1)
+	next := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			r.Header.Set("X-Body", string(body))
+			requests <- r
+
+			w.Header().Add("Content-Type", "application/json")
+			w.Header().Add("X-Next", "true")
+			w.Write([]byte(`{"site": "example.net"}`))
+		}),
+	)
+	defer next.Close()
+
+	t.Run("Ignore", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.Shadow(
+				µ.GET(ø.URI(ts.URL+"/json"), ƒ.Status.OK),
+				next.URL,
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+		<-requests
+	})
+
+	t.Run("Identical", func(t *testing.T) {
+		mirror := make(chan error, 1)
+		err := µ.New().IO(context.Background(),
+			µ.ShadowWith(
+				µ.GET(ø.URI(ts.URL+"/json"), ƒ.Status.OK, ƒ.Match(`{"site": "example.com"}`)),
+				ts.URL,
+				func(err error) { mirror <- err },
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Nil(<-mirror),
+		)
+	})
+
+	t.Run("Diff", func(t *testing.T) {
+		mirror := make(chan error, 1)
+		err := µ.New().IO(context.Background(),
+			µ.ShadowWith(
+				µ.GET(ø.URI(ts.URL+"/json"), ƒ.Status.OK, ƒ.Match(`{"site": "example.com"}`)),
+				next.URL,
+				func(err error) { mirror <- err },
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+
+		var diff *µ.ShadowDiff
+		it.Then(t).Should(
+			it.True(errors.As(<-mirror, &diff)),
+			it.Equal(diff.Status, [2]int{200, 200}),
+			it.Seq(diff.Header).Equal("X-Next"),
+			it.True(diff.Body),
+		)
+		<-requests
+	})
+
+	t.Run("Rewrite", func(t *testing.T) {
+		mirror := make(chan error, 1)
+		err := µ.New(µ.WithRewrite(µ.UnwrapJSON("site"))).IO(context.Background(),
+			µ.ShadowWith(
+				µ.GET(ø.URI(ts.URL+"/json"), ƒ.Status.OK, ƒ.Match(`"example.com"`)),
+				ts.URL,
+				func(err error) { mirror <- err },
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Nil(<-mirror),
+		)
+	})
+
+	t.Run("Copy", func(t *testing.T) {
+		mirror := make(chan error, 1)
+		err := µ.New().IO(context.Background(),
+			µ.ShadowWith(
+				µ.POST(
+					ø.URI(ts.URL+"/json"),
+					ø.Header("X-Trace", "abc"),
+					ø.ContentType.JSON,
+					ø.Send(`{"site": "example.com"}`),
+					ƒ.Status.OK,
+				),
+				next.URL,
+				func(err error) { mirror <- err },
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+		<-mirror
+
+		r := <-requests
+		it.Then(t).Should(
+			it.Equal(r.Method, http.MethodPost),
+			it.Equal(r.URL.Path, "/json"),
+			it.Equal(r.Header.Get("X-Trace"), "abc"),
+			it.Equal(r.Header.Get("X-Body"), `{"site": "example.com"}`),
+		)
+	})
+}
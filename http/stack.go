@@ -10,6 +10,8 @@ package http
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net"
 	"net/http"
 	"time"
@@ -30,6 +32,7 @@ func NewRequest(method, url string) (*http.Request, error) {
 type Stack interface {
 	WithContext(context.Context) *Context
 	IO(context.Context, ...Arrow) error
+	IOParallel(context.Context, ...Arrow) error
 }
 
 type Socket interface {
@@ -39,9 +42,34 @@ type Socket interface {
 // Protocol is an instance of Stack
 type Protocol struct {
 	Socket
-	Host     string
-	LogLevel int
-	Memento  bool
+	Host            string
+	LogLevel        int
+	LogPayloadLimit int
+	LogPrettyJSON   bool
+	Memento         bool
+	Compression     bool
+	proxy           bool
+	redirects       bool
+	headers         http.Header
+	maxHeaderBytes  int64
+	maxHeaders      int
+	clock           Clock
+	dns             *DNSCache
+	cache           *httpCache
+	breaker         *circuitBreaker
+	limiter         *rateLimiter
+	logger          *log.Logger
+	middleware      []func(Socket) Socket
+	rewrite         []Rewrite
+	errmapper       []ErrorMapper
+	profile         Profile
+	envVars         Profile
+	flagVars        VarFlags
+	schemas         *SchemaInference
+	strictURI       bool
+	unixSocket      string
+	stats           *stats
+	socket          Socket
 }
 
 // New instance of HTTP Stack
@@ -56,11 +84,21 @@ func New(opt ...Option) Stack {
 
 // New instance of HTTP Stack
 func NewStack(opt ...Option) (Stack, error) {
-	cat := &Protocol{Socket: Client()}
+	cat := &Protocol{Socket: Client(), clock: SystemClock}
 
 	if err := opts.Apply(cat, opt); err != nil {
 		return nil, err
 	}
+	if cat.dns != nil {
+		cat.dns.clock = cat.clock
+	}
+	if cat.cache != nil {
+		cat.cache.clock = cat.clock
+	}
+	if cat.stats != nil {
+		cat.stats.since = cat.clock.Now()
+	}
+	cat.chain()
 
 	return cat, nil
 }
@@ -77,22 +115,48 @@ func (stack *Protocol) WithContext(ctx context.Context) *Context {
 	}
 }
 
-func (stack *Protocol) IO(ctx context.Context, arrows ...Arrow) error {
+func (stack *Protocol) IO(ctx context.Context, arrows ...Arrow) (err error) {
+	if _, scoped := ctx.Value(deferredKey{}).(*deferred); !scoped {
+		var d *deferred
+		ctx, d = withDeferred(ctx)
+		defer func() {
+			stack.cleanup(ctx, d, func(_ *Context, _ time.Duration, failure error) {
+				if failure != nil {
+					err = errors.Join(err, failure)
+				}
+			})
+		}()
+	}
+
+	return stack.io(ctx, arrows...)
+}
+
+func (stack *Protocol) io(ctx context.Context, arrows ...Arrow) error {
 	c := stack.WithContext(ctx)
 
 	for _, f := range arrows {
 		if err := f(c); err != nil {
+			err = stack.mapError(c, err)
 			c.discardBody()
 			return err
 		}
 		if err := c.discardBody(); err != nil {
-			return err
+			return stack.mapError(c, err)
 		}
 	}
 
 	return nil
 }
 
+func (stack *Protocol) logf(format string, v ...any) {
+	if stack.logger != nil {
+		stack.logger.Printf(format, v...)
+		return
+	}
+
+	log.Printf(format, v...)
+}
+
 // Creates default HTTP client
 func Client() *http.Client {
 	return &http.Client{
@@ -9,12 +9,21 @@
 package http_test
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
 	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
 	"github.com/fogfish/it/v2"
 	"github.com/fogfish/opts"
-	"net/http"
-	"testing"
 )
 
 func TestConfig(t *testing.T) {
@@ -39,6 +48,14 @@ func TestConfig(t *testing.T) {
 		it.Then(t).Should(it.Equal(cat.LogLevel, 3))
 	})
 
+	t.Run("WithDebugPayloadLimit", func(t *testing.T) {
+		cat := µ.New(µ.WithDebugPayloadLimit(1024)).(*µ.Protocol)
+		it.Then(t).Should(
+			it.Equal(cat.LogLevel, 3),
+			it.Equal(cat.LogPayloadLimit, 1024),
+		)
+	})
+
 	t.Run("WithMemento", func(t *testing.T) {
 		cat := µ.New(µ.WithMemento(true)).(*µ.Protocol)
 		it.Then(t).Should(it.True(cat.Memento))
@@ -59,6 +76,23 @@ func TestConfig(t *testing.T) {
 		it.Then(t).Should(it.Equiv(cat.Socket.(*http.Client).CheckRedirect, nil))
 	})
 
+	t.Run("WithForeignSocket", func(t *testing.T) {
+		socket := µ.SocketFunc(func(req *http.Request) (*http.Response, error) { return nil, nil })
+		// Note: options predating custom sockets are no-op for them
+		_, err := µ.NewStack(µ.WithClient(socket), µ.WithCookieJar(), µ.WithInsecureTLS(), µ.WithRedirects())
+		it.Then(t).Should(it.Nil(err))
+
+		_, err = µ.NewStack(µ.WithClient(socket), µ.WithDialer(&net.Dialer{}))
+		it.Then(t).ShouldNot(it.Nil(err))
+
+		_, err = µ.NewStack(µ.WithClient(socket), µ.WithStats())
+		it.Then(t).ShouldNot(it.Nil(err))
+
+		// Note: middlewares keep the client of stack configurable
+		_, err = µ.NewStack(µ.WithMiddleware(tagger("a").Wrap), µ.WithCookieJar(), µ.WithInsecureTLS())
+		it.Then(t).Should(it.Nil(err))
+	})
+
 	t.Run("WithFailedConfig", func(t *testing.T) {
 		withError := opts.From(func(*µ.Protocol) error {
 			return fmt.Errorf("error")
@@ -71,3 +105,138 @@ func TestConfig(t *testing.T) {
 	})
 
 }
+
+func TestDebugPayloadLimit(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+
+	err := µ.New(µ.WithDebugPayloadLimit(4)).IO(context.Background(),
+		µ.POST(
+			ø.URI("%s/json", ø.Authority(ts.URL)),
+			ø.ContentType.Text,
+			ø.Send("0123456789"),
+			ƒ.Status.OK,
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.String(buf.String()).Contain("0123...\n[truncated 6 of 10 bytes]"),
+		it.String(buf.String()).Contain("{\"si...\n[truncated 19 of 23 bytes]"),
+	)
+}
+
+func TestLogWriter(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	buf := &bytes.Buffer{}
+	err := µ.New(µ.WithDebugRequest, µ.WithLogWriter(buf)).IO(context.Background(),
+		µ.GET(
+			ø.URI("%s/json", ø.Authority(ts.URL)),
+			ƒ.Status.OK,
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.String(buf.String()).Contain("GET /json HTTP/1.1"),
+	)
+}
+
+func TestDebugPrettyJSON(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	req := µ.GET(
+		ø.URI("%s/json", ø.Authority(ts.URL)),
+		ƒ.Status.OK,
+	)
+
+	t.Run("Enabled", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		log.SetOutput(buf)
+		defer log.SetOutput(os.Stderr)
+
+		err := µ.New(µ.WithDebugPayload, µ.WithLogPrettyJSON()).IO(context.Background(), req)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.String(buf.String()).Contain("{\n  \"site\": \"example.com\"\n}"),
+		)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		log.SetOutput(buf)
+		defer log.SetOutput(os.Stderr)
+
+		err := µ.New(µ.WithDebugPayload).IO(context.Background(), req)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.String(buf.String()).Contain(`{"site": "example.com"}`),
+		)
+	})
+}
+
+func TestDefaultHeaders(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-User-Agent", r.Header.Get("User-Agent"))
+			w.Header().Set("X-Tenant", r.Header.Get("X-Tenant"))
+			w.Header().Set("X-Api-Key", r.Header.Get("X-Api-Key"))
+		}),
+	)
+	defer ts.Close()
+
+	stack := µ.New(
+		µ.WithDefaultHeader("User-Agent", "gurl/test"),
+		µ.WithDefaultHeaders(map[string]string{
+			"X-Tenant":  "acme",
+			"X-Api-Key": "secret",
+		}),
+	)
+
+	t.Run("Default", func(t *testing.T) {
+		err := stack.IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+				ƒ.Header("X-User-Agent", "gurl/test"),
+				ƒ.Header("X-Tenant", "acme"),
+				ƒ.Header("X-Api-Key", "secret"),
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		err := stack.IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				ø.Header("X-Tenant", "other"),
+				ƒ.Status.OK,
+				ƒ.Header("X-User-Agent", "gurl/test"),
+				ƒ.Header("X-Tenant", "other"),
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("NilHeader", func(t *testing.T) {
+		err := stack.IO(context.Background(),
+			µ.Join(
+				func(ctx *µ.Context) error {
+					req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+					req.Header = nil
+					ctx.Request = req
+					return err
+				},
+				ƒ.Status.OK,
+				ƒ.Header("X-Tenant", "acme"),
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+	})
+}
@@ -0,0 +1,242 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//
+// The file implements statistics of the protocol stack, counters are
+// accumulated since creation of the stack.
+//
+
+// Stats is the snapshot of counters of the stack, suitable for health
+// endpoints of embedding services without metrics backend.
+type Stats struct {
+	Since          time.Time `json:"since"`
+	Requests       uint64    `json:"requests"`
+	Failures       uint64    `json:"failures"`      // I/O failures, no response
+	ClientErrors   uint64    `json:"client_errors"` // 4xx responses
+	ServerErrors   uint64    `json:"server_errors"` // 5xx responses
+	BytesSent      uint64    `json:"bytes_sent"`
+	BytesReceived  uint64    `json:"bytes_received"`
+	ActiveConns    int64     `json:"active_conns"`
+	IdleConns      int64     `json:"idle_conns"`
+	DNSCacheHits   uint64    `json:"dns_cache_hits,omitempty"`
+	DNSCacheMisses uint64    `json:"dns_cache_misses,omitempty"`
+	CacheHits      uint64    `json:"cache_hits,omitempty"`
+	CacheMisses    uint64    `json:"cache_misses,omitempty"`
+
+	// Counters of tagged requests (see ø.Tag) by tags formatted as
+	// key=value pairs ordered by key
+	Tags map[string]TagStats `json:"tags,omitempty"`
+}
+
+// TagStats is the snapshot of counters of tagged requests
+type TagStats struct {
+	Requests     uint64 `json:"requests"`
+	Failures     uint64 `json:"failures"`
+	ClientErrors uint64 `json:"client_errors"`
+	ServerErrors uint64 `json:"server_errors"`
+}
+
+type stats struct {
+	since         time.Time
+	requests      atomic.Uint64
+	failures      atomic.Uint64
+	clientErrors  atomic.Uint64
+	serverErrors  atomic.Uint64
+	bytesSent     atomic.Uint64
+	bytesReceived atomic.Uint64
+	inflight      atomic.Int64
+	conns         atomic.Int64
+	tags          sync.Map
+}
+
+type tagStats struct {
+	requests     atomic.Uint64
+	failures     atomic.Uint64
+	clientErrors atomic.Uint64
+	serverErrors atomic.Uint64
+}
+
+// Stats returns snapshot of counters since creation of the stack, the stack
+// shall be configured with WithStats.
+// Requests served from HTTP cache are not counted as requests.
+func (stack *Protocol) Stats() Stats {
+	s := stack.stats
+	if s == nil {
+		return Stats{}
+	}
+
+	active := s.inflight.Load()
+	conns := s.conns.Load()
+
+	snapshot := Stats{
+		Since:         s.since,
+		Requests:      s.requests.Load(),
+		Failures:      s.failures.Load(),
+		ClientErrors:  s.clientErrors.Load(),
+		ServerErrors:  s.serverErrors.Load(),
+		BytesSent:     s.bytesSent.Load(),
+		BytesReceived: s.bytesReceived.Load(),
+		ActiveConns:   min(active, conns),
+		IdleConns:     max(conns-active, 0),
+	}
+
+	s.tags.Range(func(key, val any) bool {
+		if snapshot.Tags == nil {
+			snapshot.Tags = map[string]TagStats{}
+		}
+		t := val.(*tagStats)
+		snapshot.Tags[key.(string)] = TagStats{
+			Requests:     t.requests.Load(),
+			Failures:     t.failures.Load(),
+			ClientErrors: t.clientErrors.Load(),
+			ServerErrors: t.serverErrors.Load(),
+		}
+		return true
+	})
+
+	if stack.dns != nil {
+		snapshot.DNSCacheHits = stack.dns.hits.Load()
+		snapshot.DNSCacheMisses = stack.dns.misses.Load()
+	}
+
+	if stack.cache != nil {
+		snapshot.CacheHits = stack.cache.hits.Load()
+		snapshot.CacheMisses = stack.cache.misses.Load()
+	}
+
+	return snapshot
+}
+
+func withStats(cat *Protocol) error {
+	cli, err := clientOf(cat)
+	if err != nil {
+		return err
+	}
+
+	t, err := transportOf(cli)
+	if err != nil {
+		return err
+	}
+
+	dial := t.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	cat.stats = &stats{}
+	t.DialContext = cat.stats.dialContext(dial)
+
+	return nil
+}
+
+func (s *stats) dialContext(dial dialContext) dialContext {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		s.conns.Add(1)
+		return &statsConn{Conn: conn, stats: s}, nil
+	}
+}
+
+type statsConn struct {
+	net.Conn
+	stats *stats
+	once  sync.Once
+}
+
+func (c *statsConn) Close() error {
+	c.once.Do(func() { c.stats.conns.Add(-1) })
+	return c.Conn.Close()
+}
+
+// do counts the request and wraps payloads for accounting of bytes
+func (s *stats) do(req *http.Request, socket func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	s.requests.Add(1)
+	s.inflight.Add(1)
+
+	tagged := s.tagged(req)
+	if tagged != nil {
+		tagged.requests.Add(1)
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = &statsBody{ReadCloser: req.Body, counter: &s.bytesSent}
+	}
+
+	in, err := socket(req)
+	if err != nil {
+		s.inflight.Add(-1)
+		s.failures.Add(1)
+		if tagged != nil {
+			tagged.failures.Add(1)
+		}
+		return in, err
+	}
+
+	switch {
+	case in.StatusCode >= 500:
+		s.serverErrors.Add(1)
+		if tagged != nil {
+			tagged.serverErrors.Add(1)
+		}
+	case in.StatusCode >= 400:
+		s.clientErrors.Add(1)
+		if tagged != nil {
+			tagged.clientErrors.Add(1)
+		}
+	}
+
+	in.Body = &statsBody{ReadCloser: in.Body, counter: &s.bytesReceived, done: func() { s.inflight.Add(-1) }}
+	return in, nil
+}
+
+// tagged returns counters of tags attached to the request
+func (s *stats) tagged(req *http.Request) *tagStats {
+	tags := TagsOf(req.Context())
+	if len(tags) == 0 {
+		return nil
+	}
+
+	val, _ := s.tags.LoadOrStore(formatTags(tags), &tagStats{})
+	return val.(*tagStats)
+}
+
+type statsBody struct {
+	io.ReadCloser
+	counter *atomic.Uint64
+	done    func()
+	once    sync.Once
+}
+
+func (b *statsBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.counter.Add(uint64(n))
+	return n, err
+}
+
+func (b *statsBody) Close() error {
+	if b.done != nil {
+		b.once.Do(b.done)
+	}
+	return b.ReadCloser.Close()
+}
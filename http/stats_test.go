@@ -0,0 +1,66 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestStats(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/404":
+				w.WriteHeader(http.StatusNotFound)
+			case "/500":
+				w.WriteHeader(http.StatusInternalServerError)
+			default:
+				w.Write([]byte("hello"))
+			}
+		}),
+	)
+	defer ts.Close()
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	stack := µ.New(µ.WithStats())
+	for _, path := range []string{"/", "/404", "/500"} {
+		stack.IO(context.Background(),
+			µ.POST(
+				ø.URI("%s%s", ø.Authority(ts.URL), ø.Path(path)),
+				ø.ContentType.Text,
+				ø.Send("abc"),
+				ƒ.Status.OK,
+			),
+		)
+	}
+	stack.IO(context.Background(), µ.GET(ø.URI(down.URL), ƒ.Status.OK))
+
+	stats := stack.(*µ.Protocol).Stats()
+	it.Then(t).Should(
+		it.Equal(stats.Requests, 4),
+		it.Equal(stats.Failures, 1),
+		it.Equal(stats.ClientErrors, 1),
+		it.Equal(stats.ServerErrors, 1),
+		it.Equal(stats.BytesSent, 9),
+		it.Equal(stats.BytesReceived, 5),
+		it.Equal(stats.ActiveConns, 0),
+		it.Equal(stats.IdleConns, 1),
+		it.True(!stats.Since.IsZero()),
+	)
+}
@@ -63,6 +63,10 @@ func NewStatusCode(code int, required ...StatusCode) StatusCode {
 
 // Error makes StatusCode to be error
 func (e StatusCode) Error() string {
+	if e.IsClass() {
+		return fmt.Sprintf("HTTP %s", e.Text())
+	}
+
 	status := e.StatusCode()
 	if req := e.Required(); req != 0 {
 		return fmt.Sprintf("HTTP Status `%d %s`, required `%d %s`.",
@@ -71,14 +75,40 @@ func (e StatusCode) Error() string {
 	return fmt.Sprintf("HTTP %d %s", status, http.StatusText(status))
 }
 
-// Is compares wrapped errors
+// Is compares wrapped errors, the class of status codes matches any code
+// of the class.
+//
+//	errors.Is(err, http.StatusClass4xx)
 func (e StatusCode) Is(err error) bool {
 	if code, ok := err.(StatusCode); ok {
-		return e.StatusCode() == code.StatusCode()
+		return e.Match(code.StatusCode()) || code.Match(e.StatusCode())
 	}
 	return false
 }
 
+// IsClass returns true if status code defines the class of codes (e.g. 2xx)
+func (e StatusCode) IsClass() bool {
+	code := e.StatusCode()
+	return code >= 1 && code <= 5
+}
+
+// Match returns true if integer code equals to status code or belongs
+// to the class of codes.
+func (e StatusCode) Match(code int) bool {
+	if e.IsClass() {
+		return code/100 == e.StatusCode()
+	}
+	return e.StatusCode() == code
+}
+
+// Text is human readable status code, e.g. 200 or 2xx
+func (e StatusCode) Text() string {
+	if e.IsClass() {
+		return fmt.Sprintf("%dxx", e.StatusCode())
+	}
+	return fmt.Sprintf("%d", e.StatusCode())
+}
+
 // Value transforms StatusCode type to integer value: StatusCode ⟼ int
 func (e StatusCode) StatusCode() int {
 	return int(e) & 0xffff
@@ -90,6 +120,13 @@ func (e StatusCode) Required() int {
 }
 
 const (
+	// Classes of status codes, matches any code of the class
+	StatusClass1xx = StatusCode(1)
+	StatusClass2xx = StatusCode(2)
+	StatusClass3xx = StatusCode(3)
+	StatusClass4xx = StatusCode(4)
+	StatusClass5xx = StatusCode(5)
+
 	//
 	StatusContinue           = StatusCode(http.StatusContinue)
 	StatusSwitchingProtocols = StatusCode(http.StatusSwitchingProtocols)
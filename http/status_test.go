@@ -120,3 +120,32 @@ func TestStatusCodeText(t *testing.T) {
 			it.True(errors.Is(code, fmt.Errorf("some error"))),
 		)
 }
+
+func TestStatusCodeClass(t *testing.T) {
+	for class, codes := range map[gurl.StatusCode][]int{
+		gurl.StatusClass1xx: {100, 199},
+		gurl.StatusClass2xx: {200, 204, 299},
+		gurl.StatusClass3xx: {301, 304},
+		gurl.StatusClass4xx: {400, 404, 429},
+		gurl.StatusClass5xx: {500, 503},
+	} {
+		for _, code := range codes {
+			it.Then(t).Should(
+				it.True(class.IsClass()),
+				it.True(class.Match(code)),
+				it.True(errors.Is(gurl.NewStatusCode(code), class)),
+			).ShouldNot(
+				it.True(class.Match(code + 100)),
+			)
+		}
+	}
+
+	it.Then(t).Should(
+		it.Equal(gurl.StatusClass4xx.Error(), "HTTP 4xx"),
+		it.Equal(gurl.StatusClass4xx.Text(), "4xx"),
+		it.Equal(gurl.StatusNotFound.Text(), "404"),
+	).ShouldNot(
+		it.True(gurl.StatusNotFound.IsClass()),
+		it.True(errors.Is(gurl.StatusNotFound, gurl.StatusClass5xx)),
+	)
+}
@@ -0,0 +1,107 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/fogfish/gurl/v2"
+)
+
+//
+// The file implements metadata tags of I/O, the labels aggregating
+// metrics, logs and reports by logical operation.
+//
+
+type tagsKey struct{}
+
+// TagsOf returns tags of I/O attached to the context of request. Use it
+// within middlewares to label metrics by logical operation.
+func TagsOf(ctx context.Context) map[string]string {
+	if tags, ok := ctx.Value(tagsKey{}).(map[string]string); ok {
+		return tags
+	}
+	return nil
+}
+
+func withTags(ctx *Context, eg *http.Request) *http.Request {
+	if len(ctx.Tags) == 0 {
+		return eg
+	}
+
+	return eg.WithContext(context.WithValue(eg.Context(), tagsKey{}, maps.Clone(ctx.Tags)))
+}
+
+// StatusError is StatusCode of tagged I/O, it carries tags of the failed
+// request. The error matches the status code as StatusCode does, use
+// errors.Is or errors.As instead of type assertion of StatusCode.
+//
+//	errors.Is(err, http.StatusNotFound)
+//
+//	var code http.StatusCode
+//	errors.As(err, &code)
+type StatusError struct {
+	StatusCode
+	Tags map[string]string
+}
+
+func (e *StatusError) Unwrap() error { return e.StatusCode }
+
+func (e *StatusError) Is(target error) bool {
+	code, ok := target.(StatusCode)
+	return ok && code == e.StatusCode
+}
+
+func (e *StatusError) As(target any) bool {
+	if code, ok := target.(*StatusCode); ok {
+		*code = e.StatusCode
+		return true
+	}
+	return false
+}
+
+// attaches tags of the request to the error, unless the error carries own.
+// The error is copied, values returned by arrows might be shared.
+func withErrorTags(tags map[string]string, err error) error {
+	if len(tags) == 0 {
+		return err
+	}
+
+	switch e := err.(type) {
+	case StatusCode:
+		return &StatusError{StatusCode: e, Tags: maps.Clone(tags)}
+	case *gurl.NoMatch:
+		if e.Tags == nil {
+			c := *e
+			c.Tags = maps.Clone(tags)
+			return &c
+		}
+	case *RetryError:
+		if e.Tags == nil {
+			c := *e
+			c.Tags = maps.Clone(tags)
+			return &c
+		}
+	}
+
+	return err
+}
+
+// formats tags as key=value pairs ordered by key
+func formatTags(tags map[string]string) string {
+	seq := make([]string, 0, len(tags))
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		seq = append(seq, key+"="+tags[key])
+	}
+	return strings.Join(seq, " ")
+}
@@ -0,0 +1,181 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+func TestTags(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	var tags map[string]string
+	mw := func(next µ.Socket) µ.Socket {
+		return µ.SocketFunc(func(req *http.Request) (*http.Response, error) {
+			tags = µ.TagsOf(req.Context())
+			return next.Do(req)
+		})
+	}
+
+	logs := &bytes.Buffer{}
+	stack := µ.New(
+		µ.WithHost(ts.URL),
+		µ.WithMiddleware(mw),
+		µ.WithDebugRequest,
+		µ.WithLogWriter(logs),
+	)
+
+	seq := µ.Once(stack,
+		func() µ.Arrow {
+			return µ.GET(
+				ø.URI("/json"),
+				ø.Tag("operation", "get-site"),
+				ø.Tag("team", "web"),
+				ƒ.Status.OK,
+			)
+		},
+	)
+
+	it.Then(t).Should(
+		it.Equal(len(seq), 1),
+		it.Equal(seq[0].Status, "success"),
+		it.Equal(seq[0].Tags["operation"], "get-site"),
+		it.Equal(tags["operation"], "get-site"),
+		it.Equal(tags["team"], "web"),
+		it.True(strings.Contains(logs.String(), ">>>> operation=get-site team=web\n")),
+	)
+}
+
+func TestTagsScope(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	var tags []map[string]string
+	mw := func(next µ.Socket) µ.Socket {
+		return µ.SocketFunc(func(req *http.Request) (*http.Response, error) {
+			tags = append(tags, µ.TagsOf(req.Context()))
+			return next.Do(req)
+		})
+	}
+
+	stack := µ.New(µ.WithHost(ts.URL), µ.WithMiddleware(mw))
+	err := stack.IO(context.Background(),
+		ø.Tag("suite", "site"),
+		µ.GET(ø.URI("/json"), ø.Tag("operation", "get-site"), ƒ.Status.OK),
+		µ.GET(ø.URI("/json"), ƒ.Status.OK),
+	)
+
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(len(tags), 2),
+		it.Equal(tags[0]["suite"], "site"),
+		it.Equal(tags[0]["operation"], "get-site"),
+		it.Equal(tags[1]["suite"], "site"),
+		it.Equal(tags[1]["operation"], ""),
+	)
+}
+
+func TestTagsError(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	stack := µ.New(µ.WithHost(ts.URL))
+
+	t.Run("StatusCode", func(t *testing.T) {
+		var p µ.Problem
+		err := stack.IO(context.Background(),
+			µ.GET(ø.URI("/unknown"), ø.Tag("operation", "get-site"), ƒ.Guard(&p)),
+		)
+
+		var e *µ.StatusError
+		var code µ.StatusCode
+		it.Then(t).Should(
+			it.True(errors.Is(err, µ.StatusBadRequest)),
+			it.True(errors.As(err, &e)),
+			it.Equal(e.Tags["operation"], "get-site"),
+			it.True(errors.As(err, &code)),
+			it.Equal(code, µ.StatusBadRequest),
+		)
+	})
+
+	t.Run("SharedNoMatch", func(t *testing.T) {
+		shared := &gurl.NoMatch{ID: "shared"}
+		err := stack.IO(context.Background(),
+			µ.GET(ø.URI("/json"), ø.Tag("operation", "get-site"),
+				func(*µ.Context) error { return shared },
+			),
+		)
+
+		var e *gurl.NoMatch
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Equal(e.Tags["operation"], "get-site"),
+			it.Equal(len(shared.Tags), 0),
+		)
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		err := stack.IO(context.Background(),
+			µ.GET(ø.URI("/json"), ø.Tag("operation", "get-site"), ƒ.Status.OK, ƒ.Match(`{"site": "example.net"}`)),
+		)
+
+		var e *gurl.NoMatch
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Equal(e.Tags["operation"], "get-site"),
+		)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		err := stack.IO(context.Background(),
+			µ.GET(ø.URI("/json"), ø.Tag("operation", "get-site"),
+				µ.Retry(1, 0, ƒ.Status.NotFound),
+			),
+		)
+
+		var e *µ.RetryError
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Equal(e.Tags["operation"], "get-site"),
+		)
+	})
+}
+
+func TestTagsStats(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	stack := µ.New(µ.WithHost(ts.URL), µ.WithStats())
+	err := stack.IO(context.Background(),
+		µ.GET(ø.URI("/json"), ø.Tag("operation", "get-site"), ƒ.Status.OK),
+		µ.GET(ø.URI("/unknown"), ø.Tag("operation", "get-site"), ƒ.Status.BadRequest),
+		µ.GET(ø.URI("/json"), ƒ.Status.OK),
+	)
+
+	stats := stack.(*µ.Protocol).Stats()
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(stats.Requests, 3),
+		it.Equal(len(stats.Tags), 1),
+		it.Equal(stats.Tags["operation=get-site"].Requests, 2),
+		it.Equal(stats.Tags["operation=get-site"].ClientErrors, 1),
+	)
+}
@@ -0,0 +1,56 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+)
+
+//
+// The file implements TLS configuration of the protocol stack
+//
+
+func withTLSConfig(cat *Protocol, conf *tls.Config) error {
+	return tlsConfig(cat, func(t *http.Transport) {
+		t.TLSClientConfig = conf.Clone()
+	})
+}
+
+func withRootCAs(cat *Protocol, pool *x509.CertPool) error {
+	return tlsConfig(cat, func(t *http.Transport) {
+		t.TLSClientConfig.RootCAs = pool
+	})
+}
+
+func withClientCertificate(cat *Protocol, cert tls.Certificate) error {
+	return tlsConfig(cat, func(t *http.Transport) {
+		t.TLSClientConfig.Certificates = append(t.TLSClientConfig.Certificates, cert)
+	})
+}
+
+func tlsConfig(cat *Protocol, f func(*http.Transport)) error {
+	cli, err := clientOf(cat)
+	if err != nil {
+		return err
+	}
+
+	switch t := cli.Transport.(type) {
+	case *http.Transport:
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		f(t)
+	default:
+		return fmt.Errorf("unsupported transport type %T", t)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/it/v2"
+)
+
+// self-signed certificate usable by both server and client
+func certificate(t *testing.T) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	it.Then(t).Should(it.Nil(err))
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gurl"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	it.Then(t).Should(it.Nil(err))
+
+	pkcs, err := x509.MarshalECPrivateKey(key)
+	it.Then(t).Should(it.Nil(err))
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: pkcs})
+}
+
+func TestMutualTLS(t *testing.T) {
+	certPEM, keyPEM := certificate(t)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	it.Then(t).Should(it.Nil(err))
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+
+	ts := httptest.NewUnstartedServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	it.Then(t).Should(
+		it.Nil(os.WriteFile(certFile, certPEM, 0600)),
+		it.Nil(os.WriteFile(keyFile, keyPEM, 0600)),
+	)
+
+	req := µ.GET(
+		ø.URI(ts.URL),
+		ƒ.Status.OK,
+	)
+
+	t.Run("ClientTLS", func(t *testing.T) {
+		err := µ.New(µ.WithRootCAs(pool), µ.WithClientTLS(certFile, keyFile)).
+			IO(context.Background(), req)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("ClientCertificate", func(t *testing.T) {
+		err := µ.New(µ.WithRootCAs(pool), µ.WithClientCertificate(cert)).
+			IO(context.Background(), req)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("TLSConfig", func(t *testing.T) {
+		conf := &tls.Config{RootCAs: pool, Certificates: []tls.Certificate{cert}}
+		err := µ.New(µ.WithTLSConfig(conf)).IO(context.Background(), req)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("NoCertificate", func(t *testing.T) {
+		err := µ.New(µ.WithRootCAs(pool)).IO(context.Background(), req)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+
+	t.Run("UnknownRootCA", func(t *testing.T) {
+		err := µ.New(µ.WithClientCertificate(cert)).IO(context.Background(), req)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+
+	t.Run("BadFiles", func(t *testing.T) {
+		_, err := µ.NewStack(µ.WithClientTLS(keyFile, certFile))
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+}
@@ -9,27 +9,32 @@
 package http
 
 import (
+	"bytes"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"image"
 	"io"
 	"net/http"
+	"reflect"
 	"strings"
 	"time"
 
 	"github.com/ajg/form"
 	"github.com/fogfish/gurl/v2"
+	"google.golang.org/protobuf/proto"
 )
 
 // Arrow is a morphism applied to HTTP protocol stack
 type Arrow func(*Context) error
 
 type ReadableHeaderValues interface {
-	int | string | time.Time
+	int | string | time.Time | time.Duration
 }
 
 type WriteableHeaderValues interface {
-	*int | *string | *time.Time
+	*int | *string | *time.Time | *time.Duration
 }
 
 type MatchableHeaderValues interface {
@@ -39,7 +44,14 @@ type MatchableHeaderValues interface {
 // Join composes HTTP arrows to high-order function
 // (a ⟼ b, b ⟼ c, c ⟼ d) ⤇ a ⟼ d
 func Join(arrows ...Arrow) Arrow {
-	return func(cat *Context) error {
+	return Annotated(func(cat *Context) error {
+		if cat.probe != nil {
+			for _, f := range arrows {
+				cat.probe.eval(cat, f)
+			}
+			return nil
+		}
+
 		for _, f := range arrows {
 			if err := f(cat); err != nil {
 				return err
@@ -47,7 +59,7 @@ func Join(arrows ...Arrow) Arrow {
 		}
 
 		return nil
-	}
+	})
 }
 
 // Bind composes HTTP arrows to high-order function
@@ -66,6 +78,39 @@ func Bind(arrows ...interface{ Arrow() Arrow }) Arrow {
 	}
 }
 
+// Soft composes HTTP arrows to high-order function, which evaluates all
+// arrows even after the failure. Errors of all arrows are joined, so that
+// single run reports every violated expectation about the response.
+// The evaluation stops if response is not available (e.g. I/O failure).
+//
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		http.Soft(
+//			ƒ.Status.OK,
+//			ƒ.ContentType.JSON,
+//			ƒ.Header("X-Version", "1.0"),
+//		),
+//	)
+func Soft(arrows ...Arrow) Arrow {
+	return Annotated(func(cat *Context) error {
+		if cat.probe != nil {
+			return Join(arrows...)(cat)
+		}
+
+		var errs []error
+		for _, f := range arrows {
+			if err := f(cat); err != nil {
+				errs = append(errs, err)
+				if cat.Response == nil {
+					break
+				}
+			}
+		}
+
+		return errors.Join(errs...)
+	})
+}
+
 // GET composes HTTP arrows to high-order function for HTTP GET request
 // (a ⟼ b, b ⟼ c, c ⟼ d) ⤇ a ⟼ d
 func GET(arrows ...Arrow) Arrow { return method(http.MethodGet, arrows) }
@@ -91,16 +136,28 @@ func DELETE(arrows ...Arrow) Arrow { return method(http.MethodDelete, arrows) }
 func PATCH(arrows ...Arrow) Arrow { return method(http.MethodPatch, arrows) }
 
 func method(verb string, arrows []Arrow) Arrow {
-	return func(ctx *Context) error {
+	return Annotated(func(ctx *Context) error {
 		ctx.Method = verb
+		defer ctx.release()
+
+		tags := ctx.Tags
+		defer func() {
+			ctx.tagged = ctx.Tags
+			ctx.Tags = tags
+		}()
+
+		if ctx.Annotate(Step{Kind: "http." + verb, Method: verb}) {
+			return Join(arrows...)(ctx)
+		}
+
 		for _, f := range arrows {
 			if err := f(ctx); err != nil {
-				return err
+				return withErrorTags(ctx.Tags, err)
 			}
 		}
 
 		return nil
-	}
+	})
 }
 
 // Executes protocol operation
@@ -130,23 +187,131 @@ func IO[T any](ctx *Context, arrows ...Arrow) (*T, error) {
 }
 
 func HintedContentCodec[T any](content string, stream io.ReadCloser, data *T) error {
+	if codec, ok := any(data).(gurl.Decodable); ok {
+		return codec.DecodeBody(content, stream)
+	}
+
+	raw := &rawSnapshot{limit: snapshotLimit}
+	reader := io.TeeReader(stream, raw)
+
+	// Note: charset is only applicable to text payloads (json, form, xml),
+	//       binary payloads are decoded as-is.
+	text, transcoded := charsetReader(content, stream)
+	if transcoded {
+		text = io.TeeReader(text, raw)
+	} else {
+		text = reader
+	}
+
+	var err error
+	codec, hasCodec := LookupCodec(content)
 	switch {
+	case hasCodec:
+		err = codec.Decode(reader, data)
+	case strings.Contains(content, "ndjson") || strings.Contains(content, "jsonl"):
+		err = decodeNDJSON(PrefixReader(text), data)
 	case strings.Contains(content, "json"):
-		return json.NewDecoder(stream).Decode(data)
+		err = json.NewDecoder(PrefixReader(text, PrefixXSSI...)).Decode(data)
 	case strings.Contains(content, "www-form"):
-		return form.NewDecoder(stream).Decode(data)
+		err = form.NewDecoder(text).Decode(data)
+	case strings.Contains(content, "xml"):
+		decoder := xml.NewDecoder(text)
+		decoder.CharsetReader = xmlCharsetReader(transcoded)
+		err = decoder.Decode(data)
+	case strings.Contains(content, "protobuf"):
+		msg, ok := any(data).(proto.Message)
+		if !ok {
+			return fmt.Errorf("decode %s: %T is not proto.Message", content, data)
+		}
+		var bin []byte
+		if bin, err = io.ReadAll(reader); err == nil {
+			err = proto.Unmarshal(bin, msg)
+		}
 	case strings.HasPrefix(content, "image/"):
-		img, _, err := image.Decode(stream)
+		var img image.Image
+		img, _, err = image.Decode(reader)
 		if err == nil {
 			*data = img.(T)
 		}
-		return err
 	default:
 		return &gurl.NoMatch{
 			ID:       "http.Recv",
-			Diff:     fmt.Sprintf("- Content-Type: {json | www-form | image}\n+ Content-Type: %s", content),
+			Diff:     fmt.Sprintf("- Content-Type: {json | www-form | xml | protobuf | image | codec}\n+ Content-Type: %s", content),
 			Protocol: "codec",
 			Actual:   content,
 		}
 	}
+
+	if err != nil {
+		return &gurl.DecodeError{
+			ContentType: content,
+			Offset:      offsetOf(err),
+			Snapshot:    raw.Bytes(),
+			Err:         err,
+		}
+	}
+
+	return nil
+}
+
+// decodeNDJSON decodes JSON Lines into slice, record-by-record
+func decodeNDJSON(r io.Reader, data any) error {
+	seq := reflect.ValueOf(data).Elem()
+	if seq.Kind() == reflect.Interface && seq.NumMethod() == 0 {
+		var vals []any
+		if err := decodeNDJSON(r, &vals); err != nil {
+			return err
+		}
+		seq.Set(reflect.ValueOf(vals))
+		return nil
+	}
+
+	if seq.Kind() != reflect.Slice {
+		return fmt.Errorf("decode ndjson requires slice, %T given", data)
+	}
+
+	codec := json.NewDecoder(r)
+	for {
+		val := reflect.New(seq.Type().Elem())
+		if err := codec.Decode(val.Interface()); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		seq.Set(reflect.Append(seq, val.Elem()))
+	}
+}
+
+// snapshotLimit is max size of payload captured by DecodeError
+const snapshotLimit = 1024
+
+// rawSnapshot captures prefix of the stream up to the limit
+type rawSnapshot struct {
+	bytes.Buffer
+	limit int
+}
+
+func (s *rawSnapshot) Write(p []byte) (int, error) {
+	if n := s.limit - s.Len(); n > 0 {
+		if len(p) < n {
+			n = len(p)
+		}
+		s.Buffer.Write(p[:n])
+	}
+	return len(p), nil
+}
+
+func offsetOf(err error) int64 {
+	var syntax *json.SyntaxError
+	if errors.As(err, &syntax) {
+		return syntax.Offset
+	}
+
+	var typed *json.UnmarshalTypeError
+	if errors.As(err, &typed) {
+		return typed.Offset
+	}
+
+	return -1
 }
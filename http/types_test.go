@@ -11,6 +11,7 @@ package http_test
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"image"
 	_ "image/png"
@@ -160,6 +161,62 @@ func TestBindFailed(t *testing.T) {
 	)
 }
 
+func TestSoft(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	t.Run("Success", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				µ.Soft(
+					ƒ.Status.OK,
+					ƒ.ContentType.JSON,
+				),
+			),
+		)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("Accumulate", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/json", ø.Authority(ts.URL)),
+				µ.Soft(
+					ƒ.Status.Created,
+					ƒ.ContentType.HTML,
+					ƒ.Header("X-Unknown", "*"),
+					ƒ.Match(`{"site": "example.com"}`),
+				),
+			),
+		)
+
+		var seq interface{ Unwrap() []error }
+		it.Then(t).Should(
+			it.True(errors.As(err, &seq)),
+			it.Equal(len(seq.Unwrap()), 3),
+		)
+	})
+
+	t.Run("Unavailable", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("http://127.0.0.1:1"),
+				µ.Soft(
+					ƒ.Status.OK,
+					ƒ.ContentType.JSON,
+				),
+			),
+		)
+
+		var seq interface{ Unwrap() []error }
+		it.Then(t).Should(
+			it.True(errors.As(err, &seq)),
+			it.Equal(len(seq.Unwrap()), 1),
+		)
+	})
+}
+
 func TestIOWithContext(t *testing.T) {
 	ts := mock()
 	defer ts.Close()
@@ -10,6 +10,7 @@ package gurl
 
 import (
 	"fmt"
+	"io"
 )
 
 // NotSupported is returned if communication schema is not supported.
@@ -19,13 +20,56 @@ func (e *NotSupported) Error() string {
 	return fmt.Sprintf("Not supported: %s", e.URL)
 }
 
+// InvalidURI is returned if URI template is malformed.
+type InvalidURI struct {
+	URI    string
+	Reason string
+}
+
+func (e *InvalidURI) Error() string {
+	return fmt.Sprintf("Invalid URI %q: %s", e.URI, e.Reason)
+}
+
 // Mismatch is returned by api if expectation at body value is failed
 type NoMatch struct {
-	ID       string // unique ID of failed combinator
-	Protocol any    // protocol primitive caused failure
-	Diff     string // human readable difference between expected & actual values
-	Expect   any    // expected value
-	Actual   any    // actual value
+	ID       string            // unique ID of failed combinator
+	Protocol any               // protocol primitive caused failure
+	Diff     string            // human readable difference between expected & actual values
+	Expect   any               // expected value
+	Actual   any               // actual value
+	Tags     map[string]string // tags of failed I/O
 }
 
 func (e *NoMatch) Error() string { return e.Diff }
+
+// Unfulfilled is returned if promised value is consumed before it is fulfilled.
+type Unfulfilled struct{ Type string }
+
+func (e *Unfulfilled) Error() string {
+	return fmt.Sprintf("Unfulfilled promise of %s", e.Type)
+}
+
+// DecodeError is returned if payload cannot be decoded. It carries content
+// type, offset of offending byte (-1 if unknown) and truncated snapshot of
+// the raw payload.
+type DecodeError struct {
+	ContentType string
+	Offset      int64
+	Snapshot    []byte
+	Err         error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Offset >= 0 {
+		return fmt.Sprintf("Decode %s at %d: %v\n%s", e.ContentType, e.Offset, e.Err, e.Snapshot)
+	}
+	return fmt.Sprintf("Decode %s: %v\n%s", e.ContentType, e.Err, e.Snapshot)
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// Decodable is implemented by types that decode wire format of payload on
+// their own. The decoder is preferred over codecs built into the library.
+type Decodable interface {
+	DecodeBody(contentType string, r io.Reader) error
+}
@@ -13,8 +13,10 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"io"
 	net "net/http"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -26,13 +28,23 @@ import (
 	"github.com/fogfish/opts"
 )
 
-// Configure HTTP Stack to use AWS Sign V4
-var WithSignatureV4 = opts.FMap(optsSigner)
+// Configure HTTP Stack to use AWS Sign V4. The request is signed for
+// "execute-api" service unless another one is defined by options.
+//
+//	awsapi.WithSignatureV4(conf,
+//		awsapi.Service(awsapi.ServiceS3),
+//		awsapi.UnsignedPayload,
+//	)
+func WithSignatureV4(conf aws.Config, opt ...SignerOption) http.Option {
+	return opts.From(func(p *http.Protocol) error {
+		return optsSigner(p, conf, opt...)
+	})()
+}
 
 // Configure HTTP Stack to use AWS Sign V4 using assumed role
-func WithAssumedRole(conf aws.Config, role, externalID string) http.Option {
+func WithAssumedRole(conf aws.Config, role, externalID string, opt ...SignerOption) http.Option {
 	if role == "" && externalID == "" {
-		return WithSignatureV4(conf)
+		return WithSignatureV4(conf, opt...)
 	}
 
 	return opts.From(func(p *http.Protocol) error {
@@ -53,43 +65,103 @@ func WithAssumedRole(conf aws.Config, role, externalID string) http.Option {
 			return err
 		}
 
-		return optsSigner(p, assumed)
+		return optsSigner(p, assumed, opt...)
 	})()
 }
 
+// Payload signing modes
+type Payload int
+
+const (
+	// The payload is buffered and its SHA256 digest is signed (default)
+	PayloadSigned Payload = iota
+	// The payload is streamed as-is, the digest is not signed. S3 accepts
+	// unsigned payload over HTTPS only.
+	PayloadUnsigned
+	// The payload is streamed using aws-chunked encoding with CRC32 checksum
+	// in trailer (STREAMING-UNSIGNED-PAYLOAD-TRAILER). It requires known
+	// Content-Length of the request.
+	PayloadStreaming
+)
+
+// SignerOption configures AWS Sign V4
+type SignerOption func(*signer)
+
+// Service defines the signing name of AWS service (e.g. "s3", "es", "lambda")
+func Service(name string) SignerOption {
+	return func(s *signer) { s.service = name }
+}
+
+// Mode defines the payload signing mode
+func Mode(payload Payload) SignerOption {
+	return func(s *signer) { s.payload = payload }
+}
+
+// Shortcuts of payload signing modes
+var (
+	UnsignedPayload  = Mode(PayloadUnsigned)
+	StreamingPayload = Mode(PayloadStreaming)
+)
+
+// ExpiryWindow refreshes the session token ahead of its expiration.
+// The credentials are cached and refreshed by the signer when they are
+// expired or rejected by AWS as expired. The option is ignored if
+// credentials of the config are aws.CredentialsCache already.
+func ExpiryWindow(d time.Duration) SignerOption {
+	return func(s *signer) { s.expiryWindow = d }
+}
+
 type signer struct {
-	config aws.Config
-	signer *v4.Signer
-	socket http.Socket
+	config       aws.Config
+	signer       *v4.Signer
+	socket       http.Socket
+	service      string
+	payload      Payload
+	expiryWindow time.Duration
+	credentials  *aws.CredentialsCache
 }
 
-func optsSigner(p *http.Protocol, conf aws.Config) error {
-	p.Socket = &signer{
-		config: conf,
-		signer: v4.NewSigner(),
-		socket: p.Socket,
+func optsSigner(p *http.Protocol, conf aws.Config, opt ...SignerOption) error {
+	s := &signer{
+		config:  conf,
+		signer:  v4.NewSigner(),
+		socket:  p.Socket,
+		service: ServiceExecuteAPI,
 	}
+	for _, f := range opt {
+		f(s)
+	}
+
+	switch provider := conf.Credentials.(type) {
+	case nil:
+		return errors.New("aws credentials are not defined")
+	case *aws.CredentialsCache:
+		s.credentials = provider
+	default:
+		s.credentials = aws.NewCredentialsCache(provider, s.cacheOptions)
+	}
+
+	p.Socket = s
 	return nil
 }
 
+func (s *signer) cacheOptions(opts *aws.CredentialsCacheOptions) {
+	opts.ExpiryWindow = s.expiryWindow
+}
+
 func (s *signer) Do(req *net.Request) (*net.Response, error) {
-	credential, err := s.config.Credentials.Retrieve(req.Context())
+	credential, err := s.credentials.Retrieve(req.Context())
 	if err != nil {
 		return nil, err
 	}
 
-	hash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
-	if req.Body != nil {
-		buf := &bytes.Buffer{}
-		hasher := sha256.New()
-		stream := io.TeeReader(req.Body, hasher)
-		if _, err := io.Copy(buf, stream); err != nil {
-			return nil, err
-		}
-		hash = hex.EncodeToString(hasher.Sum(nil))
+	hash, err := s.digest(req)
+	if err != nil {
+		return nil, err
+	}
 
-		req.Body.Close()
-		req.Body = io.NopCloser(buf)
+	if s.service == ServiceS3 || s.payload != PayloadSigned {
+		req.Header.Set("X-Amz-Content-Sha256", hash)
 	}
 
 	err = s.signer.SignHTTP(
@@ -97,13 +169,59 @@ func (s *signer) Do(req *net.Request) (*net.Response, error) {
 		credential,
 		req,
 		hash,
-		"execute-api",
+		s.service,
 		s.config.Region,
 		time.Now(),
+		func(opts *v4.SignerOptions) {
+			// S3 expects the path to be escaped once
+			opts.DisableURIPathEscaping = s.service == ServiceS3
+		},
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.socket.Do(req)
+	resp, err := s.socket.Do(req)
+	if err == nil && isExpiredToken(resp) {
+		// Note: the request is not replayed, next one uses refreshed token
+		s.credentials.Invalidate()
+	}
+
+	return resp, err
+}
+
+func (s *signer) digest(req *net.Request) (string, error) {
+	switch s.payload {
+	case PayloadUnsigned:
+		return unsignedPayload, nil
+	case PayloadStreaming:
+		if err := chunked(req); err != nil {
+			return "", err
+		}
+		return streamingPayload, nil
+	}
+
+	if req.Body == nil || req.Body == net.NoBody {
+		return emptyPayload, nil
+	}
+
+	buf := &bytes.Buffer{}
+	hasher := sha256.New()
+	stream := io.TeeReader(req.Body, hasher)
+	if _, err := io.Copy(buf, stream); err != nil {
+		return "", err
+	}
+
+	req.Body.Close()
+	req.Body = io.NopCloser(buf)
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func isExpiredToken(resp *net.Response) bool {
+	if resp.StatusCode != net.StatusForbidden && resp.StatusCode != net.StatusBadRequest {
+		return false
+	}
+
+	return strings.HasPrefix(resp.Header.Get("X-Amzn-Errortype"), "ExpiredToken")
 }
@@ -0,0 +1,161 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package awsapi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/gurl/x/awsapi"
+)
+
+type socket struct {
+	req    *http.Request
+	body   []byte
+	header http.Header
+}
+
+func (s *socket) Do(req *http.Request) (*http.Response, error) {
+	s.req = req
+	if req.Body != nil {
+		s.body, _ = io.ReadAll(req.Body)
+	}
+
+	header := s.header
+	if header == nil {
+		header = http.Header{}
+	}
+
+	status := http.StatusOK
+	if header.Get("X-Amzn-Errortype") != "" {
+		status = http.StatusForbidden
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(nil)),
+		Request:    req,
+	}, nil
+}
+
+func put(sock *socket, opt ...awsapi.SignerOption) error {
+	stack := µ.New(
+		µ.WithClient(sock),
+		awsapi.WithSignatureV4(conf, opt...),
+	)
+
+	return stack.IO(context.Background(),
+		µ.PUT(
+			ø.URI("https://example.com/bucket/a b"),
+			ø.ContentType.Text,
+			ø.ContentLength.Set(5),
+			ø.Send(strings.NewReader("hello")),
+			ƒ.Status.OK,
+		),
+	)
+}
+
+func TestSignatureV4(t *testing.T) {
+	sock := &socket{}
+	if err := put(sock); err != nil {
+		t.Fatal(err)
+	}
+
+	auth := sock.req.Header.Get("Authorization")
+	if !strings.Contains(auth, "/eu-west-1/execute-api/aws4_request") ||
+		sock.req.Header.Get("X-Amz-Content-Sha256") != "" ||
+		string(sock.body) != "hello" {
+		t.Errorf("unexpected signature %s", auth)
+	}
+}
+
+func TestSignatureV4S3(t *testing.T) {
+	sock := &socket{}
+	if err := put(sock, awsapi.Service(awsapi.ServiceS3)); err != nil {
+		t.Fatal(err)
+	}
+
+	auth := sock.req.Header.Get("Authorization")
+	if !strings.Contains(auth, "/eu-west-1/s3/aws4_request") ||
+		sock.req.Header.Get("X-Amz-Content-Sha256") != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("unexpected signature %s", auth)
+	}
+}
+
+func TestSignatureV4Unsigned(t *testing.T) {
+	sock := &socket{}
+	if err := put(sock, awsapi.Service(awsapi.ServiceS3), awsapi.UnsignedPayload); err != nil {
+		t.Fatal(err)
+	}
+
+	if sock.req.Header.Get("X-Amz-Content-Sha256") != "UNSIGNED-PAYLOAD" ||
+		string(sock.body) != "hello" {
+		t.Errorf("unexpected request %v", sock.req.Header)
+	}
+}
+
+func TestSignatureV4Streaming(t *testing.T) {
+	sock := &socket{}
+	if err := put(sock, awsapi.Service(awsapi.ServiceS3), awsapi.StreamingPayload); err != nil {
+		t.Fatal(err)
+	}
+
+	sum := crc32.ChecksumIEEE([]byte("hello"))
+	expect := fmt.Sprintf("5\r\nhello\r\n0\r\nx-amz-checksum-crc32:%s\r\n\r\n", crc32b64(sum))
+
+	if sock.req.Header.Get("X-Amz-Content-Sha256") != "STREAMING-UNSIGNED-PAYLOAD-TRAILER" ||
+		sock.req.Header.Get("Content-Encoding") != "aws-chunked" ||
+		sock.req.Header.Get("X-Amz-Decoded-Content-Length") != "5" ||
+		sock.req.ContentLength != int64(len(expect)) ||
+		string(sock.body) != expect {
+		t.Errorf("unexpected request %q", sock.body)
+	}
+}
+
+func TestSignatureV4ExpiredToken(t *testing.T) {
+	calls := 0
+	conf := aws.Config{
+		Region: "eu-west-1",
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			calls++
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET", SessionToken: "TOKEN"}, nil
+		}),
+	}
+
+	sock := &socket{header: http.Header{"X-Amzn-Errortype": {"ExpiredTokenException"}}}
+	stack := µ.New(
+		µ.WithClient(sock),
+		awsapi.WithSignatureV4(conf),
+	)
+
+	for i := 0; i < 2; i++ {
+		stack.IO(context.Background(), µ.GET(ø.URI("https://example.com/"), ƒ.Status.OK))
+	}
+
+	if calls != 2 {
+		t.Errorf("credentials are not refreshed, %d calls", calls)
+	}
+}
+
+func crc32b64(sum uint32) string {
+	b := []byte{byte(sum >> 24), byte(sum >> 16), byte(sum >> 8), byte(sum)}
+	return base64.StdEncoding.EncodeToString(b)
+}
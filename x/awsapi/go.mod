@@ -13,6 +13,7 @@ require (
 
 require (
 	github.com/ajg/form v1.5.2-0.20200323032839-9aeb3cf462e1 // indirect
+	github.com/andybalholm/brotli v1.2.5 // indirect
 	github.com/aws/aws-sdk-go-v2/feature/ec2/imds v1.16.13 // indirect
 	github.com/aws/aws-sdk-go-v2/internal/configsources v1.3.17 // indirect
 	github.com/aws/aws-sdk-go-v2/internal/endpoints/v2 v2.6.17 // indirect
@@ -24,5 +25,12 @@ require (
 	github.com/aws/smithy-go v1.20.4 // indirect
 	github.com/fogfish/golem/hseq v1.2.0 // indirect
 	github.com/fogfish/golem/optics v0.13.1 // indirect
+	github.com/google/go-cmp v0.6.0 // indirect
 	golang.org/x/net v0.17.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+	golang.org/x/time v0.5.0 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
 )
+
+// the extension is built against the core module of this tree
+replace github.com/fogfish/gurl/v2 => ../../
@@ -0,0 +1,123 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package awsapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"io"
+	net "net/http"
+	"strconv"
+)
+
+//
+// The file implements aws-chunked encoding of payload with trailing checksum
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-streaming.html
+//
+
+const (
+	chunkSize       = 64 * 1024
+	checksumTrailer = "x-amz-checksum-crc32"
+)
+
+// chunked re-encodes the body of request using aws-chunked encoding
+func chunked(req *net.Request) error {
+	if req.Body == nil || req.Body == net.NoBody {
+		req.ContentLength = 0
+	}
+
+	if req.ContentLength < 0 || (req.ContentLength == 0 && req.Body != nil && req.Body != net.NoBody) {
+		return errors.New("aws-chunked payload requires Content-Length, use ø.ContentLength")
+	}
+
+	size := req.ContentLength
+	body := req.Body
+	if body == nil {
+		body = net.NoBody
+	}
+
+	req.Header.Add("Content-Encoding", "aws-chunked")
+	req.Header.Set("X-Amz-Decoded-Content-Length", strconv.FormatInt(size, 10))
+	req.Header.Set("X-Amz-Trailer", checksumTrailer)
+	req.ContentLength = chunkedLength(size)
+	req.Body = &chunkedReader{
+		source: body,
+		hash:   crc32.NewIEEE(),
+		chunk:  make([]byte, chunkSize),
+	}
+	req.GetBody = nil
+
+	return nil
+}
+
+// chunkedLength calculates length of encoded payload
+func chunkedLength(size int64) int64 {
+	length := int64(0)
+	for n := size; n > 0; n -= chunkSize {
+		chunk := min(n, chunkSize)
+		length += int64(len(strconv.FormatInt(chunk, 16))) + 2 + chunk + 2
+	}
+
+	// final chunk "0\r\n", trailer and terminating "\r\n"
+	trailer := len(checksumTrailer) + 1 + base64.StdEncoding.EncodedLen(crc32.Size) + 2
+	return length + 3 + int64(trailer) + 2
+}
+
+type chunkedReader struct {
+	source io.ReadCloser
+	hash   hash.Hash32
+	chunk  []byte
+	buf    bytes.Buffer
+	eof    bool
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	return r.buf.Read(p)
+}
+
+func (r *chunkedReader) next() error {
+	n, err := io.ReadFull(r.source, r.chunk)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	if n > 0 {
+		r.hash.Write(r.chunk[:n])
+		r.buf.WriteString(strconv.FormatInt(int64(n), 16))
+		r.buf.WriteString("\r\n")
+		r.buf.Write(r.chunk[:n])
+		r.buf.WriteString("\r\n")
+	}
+
+	if err != nil {
+		sum := binary.BigEndian.AppendUint32(nil, r.hash.Sum32())
+		r.buf.WriteString("0\r\n")
+		r.buf.WriteString(checksumTrailer + ":" + base64.StdEncoding.EncodeToString(sum) + "\r\n")
+		r.buf.WriteString("\r\n")
+		r.eof = true
+	}
+
+	return nil
+}
+
+func (r *chunkedReader) Close() error { return r.source.Close() }
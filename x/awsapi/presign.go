@@ -0,0 +1,118 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package awsapi
+
+import (
+	"context"
+	net "net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/fogfish/gurl/v2/http"
+)
+
+// Signing names of commonly used AWS services
+const (
+	ServiceExecuteAPI = "execute-api"
+	ServiceIoT        = "iotdevicegateway"
+	ServiceS3         = "s3"
+	ServiceOpenSearch = "es"
+	ServiceLambda     = "lambda"
+)
+
+const (
+	emptyPayload     = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	streamingPayload = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+)
+
+// Presign authorizes the request with SigV4 query string (presigned URL)
+// instead of Authorization header. The arrow must follow ø.URI.
+//
+//	http.GET(
+//		ø.URI("https://example.com/path"),
+//		awsapi.Presign(conf, awsapi.ServiceIoT, 5*time.Minute),
+//		ƒ.Status.OK,
+//	)
+func Presign(conf aws.Config, service string, expires time.Duration) http.Arrow {
+	return func(ctx *http.Context) error {
+		uri, header, err := presign(ctx.Context, conf, service, ctx.Request, expires)
+		if err != nil {
+			return err
+		}
+
+		ctx.Request.URL, err = url.Parse(uri)
+		if err != nil {
+			return err
+		}
+
+		for key, val := range header {
+			if key != "Host" {
+				ctx.Request.Header[key] = val
+			}
+		}
+
+		return nil
+	}
+}
+
+// PresignURL returns SigV4 presigned URL, use it with clients that are not
+// able to sign requests, e.g. WebSocket endpoints of AWS API Gateway or
+// AWS IoT Core.
+//
+//	uri, err := awsapi.PresignURL(ctx, conf, awsapi.ServiceExecuteAPI,
+//		"wss://xxx.execute-api.eu-west-1.amazonaws.com/live", time.Minute)
+func PresignURL(ctx context.Context, conf aws.Config, service, uri string, expires time.Duration) (string, error) {
+	req, err := net.NewRequestWithContext(ctx, net.MethodGet, uri, nil)
+	if err != nil {
+		return "", err
+	}
+
+	signed, _, err := presign(ctx, conf, service, req, expires)
+	return signed, err
+}
+
+func presign(ctx context.Context, conf aws.Config, service string, req *net.Request, expires time.Duration) (string, net.Header, error) {
+	credential, err := conf.Credentials.Retrieve(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	q := req.URL.Query()
+	q.Set("X-Amz-Expires", strconv.Itoa(int(expires/time.Second)))
+	req.URL.RawQuery = q.Encode()
+
+	// AWS IoT Core requires security token to be appended after signing
+	token := ""
+	if service == ServiceIoT {
+		token = credential.SessionToken
+		credential.SessionToken = ""
+	}
+
+	uri, header, err := v4.NewSigner().PresignHTTP(ctx,
+		credential,
+		req,
+		unsignedPayload,
+		service,
+		conf.Region,
+		time.Now(),
+	)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if token != "" {
+		uri += "&X-Amz-Security-Token=" + url.QueryEscape(token)
+	}
+
+	return uri, header, nil
+}
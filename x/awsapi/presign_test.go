@@ -0,0 +1,83 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package awsapi_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	µ "github.com/fogfish/gurl/v2/http"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/gurl/x/awsapi"
+)
+
+var conf = aws.Config{
+	Region:      "eu-west-1",
+	Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", "TOKEN"),
+}
+
+func TestPresignURL(t *testing.T) {
+	uri, err := awsapi.PresignURL(context.Background(), conf,
+		awsapi.ServiceExecuteAPI, "wss://example.com/live", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := u.Query()
+	if u.Scheme != "wss" ||
+		q.Get("X-Amz-Algorithm") != "AWS4-HMAC-SHA256" ||
+		q.Get("X-Amz-Expires") != "60" ||
+		q.Get("X-Amz-Security-Token") != "TOKEN" ||
+		q.Get("X-Amz-Signature") == "" ||
+		!strings.HasPrefix(q.Get("X-Amz-Credential"), "AKID/") {
+		t.Errorf("unexpected presigned url %s", uri)
+	}
+}
+
+func TestPresignIoT(t *testing.T) {
+	uri, err := awsapi.PresignURL(context.Background(), conf,
+		awsapi.ServiceIoT, "wss://example.com/mqtt", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(uri, "&X-Amz-Security-Token=TOKEN") {
+		t.Errorf("unexpected presigned url %s", uri)
+	}
+}
+
+func TestPresign(t *testing.T) {
+	cat := µ.New().WithContext(context.Background())
+	err := cat.IO(
+		µ.GET(
+			ø.URI("https://example.com/path"),
+			awsapi.Presign(conf, awsapi.ServiceExecuteAPI, time.Minute),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := cat.Request.URL.Query()
+	if cat.Request.Header.Get("Authorization") != "" ||
+		q.Get("X-Amz-Signature") == "" ||
+		cat.Request.URL.Path != "/path" {
+		t.Errorf("unexpected presigned request %s", cat.Request.URL)
+	}
+}
@@ -0,0 +1,224 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+// Package chaos is an extension to gurl library for fault injection. The
+// socket wrapper injects latency, failures, dropped connections, truncated
+// and slow bodies according to probability rules, so that resilience of
+// clients (e.g. retries, circuit breakers) is testable in CI.
+package chaos
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	net "net/http"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fogfish/gurl/v2/http"
+	"github.com/fogfish/opts"
+)
+
+// Fault is injected into the I/O instead of or on top of the actual call
+type Fault func(sock http.Socket, req *net.Request) (*net.Response, error)
+
+// Rule injects the fault with the probability into requests accepted by
+// the predicate.
+type Rule struct {
+	Probability float64
+	Accept      func(*net.Request) bool
+	Fault       Fault
+}
+
+// When restricts the rule to requests accepted by the predicate
+func (r Rule) When(f func(*net.Request) bool) Rule {
+	r.Accept = f
+	return r
+}
+
+// Latency delays requests with the probability
+func Latency(p float64, d time.Duration) Rule {
+	return Rule{
+		Probability: p,
+		Fault: func(sock http.Socket, req *net.Request) (*net.Response, error) {
+			select {
+			case <-time.After(d):
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+			return sock.Do(req)
+		},
+	}
+}
+
+// Status replies with the status code (e.g. 503) with the probability,
+// the actual request is not sent.
+func Status(p float64, code int) Rule {
+	return Rule{
+		Probability: p,
+		Fault: func(sock http.Socket, req *net.Request) (*net.Response, error) {
+			return &net.Response{
+				Status:     fmt.Sprintf("%d %s", code, net.StatusText(code)),
+				StatusCode: code,
+				Proto:      "HTTP/1.1",
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     net.Header{"Content-Type": {"text/plain"}},
+				Body:       io.NopCloser(bytes.NewReader([]byte(net.StatusText(code)))),
+				Request:    req,
+			}, nil
+		},
+	}
+}
+
+// Drop fails requests with connection reset with the probability,
+// the actual request is not sent.
+func Drop(p float64) Rule {
+	return Rule{
+		Probability: p,
+		Fault: func(sock http.Socket, req *net.Request) (*net.Response, error) {
+			return nil, fmt.Errorf("chaos: connection dropped: %w", syscall.ECONNRESET)
+		},
+	}
+}
+
+// Truncate cuts the response body after n bytes with the probability,
+// reading beyond fails with io.ErrUnexpectedEOF.
+func Truncate(p float64, n int64) Rule {
+	return Rule{
+		Probability: p,
+		Fault: func(sock http.Socket, req *net.Request) (*net.Response, error) {
+			resp, err := sock.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = &truncated{ReadCloser: resp.Body, n: n}
+			resp.ContentLength = -1
+			return resp, nil
+		},
+	}
+}
+
+// SlowRead delays each chunk of response body with the probability
+func SlowRead(p float64, chunk int, d time.Duration) Rule {
+	return Rule{
+		Probability: p,
+		Fault: func(sock http.Socket, req *net.Request) (*net.Response, error) {
+			resp, err := sock.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = &slow{ReadCloser: resp.Body, chunk: chunk, delay: d, req: req}
+			return resp, nil
+		},
+	}
+}
+
+// Chaos is a set of rules injecting faults into I/O. The first rule
+// triggered by the dice is applied to the request.
+type Chaos struct {
+	lock  sync.Mutex
+	rules []Rule
+
+	// Rand is the source of randomness, set it for reproducible runs
+	Rand *rand.Rand
+}
+
+// New creates chaos of rules
+func New(rules ...Rule) *Chaos {
+	return &Chaos{
+		rules: rules,
+		Rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (c *Chaos) fault(req *net.Request) Fault {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, rule := range c.rules {
+		if rule.Accept != nil && !rule.Accept(req) {
+			continue
+		}
+		if c.Rand.Float64() < rule.Probability {
+			return rule.Fault
+		}
+	}
+	return nil
+}
+
+// Configure HTTP Stack to inject faults using the chaos rules.
+//
+//	stack := http.New(
+//		chaos.WithChaos(
+//			chaos.New(
+//				chaos.Status(0.1, 503),
+//				chaos.Drop(0.05),
+//			),
+//		),
+//	)
+var WithChaos = opts.FMap(optsChaos)
+
+func optsChaos(p *http.Protocol, c *Chaos) error {
+	return opts.Apply(p, []http.Option{
+		http.WithMiddleware(func(next http.Socket) http.Socket {
+			return &socket{chaos: c, socket: next}
+		}),
+	})
+}
+
+type socket struct {
+	chaos  *Chaos
+	socket http.Socket
+}
+
+func (s *socket) Do(req *net.Request) (*net.Response, error) {
+	if f := s.chaos.fault(req); f != nil {
+		return f(s.socket, req)
+	}
+	return s.socket.Do(req)
+}
+
+type truncated struct {
+	io.ReadCloser
+	n int64
+}
+
+func (t *truncated) Read(p []byte) (int, error) {
+	if t.n <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if int64(len(p)) > t.n {
+		p = p[:t.n]
+	}
+	n, err := t.ReadCloser.Read(p)
+	t.n -= int64(n)
+	return n, err
+}
+
+type slow struct {
+	io.ReadCloser
+	chunk int
+	delay time.Duration
+	req   *net.Request
+}
+
+func (s *slow) Read(p []byte) (int, error) {
+	select {
+	case <-time.After(s.delay):
+	case <-s.req.Context().Done():
+		return 0, s.req.Context().Err()
+	}
+
+	if s.chunk > 0 && len(p) > s.chunk {
+		p = p[:s.chunk]
+	}
+	return s.ReadCloser.Read(p)
+}
@@ -0,0 +1,145 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package chaos_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/gurl/x/chaos"
+	"github.com/fogfish/it/v2"
+)
+
+func TestChaos(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("0123456789"))
+		}),
+	)
+	defer ts.Close()
+
+	get := func(buf *bytes.Buffer) µ.Arrow {
+		return µ.GET(
+			ø.URI(ts.URL),
+			ƒ.Status.OK,
+			ƒ.Bytes(buf),
+		)
+	}
+
+	t.Run("None", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := µ.New(chaos.WithChaos(chaos.New())).IO(context.Background(), get(buf))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(buf.String(), "0123456789"),
+		)
+	})
+
+	t.Run("Latency", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		t0 := time.Now()
+		err := µ.New(
+			chaos.WithChaos(chaos.New(chaos.Latency(1.0, 50*time.Millisecond))),
+		).IO(context.Background(), get(buf))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(time.Since(t0) >= 50*time.Millisecond),
+		)
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		n := calls
+		err := µ.New(
+			chaos.WithChaos(chaos.New(chaos.Status(1.0, http.StatusServiceUnavailable))),
+		).IO(context.Background(), µ.GET(ø.URI(ts.URL), ƒ.Status.ServiceUnavailable))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(calls, n),
+		)
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		err := µ.New(
+			chaos.WithChaos(chaos.New(chaos.Drop(1.0))),
+		).IO(context.Background(), get(&bytes.Buffer{}))
+		it.Then(t).Should(
+			it.True(errors.Is(err, syscall.ECONNRESET)),
+		)
+	})
+
+	t.Run("Truncate", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := µ.New(
+			chaos.WithChaos(chaos.New(chaos.Truncate(1.0, 4))),
+		).IO(context.Background(), get(buf))
+		it.Then(t).Should(
+			it.True(errors.Is(err, io.ErrUnexpectedEOF)),
+			it.Equal(buf.String(), "0123"),
+		)
+	})
+
+	t.Run("SlowRead", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		t0 := time.Now()
+		err := µ.New(
+			chaos.WithChaos(chaos.New(chaos.SlowRead(1.0, 5, 10*time.Millisecond))),
+		).IO(context.Background(), get(buf))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(buf.String(), "0123456789"),
+			it.True(time.Since(t0) >= 20*time.Millisecond),
+		)
+	})
+
+	t.Run("When", func(t *testing.T) {
+		rule := chaos.Drop(1.0).When(func(r *http.Request) bool {
+			return strings.HasPrefix(r.URL.Path, "/fail")
+		})
+		stack := µ.New(chaos.WithChaos(chaos.New(rule)))
+
+		err := stack.IO(context.Background(), get(&bytes.Buffer{}))
+		it.Then(t).Should(it.Nil(err))
+
+		err = stack.IO(context.Background(),
+			µ.GET(ø.URI("%s/fail", ø.Authority(ts.URL)), ƒ.Status.OK),
+		)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+
+	t.Run("Probability", func(t *testing.T) {
+		c := chaos.New(chaos.Status(0.5, http.StatusInternalServerError))
+		c.Rand = rand.New(rand.NewSource(42))
+		stack := µ.New(chaos.WithChaos(c))
+
+		failed := 0
+		for i := 0; i < 100; i++ {
+			if err := stack.IO(context.Background(), get(&bytes.Buffer{})); err != nil {
+				failed++
+			}
+		}
+		it.Then(t).Should(
+			it.True(failed > 30 && failed < 70),
+		)
+	})
+}
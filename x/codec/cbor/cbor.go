@@ -0,0 +1,32 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+// Package cbor registers CBOR (RFC 8949) codec for media type application/cbor
+package cbor
+
+import (
+	"io"
+
+	"github.com/fogfish/gurl/v2/http"
+	"github.com/fxamacker/cbor/v2"
+)
+
+func init() {
+	http.RegisterCodec("application/cbor", Codec{})
+}
+
+// Codec implements CBOR (RFC 8949) codec
+type Codec struct{}
+
+func (Codec) Encode(w io.Writer, data any) error {
+	return cbor.NewEncoder(w).Encode(data)
+}
+
+func (Codec) Decode(r io.Reader, data any) error {
+	return cbor.NewDecoder(r).Decode(data)
+}
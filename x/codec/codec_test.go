@@ -0,0 +1,63 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package codec_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	_ "github.com/fogfish/gurl/x/codec/cbor"
+	_ "github.com/fogfish/gurl/x/codec/msgpack"
+	"github.com/fogfish/it/v2"
+)
+
+func TestCodec(t *testing.T) {
+	type Site struct {
+		Site string `msgpack:"site" cbor:"site"`
+		Port int    `msgpack:"port" cbor:"port"`
+	}
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+			io.Copy(w, r.Body)
+		}),
+	)
+	defer ts.Close()
+
+	for content, arrow := range map[string]µ.Arrow{
+		"application/msgpack": ø.ContentType.MsgPack,
+		"application/cbor":    ø.ContentType.CBOR,
+	} {
+		t.Run(content, func(t *testing.T) {
+			var site Site
+			err := µ.New().IO(context.Background(),
+				µ.POST(
+					ø.URI(ts.URL),
+					arrow,
+					ø.Send(Site{Site: "example.com", Port: 8080}),
+					ƒ.Status.OK,
+					ƒ.ContentType.Is(content),
+					ƒ.Body(&site),
+				),
+			)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(site.Site, "example.com"),
+				it.Equal(site.Port, 8080),
+			)
+		})
+	}
+}
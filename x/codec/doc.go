@@ -0,0 +1,17 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+/*
+Package codec is an extension to gurl library for binary payloads. Its
+sub-packages register codecs with http.RegisterCodec once imported, so
+that ø.Send and ƒ.Body encode and decode the content type.
+
+	import _ "github.com/fogfish/gurl/x/codec/msgpack"
+	import _ "github.com/fogfish/gurl/x/codec/cbor"
+*/
+package codec
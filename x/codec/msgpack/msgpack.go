@@ -0,0 +1,35 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+// Package msgpack registers MessagePack codec for media types
+// application/msgpack, application/x-msgpack and application/vnd.msgpack
+package msgpack
+
+import (
+	"io"
+
+	"github.com/fogfish/gurl/v2/http"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func init() {
+	http.RegisterCodec("application/msgpack", Codec{})
+	http.RegisterCodec("application/x-msgpack", Codec{})
+	http.RegisterCodec("application/vnd.msgpack", Codec{})
+}
+
+// Codec implements MessagePack codec
+type Codec struct{}
+
+func (Codec) Encode(w io.Writer, data any) error {
+	return msgpack.NewEncoder(w).Encode(data)
+}
+
+func (Codec) Decode(r io.Reader, data any) error {
+	return msgpack.NewDecoder(r).Decode(data)
+}
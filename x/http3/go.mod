@@ -0,0 +1,35 @@
+module github.com/fogfish/gurl/x/http3
+
+go 1.23
+
+require (
+	github.com/fogfish/gurl/v2 v2.10.0
+	github.com/fogfish/it/v2 v2.0.2
+	github.com/fogfish/opts v0.0.2
+	github.com/quic-go/quic-go v0.48.2
+)
+
+require (
+	github.com/ajg/form v1.5.2-0.20200323032839-9aeb3cf462e1 // indirect
+	github.com/andybalholm/brotli v1.2.5 // indirect
+	github.com/fogfish/golem/hseq v1.2.0 // indirect
+	github.com/fogfish/golem/optics v0.13.1 // indirect
+	github.com/go-task/slim-sprig v0.0.0-20230315185526-52ccab3ef572 // indirect
+	github.com/google/go-cmp v0.6.0 // indirect
+	github.com/google/pprof v0.0.0-20210407192527-94a9f03dee38 // indirect
+	github.com/onsi/ginkgo/v2 v2.9.5 // indirect
+	github.com/quic-go/qpack v0.5.1 // indirect
+	go.uber.org/mock v0.4.0 // indirect
+	golang.org/x/crypto v0.26.0 // indirect
+	golang.org/x/exp v0.0.0-20240506185415-9bf2ced13842 // indirect
+	golang.org/x/mod v0.17.0 // indirect
+	golang.org/x/net v0.28.0 // indirect
+	golang.org/x/sys v0.23.0 // indirect
+	golang.org/x/text v0.17.0 // indirect
+	golang.org/x/time v0.5.0 // indirect
+	golang.org/x/tools v0.21.1-0.20240508182429-e35e4ccd0d2d // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+)
+
+// the extension is built against the core module of this tree
+replace github.com/fogfish/gurl/v2 => ../../
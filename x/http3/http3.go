@@ -0,0 +1,52 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+// Package http3 is an extension to gurl library for HTTP/3 I/O over QUIC
+// using github.com/quic-go/quic-go.
+package http3
+
+import (
+	"crypto/tls"
+	"fmt"
+	net "net/http"
+
+	"github.com/fogfish/gurl/v2/http"
+	"github.com/fogfish/opts"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// Configure HTTP Stack to use HTTP/3 transport. TLS configuration of existing
+// transport is preserved, therefore options configuring TLS must precede it.
+//
+//	http.New(
+//		http.WithInsecureTLS(),
+//		http3.WithHTTP3(),
+//	)
+var WithHTTP3 = opts.From(optsHTTP3)
+
+func optsHTTP3(p *http.Protocol) error {
+	cli, ok := p.Socket.(*net.Client)
+	if !ok {
+		return nil
+	}
+
+	var conf *tls.Config
+	switch t := cli.Transport.(type) {
+	case *net.Transport:
+		if t.TLSClientConfig != nil {
+			conf = t.TLSClientConfig.Clone()
+		}
+	case *http3.Transport:
+		return nil
+	default:
+		return fmt.Errorf("unsupported transport type %T", t)
+	}
+
+	cli.Transport = &http3.Transport{TLSClientConfig: conf}
+	return nil
+}
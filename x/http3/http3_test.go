@@ -0,0 +1,61 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package http3_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/gurl/x/http3"
+	"github.com/fogfish/it/v2"
+	quic "github.com/quic-go/quic-go/http3"
+)
+
+func TestHTTP3(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// httptest is used to obtain self-signed certificate
+	ts := httptest.NewTLSServer(handler)
+	defer ts.Close()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	it.Then(t).Should(it.Nil(err))
+	defer conn.Close()
+
+	srv := &quic.Server{
+		Handler:   handler,
+		TLSConfig: quic.ConfigureTLSConfig(ts.TLS.Clone()),
+	}
+	go srv.Serve(conn)
+	defer srv.Close()
+
+	var proto string
+	err = µ.New(µ.WithInsecureTLS(), http3.WithHTTP3()).IO(context.Background(),
+		µ.GET(
+			ø.URI("https://%s", ø.Authority(conn.LocalAddr().String())),
+			ƒ.Status.OK,
+			func(ctx *µ.Context) error {
+				proto = ctx.Response.Proto
+				return nil
+			},
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(proto, "HTTP/3.0"),
+	)
+}
@@ -0,0 +1,110 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+// Package jsonschema is an extension to gurl library for validating
+// responses against JSON Schema documents and requests against OpenAPI
+// operations.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Schema validates JSON payload of the response against JSON Schema document.
+// The schema is compiled once, when the arrow is defined. The arrow fails
+// with gurl.NoMatch listing all violations of the schema.
+//
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		ƒ.Status.OK,
+//		jsonschema.Schema(schema),
+//	)
+func Schema(schema []byte) http.Arrow {
+	compiled, failure := compile(schema)
+
+	return func(cat *http.Context) error {
+		if failure != nil {
+			return failure
+		}
+
+		var doc any
+		decoder := json.NewDecoder(cat.Response.Body)
+		decoder.UseNumber()
+		err := decoder.Decode(&doc)
+		cat.Response.Body.Close()
+		cat.Response = nil
+		if err != nil {
+			return err
+		}
+
+		err = compiled.Validate(doc)
+		if err == nil {
+			return nil
+		}
+
+		var verr *jsonschema.ValidationError
+		if !errors.As(err, &verr) {
+			return err
+		}
+
+		return &gurl.NoMatch{
+			ID:       "http.Schema",
+			Diff:     violationsOf(verr),
+			Protocol: "body",
+			Expect:   string(schema),
+			Actual:   doc,
+		}
+	}
+}
+
+func compile(schema []byte) (*jsonschema.Schema, error) {
+	const url = "schema.json"
+
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource(url, bytes.NewReader(schema)); err != nil {
+		return nil, err
+	}
+
+	return c.Compile(url)
+}
+
+// violationsOf formats leaf errors of validation, one per line
+//
+//	- /site: expected string, but got number
+func violationsOf(verr *jsonschema.ValidationError) string {
+	seq := []string{}
+
+	var walk func(*jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			path := e.InstanceLocation
+			if path == "" {
+				path = "/"
+			}
+			seq = append(seq, fmt.Sprintf("- %s: %s", path, e.Message))
+			return
+		}
+
+		for _, c := range e.Causes {
+			walk(c)
+		}
+	}
+	walk(verr)
+
+	sort.Strings(seq)
+	return strings.Join(seq, "\n")
+}
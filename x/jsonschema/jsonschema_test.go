@@ -0,0 +1,81 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package jsonschema_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/gurl/x/jsonschema"
+	"github.com/fogfish/it/v2"
+)
+
+const schema = `{
+	"type": "object",
+	"required": ["site", "port"],
+	"properties": {
+		"site": {"type": "string"},
+		"port": {"type": "integer", "minimum": 1}
+	}
+}`
+
+func TestSchema(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/valid":
+				w.Write([]byte(`{"site": "example.com", "port": 80, "extra": true}`))
+			default:
+				w.Write([]byte(`{"site": 1, "port": 0}`))
+			}
+		}),
+	)
+	defer ts.Close()
+
+	req := func(path string, schema string) error {
+		return µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI("%s/%s", ø.Authority(ts.URL), ø.Path(path)),
+				ƒ.Status.OK,
+				jsonschema.Schema([]byte(schema)),
+			),
+		)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		err := req("valid", schema)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		err := req("invalid", schema)
+
+		var e *gurl.NoMatch
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Equal(e.ID, "http.Schema"),
+			it.True(strings.Contains(e.Diff, "- /port:")),
+			it.True(strings.Contains(e.Diff, "- /site:")),
+		)
+	})
+
+	t.Run("BadSchema", func(t *testing.T) {
+		err := req("valid", `{"type": 1}`)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+}
@@ -0,0 +1,487 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	net "net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+//
+// The file implements validation of outgoing requests against OpenAPI
+// operation, mirroring validation of responses against JSON Schema.
+//
+
+// Operation validates the request built by ø arrows against the OpenAPI
+// operation: method, path, query and header parameters and the body schema.
+// The specification (JSON or YAML) is compiled once, when the arrow is
+// defined. The arrow fails with gurl.NoMatch listing all violations before
+// the request is sent, it must follow ø arrows.
+//
+//	http.POST(
+//		ø.URI("https://example.com/users/%s", id),
+//		ø.ContentType.JSON,
+//		ø.Send(user),
+//		jsonschema.Operation(spec, "updateUser"),
+//		ƒ.Status.OK,
+//	)
+func Operation(spec []byte, operationID string) http.Arrow {
+	op, failure := compileOperation(spec, operationID)
+
+	return func(cat *http.Context) error {
+		if failure != nil {
+			return failure
+		}
+
+		seq, err := op.validate(cat.Request)
+		if err != nil {
+			return err
+		}
+
+		if len(seq) == 0 {
+			return nil
+		}
+
+		sort.Strings(seq)
+		return &gurl.NoMatch{
+			ID:       "http.Operation",
+			Diff:     strings.Join(seq, "\n"),
+			Protocol: operationID,
+			Expect:   op.method + " " + op.path,
+			Actual:   cat.Request.Method + " " + cat.Request.URL.Path,
+		}
+	}
+}
+
+type operation struct {
+	method string
+	path   string
+	params []parameter
+	body   *requestBody
+}
+
+type parameter struct {
+	name     string
+	in       string
+	required bool
+	schema   *jsonschema.Schema
+}
+
+type requestBody struct {
+	required bool
+	content  map[string]*jsonschema.Schema
+}
+
+// specOf decodes OpenAPI specification either from JSON or YAML
+func specOf(spec []byte) (map[string]any, []byte, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(spec, &doc); err == nil {
+		return doc, spec, nil
+	}
+
+	if err := yaml.Unmarshal(spec, &doc); err != nil {
+		return nil, nil, err
+	}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return doc, raw, nil
+}
+
+func compileOperation(spec []byte, operationID string) (*operation, error) {
+	const url = "openapi.json"
+
+	doc, raw, err := specOf(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	c := jsonschema.NewCompiler()
+	c.Draft = jsonschema.Draft4
+	if version, _ := doc["openapi"].(string); strings.HasPrefix(version, "3.1") {
+		c.Draft = jsonschema.Draft2020
+	}
+	if err := c.AddResource(url, bytes.NewReader(raw)); err != nil {
+		return nil, err
+	}
+
+	compile := func(ptr []string) (*jsonschema.Schema, error) {
+		return c.Compile(url + "#" + pointerOf(ptr))
+	}
+
+	paths, _ := doc["paths"].(map[string]any)
+	for path, item := range paths {
+		methods, _ := item.(map[string]any)
+		for method, spec := range methods {
+			node, _ := spec.(map[string]any)
+			if id, _ := node["operationId"].(string); id != operationID {
+				continue
+			}
+
+			op := &operation{method: strings.ToUpper(method), path: path}
+
+			// Note: operation parameters override path-level ones of same name
+			shared, _ := methods["parameters"].([]any)
+			params, _ := node["parameters"].([]any)
+			defined := map[string]int{}
+			for i, p := range shared {
+				if err := op.compileParameter(doc, []string{"paths", path, "parameters", strconv.Itoa(i)}, p, defined, compile); err != nil {
+					return nil, err
+				}
+			}
+			for i, p := range params {
+				if err := op.compileParameter(doc, []string{"paths", path, method, "parameters", strconv.Itoa(i)}, p, defined, compile); err != nil {
+					return nil, err
+				}
+			}
+
+			if body, has := node["requestBody"]; has {
+				if err := op.compileBody(doc, []string{"paths", path, method, "requestBody"}, body, compile); err != nil {
+					return nil, err
+				}
+			}
+
+			return op, nil
+		}
+	}
+
+	return nil, fmt.Errorf("openapi operation %s is not defined", operationID)
+}
+
+func (op *operation) compileParameter(
+	doc map[string]any,
+	ptr []string,
+	node any,
+	defined map[string]int,
+	compile func([]string) (*jsonschema.Schema, error),
+) error {
+	spec, ptr, err := deref(doc, ptr, node)
+	if err != nil {
+		return err
+	}
+
+	p := parameter{}
+	p.name, _ = spec["name"].(string)
+	p.in, _ = spec["in"].(string)
+	p.required, _ = spec["required"].(bool)
+	if p.in == "header" {
+		p.name = net.CanonicalHeaderKey(p.name)
+	}
+
+	if _, has := spec["schema"]; has {
+		p.schema, err = compile(append(ptr, "schema"))
+		if err != nil {
+			return err
+		}
+	}
+
+	key := p.in + ":" + p.name
+	if i, has := defined[key]; has {
+		op.params[i] = p
+		return nil
+	}
+
+	defined[key] = len(op.params)
+	op.params = append(op.params, p)
+	return nil
+}
+
+func (op *operation) compileBody(
+	doc map[string]any,
+	ptr []string,
+	node any,
+	compile func([]string) (*jsonschema.Schema, error),
+) error {
+	spec, ptr, err := deref(doc, ptr, node)
+	if err != nil {
+		return err
+	}
+
+	op.body = &requestBody{content: map[string]*jsonschema.Schema{}}
+	op.body.required, _ = spec["required"].(bool)
+
+	content, _ := spec["content"].(map[string]any)
+	for media, val := range content {
+		var schema *jsonschema.Schema
+		if node, _ := val.(map[string]any); node["schema"] != nil {
+			schema, err = compile(append(ptr, "content", media, "schema"))
+			if err != nil {
+				return err
+			}
+		}
+		op.body.content[media] = schema
+	}
+
+	return nil
+}
+
+// deref resolves local $ref of OpenAPI object (e.g. #/components/parameters/id)
+func deref(doc map[string]any, ptr []string, node any) (map[string]any, []string, error) {
+	for i := 0; i < 16; i++ {
+		spec, _ := node.(map[string]any)
+		ref, has := spec["$ref"].(string)
+		if !has {
+			return spec, ptr, nil
+		}
+
+		if !strings.HasPrefix(ref, "#/") {
+			return nil, nil, fmt.Errorf("openapi $ref %s is not supported", ref)
+		}
+
+		ptr = []string{}
+		node = any(doc)
+		for _, token := range strings.Split(ref[2:], "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			ptr = append(ptr, token)
+			obj, _ := node.(map[string]any)
+			node = obj[token]
+		}
+
+		if node == nil {
+			return nil, nil, fmt.Errorf("openapi $ref %s is not defined", ref)
+		}
+	}
+
+	return nil, nil, errors.New("openapi $ref is too deep")
+}
+
+// pointerOf builds JSON pointer, tokens are URL escaped as fragment of URL
+func pointerOf(ptr []string) string {
+	seq := make([]string, len(ptr))
+	for i, token := range ptr {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+		seq[i] = url.PathEscape(token)
+	}
+	return "/" + strings.Join(seq, "/")
+}
+
+func (op *operation) validate(req *net.Request) ([]string, error) {
+	seq := []string{}
+
+	if req.Method != op.method {
+		seq = append(seq, fmt.Sprintf("- method: expected %s, but got %s", op.method, req.Method))
+	}
+
+	path, ok := matchPath(op.path, req.URL.EscapedPath())
+	if !ok {
+		seq = append(seq, fmt.Sprintf("- path: expected %s, but got %s", op.path, req.URL.Path))
+	}
+
+	query := req.URL.Query()
+	for _, p := range op.params {
+		var vals []string
+		var has bool
+		switch p.in {
+		case "path":
+			if !ok {
+				continue
+			}
+			var val string
+			val, has = path[p.name]
+			vals = []string{val}
+		case "query":
+			vals, has = query[p.name]
+		case "header":
+			vals, has = req.Header[p.name]
+		default:
+			continue
+		}
+
+		if !has {
+			if p.required {
+				seq = append(seq, fmt.Sprintf("- %s %s: required parameter is missing", p.in, p.name))
+			}
+			continue
+		}
+
+		if p.schema != nil {
+			if err := validateParam(p.schema, vals); err != nil {
+				seq = append(seq, prefixed(p.in+" "+p.name, err)...)
+			}
+		}
+	}
+
+	if op.body != nil {
+		violations, err := op.body.validate(req)
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, violations...)
+	}
+
+	return seq, nil
+}
+
+// matchPath matches path template against tail of the request path,
+// the base path of servers is not known to the operation.
+func matchPath(template, path string) (map[string]string, bool) {
+	pattern := strings.Split(strings.Trim(template, "/"), "/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < len(pattern) {
+		return nil, false
+	}
+	segments = segments[len(segments)-len(pattern):]
+
+	params := map[string]string{}
+	for i, seg := range pattern {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			val, err := url.PathUnescape(segments[i])
+			if err != nil {
+				return nil, false
+			}
+			params[seg[1:len(seg)-1]] = val
+			continue
+		}
+
+		if seg != segments[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+// validateParam validates textual value of parameter, the value is coerced
+// to the type of schema: number, boolean or array of them.
+func validateParam(schema *jsonschema.Schema, vals []string) error {
+	if len(vals) == 1 {
+		if schema.Validate(vals[0]) == nil {
+			return nil
+		}
+		vals = strings.Split(vals[0], ",")
+	}
+
+	array, coerced := make([]any, len(vals)), make([]any, len(vals))
+	for i, val := range vals {
+		array[i], coerced[i] = val, coerce(val)
+	}
+
+	if schema.Validate(array) == nil {
+		return nil
+	}
+
+	// Note: violations of coerced values are the most accurate ones
+	if len(coerced) == 1 {
+		if err := schema.Validate(coerced[0]); err == nil || schema.Validate(coerced) != nil {
+			return err
+		}
+	}
+
+	return schema.Validate(coerced)
+}
+
+func coerce(val string) any {
+	switch val {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+
+	if _, err := strconv.ParseFloat(val, 64); err == nil {
+		return json.Number(val)
+	}
+
+	return val
+}
+
+func (body *requestBody) validate(req *net.Request) ([]string, error) {
+	if req.Body == nil || req.Body == net.NoBody {
+		if body.required {
+			return []string{"- body: required payload is missing"}, nil
+		}
+		return nil, nil
+	}
+
+	media, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		media = req.Header.Get("Content-Type")
+	}
+
+	schema, has := body.schemaOf(media)
+	if !has {
+		return []string{fmt.Sprintf("- body: content type %s is not defined", media)}, nil
+	}
+
+	if schema == nil || !strings.Contains(media, "json") {
+		return nil, nil
+	}
+
+	pkt, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(pkt))
+
+	var doc any
+	decoder := json.NewDecoder(bytes.NewReader(pkt))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return []string{fmt.Sprintf("- body: %v", err)}, nil
+	}
+
+	if err := schema.Validate(doc); err != nil {
+		return prefixed("body", err), nil
+	}
+
+	return nil, nil
+}
+
+// schemaOf returns schema of media type, the wildcards (e.g. application/*)
+// are supported as defined by OpenAPI.
+func (body *requestBody) schemaOf(media string) (*jsonschema.Schema, bool) {
+	if schema, has := body.content[media]; has {
+		return schema, true
+	}
+
+	major, _, _ := strings.Cut(media, "/")
+	if schema, has := body.content[major+"/*"]; has {
+		return schema, true
+	}
+
+	schema, has := body.content["*/*"]
+	return schema, has
+}
+
+func prefixed(prefix string, err error) []string {
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return []string{fmt.Sprintf("- %s: %v", prefix, err)}
+	}
+
+	seq := strings.Split(violationsOf(verr), "\n")
+	for i, line := range seq {
+		path := strings.TrimPrefix(line, "- ")
+		if strings.HasPrefix(path, "/:") {
+			path = path[1:]
+		}
+		seq[i] = "- " + prefix + path
+	}
+	return seq
+}
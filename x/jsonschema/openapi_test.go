@@ -0,0 +1,131 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package jsonschema_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/gurl/x/jsonschema"
+	"github.com/fogfish/it/v2"
+)
+
+const spec = `
+openapi: 3.0.3
+info:
+  title: users
+  version: 1.0.0
+paths:
+  /users/{id}:
+    parameters:
+      - $ref: "#/components/parameters/id"
+    put:
+      operationId: updateUser
+      parameters:
+        - name: dry-run
+          in: query
+          schema:
+            type: boolean
+        - name: X-Request-Id
+          in: header
+          required: true
+          schema:
+            type: string
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/User"
+components:
+  parameters:
+    id:
+      name: id
+      in: path
+      required: true
+      schema:
+        type: integer
+        minimum: 1
+  schemas:
+    User:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+`
+
+type User struct {
+	Name any `json:"name,omitempty"`
+}
+
+func TestOperation(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer ts.Close()
+
+	req := func(id, dryRun string, user User, header bool) error {
+		requestID := ø.Header("X-Request-Id", "1")
+		if !header {
+			requestID = func(*µ.Context) error { return nil }
+		}
+
+		return µ.New().IO(context.Background(),
+			µ.PUT(
+				ø.URI("%s/api/users/%s", ø.Authority(ts.URL), id),
+				ø.Param("dry-run", dryRun),
+				ø.ContentType.JSON,
+				requestID,
+				ø.Send(user),
+				jsonschema.Operation([]byte(spec), "updateUser"),
+				ƒ.Status.OK,
+			),
+		)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		err := req("10", "true", User{Name: "joe"}, true)
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		err := req("0", "yes", User{Name: 1}, false)
+
+		var e *gurl.NoMatch
+		it.Then(t).Should(
+			it.True(errors.As(err, &e)),
+			it.Equal(e.ID, "http.Operation"),
+			it.True(strings.Contains(e.Diff, "- path id:")),
+			it.True(strings.Contains(e.Diff, "- query dry-run:")),
+			it.True(strings.Contains(e.Diff, "- header X-Request-Id: required parameter is missing")),
+			it.True(strings.Contains(e.Diff, "- body/name:")),
+		)
+	})
+
+	t.Run("UnknownOperation", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			µ.GET(
+				ø.URI(ts.URL),
+				jsonschema.Operation([]byte(spec), "deleteUser"),
+			),
+		)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+}
@@ -0,0 +1,69 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+// Package oauth2 is an extension to gurl library for authorizing HTTP I/O
+// with OAuth2 Bearer tokens obtained from golang.org/x/oauth2.TokenSource.
+package oauth2
+
+import (
+	net "net/http"
+
+	"github.com/fogfish/gurl/v2/http"
+	"github.com/fogfish/opts"
+	"golang.org/x/oauth2"
+)
+
+// Configure HTTP Stack to authorize every request with Bearer token.
+// The token is cached and refreshed automatically when it expires.
+var WithTokenSource = opts.FMap(optsTokenSource)
+
+type bearer struct {
+	source oauth2.TokenSource
+	socket http.Socket
+}
+
+func optsTokenSource(p *http.Protocol, source oauth2.TokenSource) error {
+	source = oauth2.ReuseTokenSource(nil, source)
+
+	return opts.Apply(p, []http.Option{
+		http.WithMiddleware(func(next http.Socket) http.Socket {
+			return &bearer{source: source, socket: next}
+		}),
+	})
+}
+
+func (b *bearer) Do(req *net.Request) (*net.Response, error) {
+	token, err := b.source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	token.SetAuthHeader(req)
+
+	return b.socket.Do(req)
+}
+
+// AuthBearer authorizes the request with Bearer token obtained from the source.
+// Use oauth2.ReuseTokenSource to cache the token across requests.
+//
+//	http.GET(
+//		ø.URI("https://example.com"),
+//		oauth2.AuthBearer(source),
+//		ƒ.Status.OK,
+//	)
+func AuthBearer(source oauth2.TokenSource) http.Arrow {
+	return func(ctx *http.Context) error {
+		token, err := source.Token()
+		if err != nil {
+			return err
+		}
+
+		token.SetAuthHeader(ctx.Request)
+		return nil
+	}
+}
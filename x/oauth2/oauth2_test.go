@@ -0,0 +1,82 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package oauth2_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/gurl/x/oauth2"
+	"github.com/fogfish/it/v2"
+	xoauth2 "golang.org/x/oauth2"
+)
+
+type tokens struct{ seq int }
+
+func (t *tokens) Token() (*xoauth2.Token, error) {
+	t.seq++
+	return &xoauth2.Token{
+		AccessToken: "token",
+		TokenType:   "Bearer",
+		Expiry:      time.Now().Add(time.Hour),
+	}, nil
+}
+
+func mock() *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+}
+
+func TestWithTokenSource(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	source := &tokens{}
+	req := µ.GET(
+		ø.URI(ts.URL),
+		ƒ.Status.OK,
+	)
+
+	err := µ.New(oauth2.WithTokenSource(source)).IO(context.Background(), req, req)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(source.seq, 1),
+	)
+}
+
+func TestAuthBearer(t *testing.T) {
+	ts := mock()
+	defer ts.Close()
+
+	source := &tokens{}
+	err := µ.New().IO(context.Background(),
+		µ.GET(
+			ø.URI(ts.URL),
+			oauth2.AuthBearer(source),
+			ƒ.Status.OK,
+		),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(source.seq, 1),
+	)
+}
@@ -0,0 +1,191 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+// Package otel is an extension to gurl library for instrumenting HTTP I/O
+// with OpenTelemetry traces and metrics.
+package otel
+
+import (
+	net "net/http"
+	"strconv"
+	"time"
+
+	"github.com/fogfish/gurl/v2/http"
+	"github.com/fogfish/opts"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const scope = "github.com/fogfish/gurl/x/otel"
+
+// Configure HTTP Stack to emit span per request. The trace context is
+// propagated to the destination using traceparent headers.
+var WithTracing = opts.FMap(optsTracing)
+
+// Configure HTTP Stack to record latency and errors of requests.
+var WithMetrics = opts.FMap(optsMetrics)
+
+// Configure HTTP Stack to propagate trace context of the inbound
+// context.Context (e.g. server handler) into outgoing requests using
+// the propagator (e.g. W3C propagation.TraceContext{}, B3 b3.New()).
+//
+//	stack := http.New(otel.WithPropagation(propagation.TraceContext{}))
+//
+//	func handler(w net.ResponseWriter, r *net.Request) {
+//		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+//		stack.IO(ctx, http.GET(ø.URI("https://example.com")))
+//	}
+var WithPropagation = opts.FMap(optsPropagation)
+
+//------------------------------------------------------------------------------
+
+type tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	socket     http.Socket
+}
+
+func optsTracing(p *http.Protocol, provider trace.TracerProvider) error {
+	t := provider.Tracer(scope, trace.WithInstrumentationVersion(Version))
+
+	return opts.Apply(p, []http.Option{
+		http.WithMiddleware(func(next http.Socket) http.Socket {
+			return &tracer{
+				tracer:     t,
+				propagator: propagation.TraceContext{},
+				socket:     next,
+			}
+		}),
+	})
+}
+
+func (t *tracer) Do(req *net.Request) (*net.Response, error) {
+	ctx, span := t.tracer.Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", req.URL.String()),
+			attribute.String("server.address", req.URL.Hostname()),
+		),
+		trace.WithAttributes(tagsOf(req)...),
+	)
+	defer span.End()
+
+	req = req.WithContext(ctx)
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	in, err := t.socket.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", in.StatusCode))
+	if in.StatusCode >= 400 {
+		span.SetStatus(codes.Error, strconv.Itoa(in.StatusCode))
+	}
+
+	return in, nil
+}
+
+//------------------------------------------------------------------------------
+
+type propagator struct {
+	propagator propagation.TextMapPropagator
+	socket     http.Socket
+}
+
+func optsPropagation(p *http.Protocol, prop propagation.TextMapPropagator) error {
+	return opts.Apply(p, []http.Option{
+		http.WithMiddleware(func(next http.Socket) http.Socket {
+			return &propagator{
+				propagator: prop,
+				socket:     next,
+			}
+		}),
+	})
+}
+
+func (p *propagator) Do(req *net.Request) (*net.Response, error) {
+	p.propagator.Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	return p.socket.Do(req)
+}
+
+//------------------------------------------------------------------------------
+
+type meter struct {
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+	socket   http.Socket
+}
+
+func optsMetrics(p *http.Protocol, provider metric.MeterProvider) error {
+	m := provider.Meter(scope, metric.WithInstrumentationVersion(Version))
+
+	duration, err := m.Float64Histogram("http.client.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of HTTP client requests."),
+	)
+	if err != nil {
+		return err
+	}
+
+	errors, err := m.Int64Counter("http.client.request.errors",
+		metric.WithDescription("Number of HTTP client requests failed at transport level."),
+	)
+	if err != nil {
+		return err
+	}
+
+	return opts.Apply(p, []http.Option{
+		http.WithMiddleware(func(next http.Socket) http.Socket {
+			return &meter{
+				duration: duration,
+				errors:   errors,
+				socket:   next,
+			}
+		}),
+	})
+}
+
+func (m *meter) Do(req *net.Request) (*net.Response, error) {
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", req.Method),
+		attribute.String("server.address", req.URL.Hostname()),
+	}
+	attrs = append(attrs, tagsOf(req)...)
+
+	t := time.Now()
+	in, err := m.socket.Do(req)
+	d := time.Since(t)
+
+	if err != nil {
+		m.errors.Add(req.Context(), 1, metric.WithAttributes(attrs...))
+		m.duration.Record(req.Context(), d.Seconds(), metric.WithAttributes(attrs...))
+		return nil, err
+	}
+
+	attrs = append(attrs, attribute.Int("http.response.status_code", in.StatusCode))
+	m.duration.Record(req.Context(), d.Seconds(), metric.WithAttributes(attrs...))
+
+	return in, nil
+}
+
+// tagsOf converts tags of request (see ø.Tag) into attributes gurl.tag.<key>
+func tagsOf(req *net.Request) []attribute.KeyValue {
+	tags := http.TagsOf(req.Context())
+	attrs := make([]attribute.KeyValue, 0, len(tags))
+	for key, val := range tags {
+		attrs = append(attrs, attribute.String("gurl.tag."+key, val))
+	}
+	return attrs
+}
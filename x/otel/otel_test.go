@@ -0,0 +1,161 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package otel_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/gurl/x/otel"
+	"github.com/fogfish/it/v2"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func mock(traceparent *string) *httptest.Server {
+	return httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*traceparent = r.Header.Get("traceparent")
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+}
+
+func TestWithTracing(t *testing.T) {
+	var traceparent string
+	ts := mock(&traceparent)
+	defer ts.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	err := µ.New(otel.WithTracing(provider)).IO(context.Background(),
+		µ.GET(
+			ø.URI(ts.URL),
+			ø.Tag("operation", "get"),
+			ƒ.Status.OK,
+		),
+	)
+
+	spans := exporter.GetSpans()
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(len(spans), 1),
+		it.Equal(spans[0].Name, "HTTP GET"),
+		it.True(len(traceparent) > 0),
+		it.String(traceparent).Contain(spans[0].SpanContext.TraceID().String()),
+	)
+
+	var status, operation attribute.Value
+	for _, attr := range spans[0].Attributes {
+		switch attr.Key {
+		case "http.response.status_code":
+			status = attr.Value
+		case "gurl.tag.operation":
+			operation = attr.Value
+		}
+	}
+	it.Then(t).Should(
+		it.Equal(status.AsInt64(), 200),
+		it.Equal(operation.AsString(), "get"),
+	)
+}
+
+func TestWithMetrics(t *testing.T) {
+	var traceparent string
+	ts := mock(&traceparent)
+	defer ts.Close()
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	req := µ.GET(
+		ø.URI(ts.URL),
+		ƒ.Status.OK,
+	)
+	err := µ.New(otel.WithMetrics(provider)).IO(context.Background(), req, req)
+	it.Then(t).Should(it.Nil(err))
+
+	var rm metricdata.ResourceMetrics
+	err = reader.Collect(context.Background(), &rm)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(len(rm.ScopeMetrics), 1),
+	)
+
+	var count uint64
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name == "http.client.request.duration" {
+			for _, dp := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				count += dp.Count
+			}
+		}
+	}
+	it.Then(t).Should(it.Equal(count, 2))
+}
+
+func TestWithPropagation(t *testing.T) {
+	var header http.Header
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header.Clone()
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer ts.Close()
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	inbound := trace.ContextWithRemoteSpanContext(context.Background(),
+		trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+			Remote:     true,
+		}),
+	)
+
+	t.Run("W3C", func(t *testing.T) {
+		err := µ.New(otel.WithPropagation(propagation.TraceContext{})).IO(inbound,
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(header.Get("traceparent"), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"),
+		)
+	})
+
+	t.Run("B3", func(t *testing.T) {
+		err := µ.New(otel.WithPropagation(b3.New())).IO(inbound,
+			µ.GET(
+				ø.URI(ts.URL),
+				ƒ.Status.OK,
+			),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(header.Get("b3"), "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1"),
+			it.Equal(header.Get("traceparent"), ""),
+		)
+	})
+}
@@ -0,0 +1,149 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+// Package prometheus is an extension to gurl library for exporting metrics
+// of HTTP I/O to Prometheus.
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	net "net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fogfish/gurl/v2/http"
+	"github.com/fogfish/opts"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// Configure HTTP Stack to record requests count by status class, requests
+// in-flight and latency, labeled by host and method. Requests count and
+// latency are also labeled by tags of request (see ø.Tag), formatted as
+// key=value pairs ordered by key.
+var WithMetrics = opts.FMap(optsMetrics)
+
+type metrics struct {
+	requests *prom.CounterVec
+	inflight *prom.GaugeVec
+	duration *prom.HistogramVec
+	socket   http.Socket
+}
+
+func optsMetrics(p *http.Protocol, reg prom.Registerer) error {
+	requests, err := register(reg,
+		prom.NewCounterVec(
+			prom.CounterOpts{
+				Namespace: "gurl",
+				Subsystem: "http",
+				Name:      "requests_total",
+				Help:      "Number of HTTP requests by status class.",
+			},
+			[]string{"host", "method", "tags", "class"},
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	inflight, err := register(reg,
+		prom.NewGaugeVec(
+			prom.GaugeOpts{
+				Namespace: "gurl",
+				Subsystem: "http",
+				Name:      "requests_in_flight",
+				Help:      "Number of HTTP requests in-flight.",
+			},
+			[]string{"host", "method"},
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	duration, err := register(reg,
+		prom.NewHistogramVec(
+			prom.HistogramOpts{
+				Namespace: "gurl",
+				Subsystem: "http",
+				Name:      "request_duration_seconds",
+				Help:      "Latency of HTTP requests.",
+				Buckets:   prom.DefBuckets,
+			},
+			[]string{"host", "method", "tags"},
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	return opts.Apply(p, []http.Option{
+		http.WithMiddleware(func(next http.Socket) http.Socket {
+			return &metrics{
+				requests: requests,
+				inflight: inflight,
+				duration: duration,
+				socket:   next,
+			}
+		}),
+	})
+}
+
+// register collector, the existing one is re-used if multiple stacks share
+// the registerer.
+func register[T prom.Collector](reg prom.Registerer, c T) (T, error) {
+	if err := reg.Register(c); err != nil {
+		var are prom.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return c, err
+	}
+	return c, nil
+}
+
+func (m *metrics) Do(req *net.Request) (*net.Response, error) {
+	host, method, tags := req.URL.Host, req.Method, labelOf(http.TagsOf(req.Context()))
+
+	inflight := m.inflight.WithLabelValues(host, method)
+	inflight.Inc()
+	defer inflight.Dec()
+
+	t := time.Now()
+	in, err := m.socket.Do(req)
+	m.duration.WithLabelValues(host, method, tags).Observe(time.Since(t).Seconds())
+
+	if err != nil {
+		m.requests.WithLabelValues(host, method, tags, "error").Inc()
+		return nil, err
+	}
+
+	m.requests.WithLabelValues(host, method, tags, class(in.StatusCode)).Inc()
+	return in, nil
+}
+
+func class(code int) string {
+	switch {
+	case code >= 100 && code < 600:
+		return fmt.Sprintf("%dxx", code/100)
+	default:
+		return "unknown"
+	}
+}
+
+func labelOf(tags map[string]string) string {
+	seq := make([]string, 0, len(tags))
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		seq = append(seq, key+"="+tags[key])
+	}
+	return strings.Join(seq, " ")
+}
@@ -0,0 +1,89 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package prometheus_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/gurl/x/prometheus"
+	"github.com/fogfish/it/v2"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestWithMetrics(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/fail" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+	defer ts.Close()
+
+	reg := prom.NewRegistry()
+	ok := µ.GET(ø.URI(ts.URL+"/ok"), ƒ.Status.OK)
+	tagged := µ.GET(ø.URI(ts.URL+"/ok"), ø.Tag("operation", "ok"), ƒ.Status.OK)
+	fail := µ.GET(ø.URI(ts.URL+"/fail"), ƒ.Status.ServiceUnavailable)
+
+	a := µ.New(prometheus.WithMetrics(reg))
+	b := µ.New(prometheus.WithMetrics(reg))
+
+	err := a.IO(context.Background(), ok, tagged, fail)
+	it.Then(t).Should(it.Nil(err))
+
+	err = b.IO(context.Background(), ok)
+	it.Then(t).Should(it.Nil(err))
+
+	uri, _ := url.Parse(ts.URL)
+	n, err := testutil.GatherAndCount(reg, "gurl_http_request_duration_seconds")
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(n, 2),
+	)
+
+	mfs, err := reg.Gather()
+	it.Then(t).Should(it.Nil(err))
+
+	counts := map[string]float64{}
+	for _, mf := range mfs {
+		if mf.GetName() != "gurl_http_requests_total" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			it.Then(t).Should(
+				it.Equal(labels["host"], uri.Host),
+				it.Equal(labels["method"], "GET"),
+			)
+			counts[labels["class"]] += m.GetCounter().GetValue()
+			if labels["tags"] != "" {
+				counts[labels["tags"]] += m.GetCounter().GetValue()
+			}
+		}
+	}
+
+	it.Then(t).Should(
+		it.Equal(counts["2xx"], 3),
+		it.Equal(counts["5xx"], 1),
+		it.Equal(counts["operation=ok"], 1),
+	)
+}
@@ -0,0 +1,303 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+// Package vcr is an extension to gurl library for recording interactions
+// with services into cassettes and replaying them, enabling deterministic
+// tests without hitting real services.
+package vcr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	net "net/http"
+	"os"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/fogfish/gurl/v2/http"
+	"github.com/fogfish/opts"
+)
+
+// Mode of cassette
+type Mode int
+
+const (
+	// Replay interactions from the cassette, unknown requests fail
+	ModeReplay Mode = iota
+	// Record interactions of services into the cassette
+	ModeRecord
+	// Replay the cassette if it exists, record it otherwise
+	ModeAuto
+)
+
+// ErrNoInteraction is returned in replay mode if request is not matched
+var ErrNoInteraction = errors.New("vcr: no interaction")
+
+// Interaction is recorded pair of request and response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+	replayed bool
+}
+
+// Request is the recorded request
+type Request struct {
+	Method string     `json:"method"`
+	URL    string     `json:"url"`
+	Header net.Header `json:"header,omitempty"`
+	Body   Body       `json:"body,omitempty"`
+}
+
+// Response is the recorded response
+type Response struct {
+	StatusCode int        `json:"status"`
+	Header     net.Header `json:"header,omitempty"`
+	Body       Body       `json:"body,omitempty"`
+}
+
+// Body of request or response, it is stored as text if payload is valid
+// UTF-8 or as base64 otherwise.
+type Body []byte
+
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*b = Body(text)
+		return nil
+	}
+
+	var bin struct {
+		Base64 []byte `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &bin); err != nil {
+		return err
+	}
+	*b = bin.Base64
+	return nil
+}
+
+// Matcher decides if recorded request matches the actual one
+type Matcher func(req *net.Request, body []byte, rec Request) bool
+
+// MatchMethodURL matches request by method and URL (default)
+func MatchMethodURL(req *net.Request, body []byte, rec Request) bool {
+	return req.Method == rec.Method && req.URL.String() == rec.URL
+}
+
+// MatchBody matches request by method, URL and body
+func MatchBody(req *net.Request, body []byte, rec Request) bool {
+	return MatchMethodURL(req, body, rec) && bytes.Equal(body, rec.Body)
+}
+
+// Cassette is the sequence of interactions persisted on disk
+type Cassette struct {
+	sync.Mutex
+	path         string
+	mode         Mode
+	interactions []*Interaction
+
+	// Matcher of requests in replay mode, default is MatchMethodURL
+	Matcher Matcher
+
+	// Headers removed from recorded requests, default is Authorization
+	Redact []string
+}
+
+// Open the cassette. In replay mode the cassette is loaded from the path,
+// in record mode interactions are stored to the path when cassette is saved.
+func Open(path string, mode Mode) (*Cassette, error) {
+	c := &Cassette{
+		path:    path,
+		mode:    mode,
+		Matcher: MatchMethodURL,
+		Redact:  []string{"Authorization"},
+	}
+
+	if mode == ModeAuto {
+		c.mode = ModeRecord
+		if _, err := os.Stat(path); err == nil {
+			c.mode = ModeReplay
+		}
+	}
+
+	if c.mode == ModeReplay {
+		file, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(file, &c.interactions); err != nil {
+			return nil, fmt.Errorf("vcr: cassette %s: %w", path, err)
+		}
+	}
+
+	return c, nil
+}
+
+// Mode of the cassette, auto mode is resolved to record or replay
+func (c *Cassette) Mode() Mode { return c.mode }
+
+// Interactions of the cassette
+func (c *Cassette) Interactions() []Interaction {
+	c.Lock()
+	defer c.Unlock()
+
+	seq := make([]Interaction, len(c.interactions))
+	for i, x := range c.interactions {
+		seq[i] = *x
+	}
+	return seq
+}
+
+// Save recorded interactions to disk, it is no-op in replay mode.
+func (c *Cassette) Save() error {
+	if c.mode != ModeRecord {
+		return nil
+	}
+
+	c.Lock()
+	bytes, err := json.MarshalIndent(c.interactions, "", "  ")
+	c.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, bytes, 0644)
+}
+
+// Configure HTTP Stack to record or replay interactions using the cassette.
+//
+//	cassette, err := vcr.Open("testdata/users.json", vcr.ModeAuto)
+//	defer cassette.Save()
+//
+//	stack := http.New(vcr.WithCassette(cassette))
+var WithCassette = opts.FMap(optsCassette)
+
+func optsCassette(p *http.Protocol, c *Cassette) error {
+	return opts.Apply(p, []http.Option{
+		http.WithMiddleware(func(next http.Socket) http.Socket {
+			return &socket{cassette: c, socket: next}
+		}),
+	})
+}
+
+type socket struct {
+	cassette *Cassette
+	socket   http.Socket
+}
+
+func (s *socket) Do(req *net.Request) (*net.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cassette.mode == ModeReplay {
+		return s.cassette.replay(req, body)
+	}
+
+	return s.cassette.record(s.socket, req, body)
+}
+
+func (c *Cassette) replay(req *net.Request, body []byte) (*net.Response, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	// Note: repeated requests are served by successive interactions,
+	//       the last one is served once all of them are replayed.
+	var last *Interaction
+	for _, x := range c.interactions {
+		if c.Matcher(req, body, x.Request) {
+			last = x
+			if !x.replayed {
+				break
+			}
+		}
+	}
+
+	if last == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL)
+	}
+	last.replayed = true
+
+	return &net.Response{
+		Status:        fmt.Sprintf("%d %s", last.Response.StatusCode, net.StatusText(last.Response.StatusCode)),
+		StatusCode:    last.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        last.Response.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(last.Response.Body)),
+		ContentLength: int64(len(last.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (c *Cassette) record(sock http.Socket, req *net.Request, body []byte) (*net.Response, error) {
+	in, err := sock.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := io.ReadAll(in.Body)
+	in.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	in.Body = io.NopCloser(bytes.NewReader(payload))
+
+	header := req.Header.Clone()
+	for _, key := range c.Redact {
+		header.Del(key)
+	}
+
+	c.Lock()
+	c.interactions = append(c.interactions, &Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: header,
+			Body:   body,
+		},
+		Response: Response{
+			StatusCode: in.StatusCode,
+			Header:     in.Header.Clone(),
+			Body:       payload,
+		},
+	})
+	c.Unlock()
+
+	return in, nil
+}
+
+func readBody(req *net.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == net.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
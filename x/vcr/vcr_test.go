@@ -0,0 +1,145 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package vcr_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/gurl/x/vcr"
+	"github.com/fogfish/it/v2"
+)
+
+type Echo struct {
+	Seq  int    `json:"seq"`
+	Body string `json:"body"`
+}
+
+func TestCassette(t *testing.T) {
+	seq := 0
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seq++
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"seq":` + strconv.Itoa(seq) + `,"body":"` + string(body) + `"}`))
+		}),
+	)
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	request := func(out *Echo, body string) µ.Arrow {
+		return µ.POST(
+			ø.URI("%s/echo", ø.Authority(ts.URL)),
+			ø.Authorization.Set("Bearer secret"),
+			ø.ContentType.Text,
+			ø.Send(body),
+			ƒ.Status.OK,
+			ƒ.Body(out),
+		)
+	}
+
+	t.Run("Record", func(t *testing.T) {
+		cassette, err := vcr.Open(path, vcr.ModeAuto)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cassette.Mode(), vcr.ModeRecord),
+		)
+
+		var a, b Echo
+		err = µ.New(vcr.WithCassette(cassette)).IO(context.Background(),
+			request(&a, "a"),
+			request(&b, "b"),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(a.Seq, 1),
+			it.Equal(a.Body, "a"),
+			it.Equal(b.Seq, 2),
+			it.Nil(cassette.Save()),
+			it.Equal(len(cassette.Interactions()), 2),
+			it.Equal(cassette.Interactions()[0].Request.Header.Get("Authorization"), ""),
+		)
+	})
+
+	ts.Close()
+
+	t.Run("Replay", func(t *testing.T) {
+		cassette, err := vcr.Open(path, vcr.ModeAuto)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(cassette.Mode(), vcr.ModeReplay),
+		)
+
+		var a, b, c Echo
+		err = µ.New(vcr.WithCassette(cassette)).IO(context.Background(),
+			request(&a, "a"),
+			request(&b, "a"),
+			request(&c, "a"),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(a.Seq, 1),
+			it.Equal(b.Seq, 2),
+			it.Equal(c.Seq, 2),
+		)
+	})
+
+	t.Run("MatchBody", func(t *testing.T) {
+		cassette, err := vcr.Open(path, vcr.ModeReplay)
+		it.Then(t).Should(it.Nil(err))
+		cassette.Matcher = vcr.MatchBody
+
+		var b Echo
+		err = µ.New(vcr.WithCassette(cassette)).IO(context.Background(),
+			request(&b, "b"),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(b.Seq, 2),
+			it.Equal(b.Body, "b"),
+		)
+
+		err = µ.New(vcr.WithCassette(cassette)).IO(context.Background(),
+			request(&b, "c"),
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, vcr.ErrNoInteraction)),
+		)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := vcr.Open(filepath.Join(t.TempDir(), "none.json"), vcr.ModeReplay)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+}
+
+func TestBody(t *testing.T) {
+	bin := vcr.Body([]byte{0xff, 0x00, 0xfe})
+	raw, err := bin.MarshalJSON()
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(string(raw), `{"base64":"/wD+"}`),
+	)
+
+	var out vcr.Body
+	err = out.UnmarshalJSON(raw)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Seq(out).Equal(0xff, 0x00, 0xfe),
+	)
+}
@@ -0,0 +1,109 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+// Package recv defines a pure computations to compose WebSocket message
+// receivers
+package recv
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fogfish/gurl/v2"
+	"github.com/fogfish/gurl/v2/http"
+	"github.com/fogfish/gurl/x/ws"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/net/websocket"
+)
+
+// Body receives next message and decodes it from JSON into the variable.
+// Supply the pointer to data target data structure.
+func Body[T any](out *T) http.Arrow {
+	return func(ctx *http.Context) error {
+		conn, err := ws.Connect(ctx)
+		if err != nil {
+			return err
+		}
+
+		return websocket.JSON.Receive(conn, out)
+	}
+}
+
+// Expect matches next JSON message to defined value
+func Expect[T any](expect T) http.Arrow {
+	return func(ctx *http.Context) error {
+		var actual T
+		if err := Body(&actual)(ctx); err != nil {
+			return err
+		}
+
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			return &gurl.NoMatch{
+				ID:       "ws.Recv",
+				Diff:     diff,
+				Protocol: "message",
+				Expect:   expect,
+				Actual:   actual,
+			}
+		}
+
+		return nil
+	}
+}
+
+// Text matches next text frame to the value
+func Text(expect string) http.Arrow {
+	return func(ctx *http.Context) error {
+		conn, err := ws.Connect(ctx)
+		if err != nil {
+			return err
+		}
+
+		var actual string
+		if err := websocket.Message.Receive(conn, &actual); err != nil {
+			return err
+		}
+
+		if actual != expect {
+			return &gurl.NoMatch{
+				ID:       "ws.Text",
+				Diff:     fmt.Sprintf("+ %s\n- %s", actual, expect),
+				Protocol: "message",
+				Expect:   expect,
+				Actual:   actual,
+			}
+		}
+
+		return nil
+	}
+}
+
+// Frame lifts next frame (text or binary) into the variable
+func Frame(out *[]byte) http.Arrow {
+	return func(ctx *http.Context) error {
+		conn, err := ws.Connect(ctx)
+		if err != nil {
+			return err
+		}
+
+		return websocket.Message.Receive(conn, out)
+	}
+}
+
+// Bytes receives next frame into writer
+func Bytes(w io.Writer) http.Arrow {
+	return func(ctx *http.Context) error {
+		var frame []byte
+		if err := Frame(&frame)(ctx); err != nil {
+			return err
+		}
+
+		_, err := w.Write(frame)
+		return err
+	}
+}
@@ -0,0 +1,140 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package recv_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	µƒ "github.com/fogfish/gurl/v2/http/recv"
+	µø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/gurl/x/ws"
+	ƒ "github.com/fogfish/gurl/x/ws/recv"
+	ø "github.com/fogfish/gurl/x/ws/send"
+	"github.com/fogfish/it/v2"
+	"golang.org/x/net/websocket"
+)
+
+type Msg struct {
+	Text string `json:"text"`
+}
+
+func echo() *httptest.Server {
+	return httptest.NewServer(
+		websocket.Handler(func(conn *websocket.Conn) {
+			io.Copy(conn, conn)
+		}),
+	)
+}
+
+func TestRecv(t *testing.T) {
+	ts := echo()
+	defer ts.Close()
+
+	url := strings.Replace(ts.URL, "http://", "ws://", 1)
+	cat := µ.New()
+
+	t.Run("Body", func(t *testing.T) {
+		var msg Msg
+		err := cat.IO(context.Background(), ws.Join(
+			ø.URI(url),
+			ø.Send(Msg{Text: "hello"}),
+			ƒ.Body(&msg),
+		))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(msg.Text, "hello"),
+		)
+	})
+
+	t.Run("Expect", func(t *testing.T) {
+		err := cat.IO(context.Background(), ws.Join(
+			ø.URI(url),
+			ø.Send(Msg{Text: "hello"}),
+			ƒ.Expect(Msg{Text: "hello"}),
+			ø.Send(Msg{Text: "world"}),
+			ƒ.Expect(Msg{Text: "world"}),
+		))
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("ExpectFailed", func(t *testing.T) {
+		err := cat.IO(context.Background(), ws.Join(
+			ø.URI(url),
+			ø.Send(Msg{Text: "hello"}),
+			ƒ.Expect(Msg{Text: "world"}),
+		))
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		err := cat.IO(context.Background(), ws.Join(
+			ø.URI(url),
+			ø.Send("hello"),
+			ƒ.Text("hello"),
+		))
+		it.Then(t).Should(it.Nil(err))
+	})
+
+	t.Run("TextFailed", func(t *testing.T) {
+		err := cat.IO(context.Background(), ws.Join(
+			ø.URI(url),
+			ø.Send("hello"),
+			ƒ.Text("world"),
+		))
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+
+	t.Run("Bytes", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := cat.IO(context.Background(), ws.Join(
+			ø.URI(url),
+			ø.Send([]byte("hello")),
+			ƒ.Bytes(buf),
+		))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(buf.String(), "hello"),
+		)
+	})
+}
+
+func TestJoin(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/events", websocket.Handler(func(conn *websocket.Conn) { io.Copy(conn, conn) }))
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text": "token"}`))
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	var token Msg
+	err := µ.New(µ.WithHost(ts.URL)).IO(context.Background(),
+		µ.GET(
+			µø.URI("/token"),
+			µƒ.Status.OK,
+			µƒ.Body(&token),
+		),
+		ws.Join(
+			ø.Subprotocol("chat"),
+			ø.URI("/events"),
+			ø.Send(&token),
+			ƒ.Expect(Msg{Text: "token"}),
+		),
+	)
+	it.Then(t).Should(it.Nil(err))
+}
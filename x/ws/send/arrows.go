@@ -0,0 +1,180 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+// Package send defines a pure computations to compose WebSocket upgrade
+// request and egress messages
+package send
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/fogfish/gurl/v2"
+	µ "github.com/fogfish/gurl/v2/http"
+	"github.com/fogfish/gurl/x/ws"
+	"golang.org/x/net/websocket"
+)
+
+// URI defines destination URI of WebSocket upgrade request. The relative
+// URI is resolved against host of the stack, http(s) scheme of the host
+// is replaced with ws(s). The origin is derived from URI, use Origin arrow
+// to overwrite it.
+func URI(uri string, args ...any) µ.Arrow {
+	return func(ctx *µ.Context) error {
+		session, err := ws.SessionOf(ctx)
+		if err != nil {
+			return err
+		}
+
+		addr := uri
+		if len(args) != 0 {
+			addr = fmt.Sprintf(uri, args...)
+		}
+
+		if !strings.HasPrefix(addr, "ws") {
+			addr = wsHost(ctx.Host) + addr
+		}
+
+		if !strings.HasPrefix(addr, "ws://") && !strings.HasPrefix(addr, "wss://") {
+			return &gurl.NotSupported{URL: addr}
+		}
+
+		location, err := url.ParseRequestURI(addr)
+		if err != nil {
+			return err
+		}
+
+		config := session.Upgrade()
+		config.Location = location
+		if config.Origin == nil {
+			if config.Origin, err = url.ParseRequestURI(origin(addr)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func wsHost(host string) string {
+	switch {
+	case strings.HasPrefix(host, "https://"):
+		return "wss://" + strings.TrimPrefix(host, "https://")
+	case strings.HasPrefix(host, "http://"):
+		return "ws://" + strings.TrimPrefix(host, "http://")
+	default:
+		return host
+	}
+}
+
+func origin(uri string) string {
+	if strings.HasPrefix(uri, "wss://") {
+		uri = "https://" + strings.TrimPrefix(uri, "wss://")
+	} else {
+		uri = "http://" + strings.TrimPrefix(uri, "ws://")
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+
+	return u.Scheme + "://" + u.Host
+}
+
+// Origin defines origin of upgrade request
+func Origin(origin string) µ.Arrow {
+	return func(ctx *µ.Context) error {
+		session, err := ws.SessionOf(ctx)
+		if err != nil {
+			return err
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil {
+			return err
+		}
+
+		session.Upgrade().Origin = u
+		return nil
+	}
+}
+
+// Subprotocol declares WebSocket subprotocols to the upgrade request
+func Subprotocol(protocols ...string) µ.Arrow {
+	return func(ctx *µ.Context) error {
+		session, err := ws.SessionOf(ctx)
+		if err != nil {
+			return err
+		}
+
+		config := session.Upgrade()
+		config.Protocol = append(config.Protocol, protocols...)
+		return nil
+	}
+}
+
+// Header defines HTTP headers to the upgrade request
+func Header(header, value string) µ.Arrow {
+	return func(ctx *µ.Context) error {
+		session, err := ws.SessionOf(ctx)
+		if err != nil {
+			return err
+		}
+
+		config := session.Upgrade()
+		if config.Header == nil {
+			config.Header = http.Header{}
+		}
+
+		config.Header.Add(header, value)
+		return nil
+	}
+}
+
+// Authorization defines header `Authorization` of the upgrade request
+func Authorization(value string) µ.Arrow {
+	return Header("Authorization", value)
+}
+
+// TLSConfig defines TLS config of secure WebSocket session
+func TLSConfig(config *tls.Config) µ.Arrow {
+	return func(ctx *µ.Context) error {
+		session, err := ws.SessionOf(ctx)
+		if err != nil {
+			return err
+		}
+
+		session.Upgrade().TlsConfig = config
+		return nil
+	}
+}
+
+// Send message to peer. The connection is established if needed.
+// The string is sent as text frame, []byte as binary frame. Any other
+// Go native type is encoded as JSON text frame.
+func Send(data any) µ.Arrow {
+	return func(ctx *µ.Context) error {
+		conn, err := ws.Connect(ctx)
+		if err != nil {
+			return err
+		}
+
+		switch v := data.(type) {
+		case string, []byte:
+			return websocket.Message.Send(conn, v)
+		case *string:
+			return websocket.Message.Send(conn, *v)
+		default:
+			return websocket.JSON.Send(conn, v)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package send_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	"github.com/fogfish/gurl/x/ws"
+	ø "github.com/fogfish/gurl/x/ws/send"
+	"github.com/fogfish/it/v2"
+	"golang.org/x/net/websocket"
+)
+
+// evaluates arrows and returns upgrade request declared by them
+func upgrade(stack µ.Stack, arrows ...µ.Arrow) (*websocket.Config, error) {
+	var config *websocket.Config
+	capture := func(ctx *µ.Context) error {
+		session, err := ws.SessionOf(ctx)
+		if err != nil {
+			return err
+		}
+		config = session.Config
+		return nil
+	}
+
+	err := stack.IO(context.Background(), ws.Join(append(arrows, capture)...))
+	return config, err
+}
+
+func TestURI(t *testing.T) {
+	cat := µ.New(µ.WithHost("https://example.com"))
+
+	t.Run("Literal", func(t *testing.T) {
+		config, err := upgrade(cat,
+			ø.URI("ws://example.com/%s", "events"),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(config.Location.String(), "ws://example.com/events"),
+			it.Equal(config.Origin.String(), "http://example.com"),
+		)
+	})
+
+	t.Run("Host", func(t *testing.T) {
+		config, err := upgrade(cat,
+			ø.URI("/events"),
+		)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(config.Location.String(), "wss://example.com/events"),
+			it.Equal(config.Origin.String(), "https://example.com"),
+		)
+	})
+
+	t.Run("NotSupported", func(t *testing.T) {
+		_, err := upgrade(µ.New(),
+			ø.URI("http://example.com"),
+		)
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+
+	t.Run("NoSession", func(t *testing.T) {
+		err := µ.New().IO(context.Background(),
+			ø.URI("ws://example.com/events"),
+		)
+		it.Then(t).Should(
+			it.True(errors.Is(err, ws.ErrNoSession)),
+		)
+	})
+}
+
+func TestUpgrade(t *testing.T) {
+	config, err := upgrade(µ.New(),
+		ø.Subprotocol("chat", "v2"),
+		ø.Header("X-Value", "1024"),
+		ø.Authorization("Bearer token"),
+		ø.Origin("https://example.org"),
+		ø.URI("ws://example.com/events"),
+	)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(config.Location.String(), "ws://example.com/events"),
+		it.Equal(config.Origin.String(), "https://example.org"),
+		it.Seq(config.Protocol).Equal("chat", "v2"),
+		it.Equal(config.Header.Get("X-Value"), "1024"),
+		it.Equal(config.Header.Get("Authorization"), "Bearer token"),
+	)
+}
@@ -0,0 +1,194 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+/*
+Package ws is an extension to gurl library for WebSocket I/O. The arrows
+are http.Arrow evaluated over the same http.Stack, so that REST and
+WebSocket I/O are composable with same "do"-notation
+
+	http.Join(
+	  http.POST(...),
+	  ws.Join(
+	    ø...,
+	    ƒ...,
+	  ),
+	)
+
+Symbol `ø` is an alias to module gurl/x/ws/send, which defines writer
+morphism to declare upgrade URI, subprotocols, headers and egress messages.
+Symbol `ƒ` is an alias to module gurl/x/ws/recv, which defines reader
+morphism to match ingress messages.
+
+	ws.Join(
+	  ø.URI("wss://example.com/events"),
+	  ø.Subprotocol("chat"),
+	  ø.Send(Hello{Text: "Hello"}),
+
+	  ƒ.Expect(Hello{Text: "World"}),
+	)
+*/
+package ws
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/fogfish/gurl/v2/http"
+	"golang.org/x/net/websocket"
+)
+
+// Session of WebSocket I/O, the upgrade request and the connection
+type Session struct {
+	Dialer *net.Dialer
+	Config *websocket.Config
+	Conn   *websocket.Conn
+}
+
+// ErrNoSession is returned if WebSocket arrow is evaluated outside of ws.Join
+var ErrNoSession = errors.New("websocket arrow is evaluated outside of ws.Join")
+
+type sessionKey struct{}
+
+// Join composes WebSocket arrows to http.Arrow. The session is scoped to
+// the composition, the connection is established by the first arrow
+// sending or receiving message, it is closed afterwards.
+func Join(arrows ...http.Arrow) http.Arrow {
+	return func(ctx *http.Context) error {
+		parent := ctx.Context
+
+		c := parent
+		if c == nil {
+			c = context.Background()
+		}
+
+		session := &Session{Dialer: &net.Dialer{Timeout: 10 * time.Second}}
+		ctx.Context = context.WithValue(c, sessionKey{}, session)
+		defer func() {
+			session.Close()
+			ctx.Context = parent
+		}()
+
+		return http.Join(arrows...)(ctx)
+	}
+}
+
+// SessionOf returns WebSocket session of the context
+func SessionOf(ctx *http.Context) (*Session, error) {
+	if ctx.Context == nil {
+		return nil, ErrNoSession
+	}
+
+	session, ok := ctx.Context.Value(sessionKey{}).(*Session)
+	if !ok {
+		return nil, ErrNoSession
+	}
+
+	return session, nil
+}
+
+// Upgrade returns configuration of upgrade request, it is allocated
+// on demand so that arrows are composable in any order.
+func (s *Session) Upgrade() *websocket.Config {
+	if s.Config == nil {
+		s.Config = &websocket.Config{Version: websocket.ProtocolVersionHybi13}
+	}
+	return s.Config
+}
+
+// Unsafe establishes connection using declared upgrade request.
+// It is no-op if connection is already established.
+func (s *Session) Unsafe(c context.Context) error {
+	if s.Conn != nil {
+		return nil
+	}
+
+	if s.Config == nil || s.Config.Location == nil {
+		return websocket.ErrBadWebSocketLocation
+	}
+
+	if s.Config.Header == nil {
+		s.Config.Header = map[string][]string{}
+	}
+
+	addr := authority(s.Config)
+	conn, err := s.Dialer.DialContext(c, "tcp", addr)
+	if err != nil {
+		return &websocket.DialError{Config: s.Config, Err: err}
+	}
+
+	if s.Config.Location.Scheme == "wss" {
+		config := s.Config.TlsConfig
+		if config == nil {
+			config = &tls.Config{}
+		}
+		if config.ServerName == "" {
+			config = config.Clone()
+			config.ServerName = s.Config.Location.Hostname()
+		}
+
+		tconn := tls.Client(conn, config)
+		if err := tconn.HandshakeContext(c); err != nil {
+			conn.Close()
+			return &websocket.DialError{Config: s.Config, Err: err}
+		}
+		conn = tconn
+	}
+
+	if deadline, ok := c.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	ws, err := websocket.NewClient(s.Config, conn)
+	if err != nil {
+		conn.Close()
+		return &websocket.DialError{Config: s.Config, Err: err}
+	}
+
+	s.Conn = ws
+	return nil
+}
+
+// Close the connection
+func (s *Session) Close() error {
+	if s.Conn == nil {
+		return nil
+	}
+
+	conn := s.Conn
+	s.Conn = nil
+	return conn.Close()
+}
+
+// Connect returns connection of the session, it is established if needed
+func Connect(ctx *http.Context) (*websocket.Conn, error) {
+	session, err := SessionOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := session.Unsafe(ctx.Context); err != nil {
+		return nil, err
+	}
+
+	return session.Conn, nil
+}
+
+func authority(config *websocket.Config) string {
+	host := config.Location.Host
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+
+	if config.Location.Scheme == "wss" {
+		return net.JoinHostPort(host, "443")
+	}
+	return net.JoinHostPort(host, "80")
+}
@@ -0,0 +1,154 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package xhtml
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+)
+
+// HTMLForm is html form parsed from the response
+type HTMLForm struct {
+	Action string
+	Method string
+	Fields url.Values
+}
+
+// HTTP Arrow receive HTML content and parses the form matching the selector.
+// The form action is resolved relative to the request URL.
+//
+//	var form xhtml.HTMLForm
+//
+//	http.Join(
+//		http.GET(
+//			ø.URI("https://example.com/consent"),
+//			ƒ.Status.OK,
+//			xhtml.Form("form#consent", &form),
+//		),
+//		form.Submit(map[string]string{"scope": "email"},
+//			ƒ.Status.OK,
+//		),
+//	)
+func Form(selector string, form *HTMLForm) http.Arrow {
+	return func(ctx *http.Context) error {
+		// Note: the buffer is allocated per evaluation, the arrow is
+		//       reusable across compositions and goroutines.
+		b := &bytes.Buffer{}
+		if err := ƒ.Bytes(b)(ctx); err != nil {
+			return err
+		}
+
+		doc, err := goquery.NewDocumentFromReader(b)
+		if err != nil {
+			return err
+		}
+
+		node := doc.Find(selector).First()
+		if node.Length() == 0 {
+			return fmt.Errorf("html form %s is not found", selector)
+		}
+
+		action, err := ctx.Request.URL.Parse(node.AttrOr("action", ""))
+		if err != nil {
+			return err
+		}
+
+		form.Action = action.String()
+		form.Method = strings.ToUpper(node.AttrOr("method", "GET"))
+		form.Fields = formFields(node)
+
+		return nil
+	}
+}
+
+func formFields(node *goquery.Selection) url.Values {
+	fields := url.Values{}
+
+	node.Find("input[name]").Each(func(_ int, s *goquery.Selection) {
+		switch strings.ToLower(s.AttrOr("type", "text")) {
+		case "submit", "button", "image", "reset", "file":
+			return
+		case "checkbox", "radio":
+			if _, checked := s.Attr("checked"); !checked {
+				return
+			}
+			fields.Add(s.AttrOr("name", ""), s.AttrOr("value", "on"))
+		default:
+			fields.Add(s.AttrOr("name", ""), s.AttrOr("value", ""))
+		}
+	})
+
+	node.Find("textarea[name]").Each(func(_ int, s *goquery.Selection) {
+		fields.Add(s.AttrOr("name", ""), s.Text())
+	})
+
+	node.Find("select[name]").Each(func(_ int, s *goquery.Selection) {
+		opt := s.Find("option[selected]").First()
+		if opt.Length() == 0 {
+			opt = s.Find("option").First()
+		}
+		if opt.Length() != 0 {
+			fields.Add(s.AttrOr("name", ""), opt.AttrOr("value", opt.Text()))
+		}
+	})
+
+	return fields
+}
+
+// Submit the form, fields overrides values parsed from the form.
+// The arrows are applied to the request, use them to declare headers
+// and match the response.
+func (form *HTMLForm) Submit(fields map[string]string, arrows ...http.Arrow) http.Arrow {
+	return func(ctx *http.Context) error {
+		values := url.Values{}
+		for key, val := range form.Fields {
+			values[key] = append([]string{}, val...)
+		}
+		for key, val := range fields {
+			values.Set(key, val)
+		}
+
+		ctx.Method = form.Method
+		if ctx.Method == "" {
+			ctx.Method = "GET"
+		}
+
+		var req []http.Arrow
+		switch ctx.Method {
+		case "GET", "HEAD":
+			uri, err := url.Parse(form.Action)
+			if err != nil {
+				return err
+			}
+			uri.RawQuery = values.Encode()
+			req = []http.Arrow{ø.URI(uri.String())}
+		default:
+			req = []http.Arrow{
+				ø.URI(form.Action),
+				ø.ContentType.Form,
+				ø.Send(values.Encode()),
+			}
+		}
+
+		for _, f := range append(req, arrows...) {
+			if err := f(ctx); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
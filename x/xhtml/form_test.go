@@ -0,0 +1,80 @@
+//
+// Copyright (C) 2019 - 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/gurl
+//
+
+package xhtml_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	µ "github.com/fogfish/gurl/v2/http"
+	ƒ "github.com/fogfish/gurl/v2/http/recv"
+	ø "github.com/fogfish/gurl/v2/http/send"
+	"github.com/fogfish/gurl/x/xhtml"
+	"github.com/fogfish/it/v2"
+)
+
+const consent = `<html><body>
+<form id="consent" action="/approve" method="post">
+  <input type="hidden" name="csrf" value="token"/>
+  <input type="text" name="scope" value="profile"/>
+  <input type="checkbox" name="remember" checked/>
+  <input type="checkbox" name="share"/>
+  <select name="ttl"><option value="1h">1h</option><option value="1d" selected>1d</option></select>
+  <input type="submit" name="go" value="Approve"/>
+</form>
+</body></html>`
+
+func TestForm(t *testing.T) {
+	var body url.Values
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/consent":
+				w.Header().Add("Content-Type", "text/html")
+				w.Write([]byte(consent))
+			case "/approve":
+				raw, _ := io.ReadAll(r.Body)
+				body, _ = url.ParseQuery(string(raw))
+				w.WriteHeader(http.StatusOK)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}),
+	)
+	defer ts.Close()
+
+	var form xhtml.HTMLForm
+	err := µ.New().IO(context.Background(),
+		µ.GET(
+			ø.URI(ts.URL+"/consent"),
+			ƒ.Status.OK,
+			xhtml.Form("form#consent", &form),
+		),
+		form.Submit(map[string]string{"scope": "email"},
+			ƒ.Status.OK,
+		),
+	)
+
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(form.Method, "POST"),
+		it.Equal(form.Action, ts.URL+"/approve"),
+		it.Equal(body.Get("csrf"), "token"),
+		it.Equal(body.Get("scope"), "email"),
+		it.Equal(body.Get("remember"), "on"),
+		it.Equal(body.Get("share"), ""),
+		it.Equal(body.Get("ttl"), "1d"),
+		it.Equal(body.Get("go"), ""),
+	)
+}
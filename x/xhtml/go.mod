@@ -10,10 +10,17 @@ require (
 
 require (
 	github.com/ajg/form v1.5.2-0.20200323032839-9aeb3cf462e1 // indirect
+	github.com/andybalholm/brotli v1.2.5 // indirect
 	github.com/andybalholm/cascadia v1.3.2 // indirect
 	github.com/fogfish/golem/hseq v1.2.0 // indirect
 	github.com/fogfish/golem/optics v0.13.1 // indirect
 	github.com/fogfish/opts v0.0.2 // indirect
 	github.com/google/go-cmp v0.6.0 // indirect
 	golang.org/x/net v0.21.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	golang.org/x/time v0.5.0 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
 )
+
+// the extension is built against the core module of this tree
+replace github.com/fogfish/gurl/v2 => ../../